@@ -1,14 +1,26 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"log"
+	"os"
+
 	"api/internal/app/controller"
 	"api/internal/app/middleware"
 	neoModels "api/internal/app/models/neo"
 	neo "api/internal/app/neo4j"
+	"api/internal/app/postgres"
 	"api/internal/app/routing"
+
+	"github.com/joho/godotenv"
 )
 
 func main() {
+	// .env is loaded before anything reads the environment, ie: the JWT secret. Deployments without one use the real environment.
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Fatalf("error loading .env file: %v", err)
+	}
 
 	neo.RegisterModel("User", &neoModels.User{})
 	neo.RegisterModel("World", &neoModels.World{})
@@ -18,18 +30,50 @@ func main() {
 	neo.RegisterModel("Location", &neoModels.Location{})
 	neo.RegisterModel("City", &neoModels.City{})
 
+	missing, err := neo.VerifySchema(context.Background(), neoModels.Indexes)
+	if err != nil {
+		log.Printf("could not verify the Neo4j schema: %v", err)
+	}
+	for _, spec := range missing {
+		log.Printf("warning: missing Neo4j index %s", spec)
+	}
+
 	router := routing.NewRouter()
+
+	// The server drains its in-flight requests before the connections they use are closed.
+	onSignal(router.Shutdown, neo.CloseGlobalDriver, func(context.Context) error {
+		return postgres.CloseShared()
+	})
+
+	router.Wrap(middleware.MaxConcurrent(256))
+	router.Wrap(middleware.Recover)
 	router.Use(middleware.Cors)
 	router.Use(middleware.ContentTypeJSON)
+	router.NotFoundHandler(controller.NotFound)
+	router.MethodNotAllowedHandler(controller.MethodNotAllowed)
 	router.Handle("POST", "/api/auth/login", controller.Login)
+	router.Handle("POST", "/api/auth/refresh", controller.Refresh)
+	router.Handle("POST", "/api/auth/logout", controller.Logout).Wrap(middleware.RequireAuth)
+	router.Handle("GET", "/api/auth/session", controller.GetSession).Wrap(middleware.RequireAuth)
 	router.Handle("POST", "/api/user", controller.CreateUser)
 	router.Handle("GET", "/api/user/:id", controller.GetUser)
 	router.Handle("GET", "/api/user/:id/worlds", controller.GetUserWorlds)
 	router.Handle("GET", "/api/user/:id/neo", controller.GetNeoUser)
 	router.Handle("POST", "/api/user/:id/world", controller.CreateWorld)
+	router.Handle("POST", "/api/user/:id/world/import", controller.ImportWorld).Wrap(middleware.RequireAuth)
+	router.Handle("POST", "/api/world/validate", controller.ValidateWorld)
 	router.Handle("GET", "/api/world/:id", controller.GetWorld)
 	router.Handle("PUT", "/api/world/:id", controller.PutWorld)
 	router.Handle("DELETE", "/api/world/:id", controller.DeleteWorld)
-	router.Serve("8080", routing.ServeOptions{Message: "http://localhost:8080", Logging: true})
+	router.Handle("POST", "/api/world/:id/transfer", controller.TransferWorld)
+	router.Handle("GET", "/api/city/:id/ancestry", controller.GetCityAncestry)
+	router.Handle("GET", "/api/meta/labels", controller.GetLabels)
+	router.Handle("GET", "/api/admin/orphans", controller.GetOrphans).Wrap(middleware.RequireRole("admin"))
+	if err := router.Serve("8080", routing.ServeOptions{Message: "http://localhost:8080", Logging: true}); err != nil {
+		log.Fatal(err)
+	}
+
+	// Serve returns once the server is shut down, onSignal exits after closing the connections.
+	select {}
 
 }
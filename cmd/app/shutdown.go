@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// shutdownTimeout bounds the time spent closing the connections on exit.
+const shutdownTimeout = 10 * time.Second
+
+// shutdown runs every closer, even when one fails, and returns their joined errors.
+func shutdown(ctx context.Context, closers ...func(context.Context) error) error {
+	var errs []error
+	for _, closer := range closers {
+		if err := closer(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// onSignal runs shutdown with the closers, in order, once the process receives SIGINT or SIGTERM, then exits.
+func onSignal(closers ...func(context.Context) error) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		sig := <-signals
+		log.Printf("received %s, shutting down", sig)
+
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		code := 0
+		if err := shutdown(ctx, closers...); err != nil {
+			log.Printf("shutdown: %v", err)
+			code = 1
+		}
+		os.Exit(code)
+	}()
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	neo "api/internal/app/neo4j"
+	"api/internal/app/neo4j/neotest"
+)
+
+func TestShutdown(t *testing.T) {
+	errRouter := errors.New("router: server closed")
+	errPostgres := errors.New("postgres: connection reset")
+
+	tests := []struct {
+		name     string
+		failures map[string]error
+		wantErrs []error
+	}{
+		{name: "every closer succeeds"},
+		{name: "one closer fails", failures: map[string]error{"router": errRouter}, wantErrs: []error{errRouter}},
+		{
+			name:     "every failure reported",
+			failures: map[string]error{"router": errRouter, "postgres": errPostgres},
+			wantErrs: []error{errRouter, errPostgres},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := neotest.NewDriver(nil)
+			neo.SetDriver(driver)
+			t.Cleanup(func() { neo.SetDriver(nil) })
+
+			var closed []string
+			closer := func(name string) func(context.Context) error {
+				return func(context.Context) error {
+					closed = append(closed, name)
+					return tt.failures[name]
+				}
+			}
+			closeNeo := func(ctx context.Context) error {
+				closed = append(closed, "neo4j")
+				return neo.CloseGlobalDriver(ctx)
+			}
+
+			err := shutdown(context.Background(), closer("router"), closeNeo, closer("postgres"))
+			for _, want := range tt.wantErrs {
+				if !errors.Is(err, want) {
+					t.Errorf("shutdown() error = %v, want it to include %v", err, want)
+				}
+			}
+			if len(tt.wantErrs) == 0 && err != nil {
+				t.Errorf("shutdown() error = %v", err)
+			}
+
+			// Every closer runs, in order, even after a failure.
+			if want := []string{"router", "neo4j", "postgres"}; !slices.Equal(closed, want) {
+				t.Errorf("shutdown() closed %v, want %v", closed, want)
+			}
+			if !driver.Closed() {
+				t.Error("shutdown() left the shared Neo4j driver open")
+			}
+		})
+	}
+}
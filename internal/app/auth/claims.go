@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultTokenTTL is how long an access token stays valid when TokenOptions.TTL is unset.
+const DefaultTokenTTL = 24 * time.Hour
+
+/* Claims is the typed content of the tokens issued by CreateJWT
+ * The registered claims (exp, iss, aud, sub...) are set from TokenOptions
+ * Username, UserID and Roles are the application claims handlers read
+ */
+type Claims struct {
+	jwt.RegisteredClaims
+	Username string   `json:"username"`
+	UserID   int64    `json:"userID,omitempty"`
+	Roles    []string `json:"roles,omitempty"`
+}
+
+/* TokenOptions configures the registered claims of a token
+ * TTL defaults to DefaultTokenTTL, the other fields are omitted from the token when empty
+ */
+type TokenOptions struct {
+	TTL      time.Duration
+	Issuer   string
+	Audience []string
+	Subject  string
+}
+
+// apply sets the registered claims of the claims from the options, along with a new jti.
+func (o TokenOptions) apply(claims *Claims, now time.Time) error {
+	ttl := o.TTL
+	if ttl <= 0 {
+		ttl = DefaultTokenTTL
+	}
+	id, err := newTokenID()
+	if err != nil {
+		return err
+	}
+	claims.ID = id
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(ttl))
+	claims.Issuer = o.Issuer
+	claims.Audience = o.Audience
+	claims.Subject = o.Subject
+	return nil
+}
+
+// sign signs the claims with the configured signing method.
+func sign(claims jwt.Claims) (string, error) {
+	method, key, err := signingMethod()
+	if err != nil {
+		return "", err
+	}
+	return jwt.NewWithClaims(method, claims).SignedString(key)
+}
+
+/* DecodeClaims is a function that decodes a JWT token into the typed Claims
+ * It takes a tokenString as a parameter and returns the claims and an error
+ * Refresh tokens and revoked tokens are rejected, see CreateRefreshToken and RevokeToken
+ * The error is nil if the token is decoded successfully, otherwise it contains an error message
+ */
+func DecodeClaims(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, keyFunc)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing JWT token: %w", err)
+	}
+	if !token.Valid {
+		return nil, fmt.Errorf("invalid JWT token")
+	}
+	if slices.Contains(claims.Audience, refreshAudience) {
+		return nil, fmt.Errorf("refresh token used as access token")
+	}
+	if err := checkRevoked(claims.ID); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
@@ -0,0 +1,26 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// claimsKey is the request context key the claims verified by the auth middleware are stored under.
+type claimsKey struct{}
+
+/* WithClaims is a function that returns a copy of ctx carrying the claims
+ * It takes a context and a map of claims as parameters, see ClaimsFromContext
+ */
+func WithClaims(ctx context.Context, claims jwt.MapClaims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+/* ClaimsFromContext is a function that returns the claims stored by WithClaims
+ * It takes a context as a parameter and returns a map of claims and a boolean
+ * The boolean is false if the context carries no claims
+ */
+func ClaimsFromContext(ctx context.Context) (jwt.MapClaims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(jwt.MapClaims)
+	return claims, ok
+}
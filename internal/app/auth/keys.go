@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// privateKey signs tokens with RS256 when set, see SetPrivateKey.
+var privateKey *rsa.PrivateKey
+
+// publicKey verifies RS256 tokens when set, see SetPublicKey.
+var publicKey *rsa.PublicKey
+
+/* SetPrivateKey is a function that makes CreateJWT sign tokens with RS256 using the given key
+ * It takes an RSA private key as a parameter, nil going back to HS256 with the secret
+ * Unless a public key is set, tokens are verified with the public half of the key
+ */
+func SetPrivateKey(key *rsa.PrivateKey) {
+	privateKey = key
+}
+
+/* SetPublicKey is a function that makes tokens be verified with RS256 using the given key
+ * It takes an RSA public key as a parameter, ie: the key of an external identity provider
+ * While a public key is set, HS256 tokens are rejected, and CreateJWT returns ErrNoSigningKey unless a private key is set
+ */
+func SetPublicKey(key *rsa.PublicKey) {
+	publicKey = key
+}
+
+// verificationKey returns the RSA key tokens are verified with, or nil when tokens use HS256.
+func verificationKey() *rsa.PublicKey {
+	if publicKey != nil {
+		return publicKey
+	}
+	if privateKey != nil {
+		return &privateKey.PublicKey
+	}
+	return nil
+}
+
+// ErrNoSigningKey is returned when issuing a token while only a public key is set: the tokens would fail verification.
+var ErrNoSigningKey = errors.New("no private key to sign tokens with, only a public key is set")
+
+// signingMethod returns the method and key CreateJWT signs tokens with.
+// Tokens are only issued with a key they are verified with: an HS256 token is rejected while a public key is set.
+func signingMethod() (jwt.SigningMethod, interface{}, error) {
+	if privateKey != nil {
+		return jwt.SigningMethodRS256, privateKey, nil
+	}
+	if publicKey != nil {
+		return nil, nil, ErrNoSigningKey
+	}
+	key, err := signingSecret()
+	if err != nil {
+		return nil, nil, err
+	}
+	return jwt.SigningMethodHS256, key, nil
+}
+
+/* keyFunc is a function that returns the key the jwt parser verifies a token with
+ * Only the algorithm of the configured keys is accepted: RS256 when an RSA key is set, HS256 otherwise
+ * This rejects alg:none tokens, and HS256 tokens signed with the public key as the HMAC secret
+ */
+func keyFunc(token *jwt.Token) (interface{}, error) {
+	if key := verificationKey(); key != nil {
+		if token.Method != jwt.SigningMethodRS256 {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		return key, nil
+	}
+
+	if token.Method != jwt.SigningMethodHS256 {
+		return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+	}
+	return signingSecret()
+}
@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// useSecret makes the test sign and verify HS256 tokens with a fixed secret, and no RSA key.
+func useSecret(t *testing.T) {
+	t.Helper()
+	SetSecret([]byte("test-secret"))
+	SetPrivateKey(nil)
+	SetPublicKey(nil)
+	t.Cleanup(func() {
+		SetSecret(nil)
+		SetPrivateKey(nil)
+		SetPublicKey(nil)
+	})
+}
+
+func generateKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestVerifySigningMethods(t *testing.T) {
+	key := generateKey(t)
+	otherKey := generateKey(t)
+	publicPEM, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicPEM = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicPEM})
+
+	claims := func() jwt.Claims {
+		return Claims{
+			Username:         "alice",
+			RegisteredClaims: jwt.RegisteredClaims{ID: "jti", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+		}
+	}
+	signed := func(method jwt.SigningMethod, key interface{}) string {
+		token, err := jwt.NewWithClaims(method, claims()).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name       string
+		privateKey *rsa.PrivateKey
+		publicKey  *rsa.PublicKey
+		token      string
+		wantValid  bool
+	}{
+		{name: "hs256 with the secret", token: signed(jwt.SigningMethodHS256, []byte("test-secret")), wantValid: true},
+		{name: "hs256 with another secret", token: signed(jwt.SigningMethodHS256, []byte("guessed")), wantValid: false},
+		{name: "alg none", token: signed(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType), wantValid: false},
+		{
+			name:      "alg none with a public key",
+			publicKey: &key.PublicKey,
+			token:     signed(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType),
+			wantValid: false,
+		},
+		{name: "rs256 round trip", privateKey: key, token: signed(jwt.SigningMethodRS256, key), wantValid: true},
+		{name: "rs256 with an external public key", publicKey: &key.PublicKey, token: signed(jwt.SigningMethodRS256, key), wantValid: true},
+		{name: "rs256 signed with another key", publicKey: &key.PublicKey, token: signed(jwt.SigningMethodRS256, otherKey), wantValid: false},
+		{
+			name:      "hs256 signed with the public key",
+			publicKey: &key.PublicKey,
+			token:     signed(jwt.SigningMethodHS256, publicPEM),
+			wantValid: false,
+		},
+		{name: "hs256 once a private key is set", privateKey: key, token: signed(jwt.SigningMethodHS256, []byte("test-secret")), wantValid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useSecret(t)
+			SetPrivateKey(tt.privateKey)
+			SetPublicKey(tt.publicKey)
+
+			valid, err := VerifyJWT(tt.token)
+			if valid != tt.wantValid {
+				t.Errorf("VerifyJWT() = %v, %v, want valid %v", valid, err, tt.wantValid)
+			}
+		})
+	}
+}
+
+func TestCreateJWTSigningKey(t *testing.T) {
+	key := generateKey(t)
+
+	tests := []struct {
+		name       string
+		privateKey *rsa.PrivateKey
+		publicKey  *rsa.PublicKey
+		wantAlg    string
+		wantErr    error
+	}{
+		{name: "secret", wantAlg: "HS256"},
+		{name: "private key", privateKey: key, wantAlg: "RS256"},
+		{name: "private and public key", privateKey: key, publicKey: &key.PublicKey, wantAlg: "RS256"},
+		{name: "public key only", publicKey: &key.PublicKey, wantErr: ErrNoSigningKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useSecret(t)
+			SetPrivateKey(tt.privateKey)
+			SetPublicKey(tt.publicKey)
+
+			token, err := CreateJWT(Claims{Username: "alice"}, TokenOptions{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateJWT() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			// Every issued token passes verification.
+			claims, err := DecodeClaims(token)
+			if err != nil {
+				t.Fatalf("DecodeClaims() error = %v", err)
+			}
+			if claims.Username != "alice" {
+				t.Errorf("DecodeClaims() username = %q, want alice", claims.Username)
+			}
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if alg := parsed.Header["alg"]; alg != tt.wantAlg {
+				t.Errorf("CreateJWT() alg = %v, want %s", alg, tt.wantAlg)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// refreshAudience is the aud claim of refresh tokens, which keeps them from being used as access tokens.
+const refreshAudience = "refresh"
+
+// RefreshTokenTTL is how long a refresh token stays valid.
+var RefreshTokenTTL = 30 * 24 * time.Hour
+
+// ErrRefreshTokenExpired is returned by RefreshAccessToken when the refresh token has expired.
+var ErrRefreshTokenExpired = errors.New("refresh token expired")
+
+/* CreateRefreshToken is a function that creates a refresh token
+ * It takes the username of the user the token is issued to as a parameter and returns a string and an error
+ * The refresh token only identifies the user: the claims of the access tokens it refreshes, ie: the roles,
+ * are read anew by RefreshAccessToken, so a change of roles applies from the next refresh
+ * The refresh token is valid for RefreshTokenTTL, and is only accepted by RefreshAccessToken
+ * The error is nil if the token is created successfully, otherwise it contains an error message
+ */
+func CreateRefreshToken(username string) (string, error) {
+	if username == "" {
+		return "", fmt.Errorf("error creating refresh token: missing username")
+	}
+
+	claims := Claims{Username: username}
+	if err := (TokenOptions{TTL: RefreshTokenTTL, Audience: []string{refreshAudience}}).apply(&claims, time.Now()); err != nil {
+		return "", err
+	}
+
+	tokenString, err := sign(claims)
+	if err != nil {
+		return "", fmt.Errorf("error creating refresh token: %w", err)
+	}
+	return tokenString, nil
+}
+
+/* IdentityFunc returns the current application claims of a user, ie: their userID and roles, read from where
+ * the users are stored. It is given the username of a refresh token by RefreshAccessToken
+ */
+type IdentityFunc func(username string) (Claims, error)
+
+/* RefreshAccessToken is a function that exchanges a refresh token for a new access token
+ * It takes a refresh token and the IdentityFunc reading the claims of its user as parameters,
+ * and returns an access token, a new refresh token and an error
+ * The refresh token is rotated: it is revoked, and the client must replace it with the returned one
+ * The revocation is checked and recorded at once, so only one of concurrent calls with the same token succeeds
+ * The error is ErrRefreshTokenExpired if the refresh token has expired, ErrTokenRevoked if it was already used,
+ * the error of identify if the user cannot be read, otherwise it is nil if the token is valid
+ */
+func RefreshAccessToken(refresh string, identify IdentityFunc) (string, string, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(refresh, claims, keyFunc, jwt.WithAudience(refreshAudience))
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return "", "", ErrRefreshTokenExpired
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("error parsing refresh token: %w", err)
+	}
+
+	if claims.Username == "" {
+		return "", "", fmt.Errorf("refresh token has no username")
+	}
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return "", "", fmt.Errorf("refresh token cannot be rotated without jti and exp claims")
+	}
+	if !revocations.Revoke(claims.ID, claims.ExpiresAt.Time) {
+		return "", "", ErrTokenRevoked
+	}
+
+	// Only the username comes from the refresh token, the access token carries the current claims of the user.
+	app, err := identify(claims.Username)
+	if err != nil {
+		return "", "", fmt.Errorf("error reading the user of the refresh token: %w", err)
+	}
+	app.Username = claims.Username
+	access, err := CreateJWT(app, TokenOptions{})
+	if err != nil {
+		return "", "", err
+	}
+	newRefresh, err := CreateRefreshToken(claims.Username)
+	if err != nil {
+		return "", "", err
+	}
+	return access, newRefresh, nil
+}
+
+// isRefreshToken reports whether the claims are those of a refresh token.
+func isRefreshToken(claims jwt.MapClaims) bool {
+	audience, err := claims.GetAudience()
+	return err == nil && slices.Contains(audience, refreshAudience)
+}
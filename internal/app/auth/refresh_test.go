@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestCreateRefreshToken(t *testing.T) {
+	useSecret(t)
+
+	if _, err := CreateRefreshToken(""); err == nil {
+		t.Error("CreateRefreshToken() accepted an empty username")
+	}
+
+	token, err := CreateRefreshToken("alice")
+	if err != nil {
+		t.Fatalf("CreateRefreshToken() error = %v", err)
+	}
+	claims := &Claims{}
+	if _, err := jwt.ParseWithClaims(token, claims, keyFunc, jwt.WithAudience(refreshAudience)); err != nil {
+		t.Fatalf("parsing the refresh token: %v", err)
+	}
+	// The refresh token identifies the user only, the other claims are read when refreshing.
+	if claims.Username != "alice" || claims.UserID != 0 || claims.Roles != nil {
+		t.Errorf("refresh token claims = %+v, want the username alone", claims)
+	}
+}
+
+func TestRefreshAccessToken(t *testing.T) {
+	errUnknownUser := errors.New("unknown user")
+	identities := map[string]Claims{
+		"alice": {UserID: 1, Roles: []string{"admin"}},
+	}
+	identify := func(username string) (Claims, error) {
+		claims, ok := identities[username]
+		if !ok {
+			return Claims{}, errUnknownUser
+		}
+		return claims, nil
+	}
+	expiredRefreshToken := func(t *testing.T) string {
+		t.Helper()
+		token, err := sign(Claims{
+			Username: "alice",
+			RegisteredClaims: jwt.RegisteredClaims{
+				ID:        "refresh-jti",
+				Audience:  []string{refreshAudience},
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	refreshToken := func(username string) func(t *testing.T) string {
+		return func(t *testing.T) string {
+			t.Helper()
+			token, err := CreateRefreshToken(username)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return token
+		}
+	}
+
+	tests := []struct {
+		name    string
+		token   func(t *testing.T) string
+		reuse   bool
+		wantErr error
+	}{
+		{
+			name:  "rotated",
+			token: refreshToken("alice"),
+		},
+		{
+			name:    "reused after rotation",
+			token:   refreshToken("alice"),
+			reuse:   true,
+			wantErr: ErrTokenRevoked,
+		},
+		{
+			name:    "expired",
+			token:   expiredRefreshToken,
+			wantErr: ErrRefreshTokenExpired,
+		},
+		{
+			name:    "user no longer exists",
+			token:   refreshToken("mallory"),
+			wantErr: errUnknownUser,
+		},
+		{
+			name: "access token",
+			token: func(t *testing.T) string {
+				token, err := CreateJWT(Claims{Username: "alice"}, TokenOptions{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				return token
+			},
+			wantErr: jwt.ErrTokenInvalidClaims,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useSecret(t)
+			SetRevocationStore(NewMemoryRevocationStore())
+			t.Cleanup(func() { SetRevocationStore(NewMemoryRevocationStore()) })
+
+			token := tt.token(t)
+			if tt.reuse {
+				if _, _, err := RefreshAccessToken(token, identify); err != nil {
+					t.Fatalf("first RefreshAccessToken() error = %v", err)
+				}
+			}
+
+			access, newRefresh, err := RefreshAccessToken(token, identify)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RefreshAccessToken() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			// The access token carries the claims read by identify, ie: roles granted since the login.
+			claims, err := DecodeClaims(access)
+			if err != nil {
+				t.Fatalf("DecodeClaims() of the access token error = %v", err)
+			}
+			if claims.Username != "alice" || claims.UserID != 1 || !slices.Equal(claims.Roles, []string{"admin"}) {
+				t.Errorf("access token claims = %+v, want alice with userID 1 and the admin role", claims)
+			}
+			// The rotated refresh token is itself usable once, and is not an access token.
+			if newRefresh == token {
+				t.Error("RefreshAccessToken() returned the same refresh token")
+			}
+			if _, err := DecodeJWT(newRefresh); err == nil {
+				t.Error("DecodeJWT() accepted a refresh token as an access token")
+			}
+			if _, _, err := RefreshAccessToken(newRefresh, identify); err != nil {
+				t.Errorf("RefreshAccessToken() of the rotated token error = %v", err)
+			}
+		})
+	}
+}
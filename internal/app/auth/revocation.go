@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrTokenRevoked is returned when decoding a token revoked through the RevocationStore.
+var ErrTokenRevoked = errors.New("token revoked")
+
+/* RevocationStore keeps track of the revoked tokens, by their jti claim
+ * Revoke is given the expiry of the token, after which the token is rejected anyway and can be forgotten
+ * Revoke must be atomic: it reports false when the token was already revoked, so concurrent callers
+ * rotating the same refresh token cannot both succeed
+ */
+type RevocationStore interface {
+	Revoke(jti string, exp time.Time) bool
+	IsRevoked(jti string) bool
+}
+
+// revocations is the store consulted when decoding tokens, see SetRevocationStore.
+var revocations RevocationStore = NewMemoryRevocationStore()
+
+/* SetRevocationStore is a function that replaces the store revoked tokens are kept in
+ * It takes a RevocationStore as a parameter, ie: one shared between instances of the API
+ */
+func SetRevocationStore(store RevocationStore) {
+	revocations = store
+}
+
+/* MemoryRevocationStore is an in-memory RevocationStore
+ * Revoked tokens are forgotten once they expire, and revocations do not survive a restart
+ */
+type MemoryRevocationStore struct {
+	mu      sync.Mutex
+	revoked map[string]time.Time
+}
+
+/* NewMemoryRevocationStore is a function that creates an empty MemoryRevocationStore
+ */
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{revoked: make(map[string]time.Time)}
+}
+
+// Revoke records the token as revoked until exp, and forgets the revoked tokens that have expired.
+// It reports false when the token was already revoked.
+func (s *MemoryRevocationStore) Revoke(jti string, exp time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, expiry := range s.revoked {
+		if now.After(expiry) {
+			delete(s.revoked, id)
+		}
+	}
+	if _, ok := s.revoked[jti]; ok {
+		return false
+	}
+	s.revoked[jti] = exp
+	return true
+}
+
+// IsRevoked reports whether the token was revoked and has not expired yet.
+func (s *MemoryRevocationStore) IsRevoked(jti string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expiry, ok := s.revoked[jti]
+	return ok && time.Now().Before(expiry)
+}
+
+// newTokenID returns a random jti claim.
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating token id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// checkRevoked returns ErrTokenRevoked if the token with the given jti was revoked.
+func checkRevoked(jti string) error {
+	if jti != "" && revocations.IsRevoked(jti) {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+/* RevokeToken is a function that revokes an access or refresh token
+ * It takes a tokenString as a parameter and returns an error
+ * The token is rejected by DecodeJWT, DecodeClaims and RefreshAccessToken until it expires
+ * The error is nil if the token is revoked, otherwise it contains an error message
+ */
+func RevokeToken(tokenString string) error {
+	claims := &Claims{}
+	if _, err := jwt.ParseWithClaims(tokenString, claims, keyFunc); err != nil {
+		return fmt.Errorf("error parsing JWT token: %w", err)
+	}
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return fmt.Errorf("token cannot be revoked without jti and exp claims")
+	}
+	revocations.Revoke(claims.ID, claims.ExpiresAt.Time)
+	return nil
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRevokeToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		revoke  bool
+		wantErr error
+	}{
+		{name: "valid token"},
+		{name: "revoked token", revoke: true, wantErr: ErrTokenRevoked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useSecret(t)
+			SetRevocationStore(NewMemoryRevocationStore())
+			t.Cleanup(func() { SetRevocationStore(NewMemoryRevocationStore()) })
+
+			token, err := CreateJWT(Claims{Username: "alice"}, TokenOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			other, err := CreateJWT(Claims{Username: "alice"}, TokenOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.revoke {
+				if err := RevokeToken(token); err != nil {
+					t.Fatalf("RevokeToken() error = %v", err)
+				}
+			}
+
+			if _, err := DecodeJWT(token); !errors.Is(err, tt.wantErr) {
+				t.Errorf("DecodeJWT() error = %v, want %v", err, tt.wantErr)
+			}
+			if _, err := DecodeClaims(token); !errors.Is(err, tt.wantErr) {
+				t.Errorf("DecodeClaims() error = %v, want %v", err, tt.wantErr)
+			}
+			// Revocation is per token, so other tokens of the same user stay valid.
+			if _, err := DecodeJWT(other); err != nil {
+				t.Errorf("DecodeJWT() of another token error = %v", err)
+			}
+		})
+	}
+}
+
+func TestMemoryRevocationStore(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		exp         time.Time
+		revokeTwice bool
+		wantRevoked bool
+	}{
+		{name: "revoked until expiry", exp: now.Add(time.Hour), wantRevoked: true},
+		{name: "expired revocation forgotten", exp: now.Add(-time.Second)},
+		{name: "second revocation reported", exp: now.Add(time.Hour), revokeTwice: true, wantRevoked: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryRevocationStore()
+			if !store.Revoke("jti", tt.exp) {
+				t.Fatal("Revoke() = false for a new token")
+			}
+			if tt.revokeTwice && store.Revoke("jti", tt.exp) {
+				t.Error("Revoke() = true for an already revoked token")
+			}
+			if revoked := store.IsRevoked("jti"); revoked != tt.wantRevoked {
+				t.Errorf("IsRevoked() = %v, want %v", revoked, tt.wantRevoked)
+			}
+		})
+	}
+}
@@ -1,26 +1,78 @@
 package auth
 
 import (
+	"api/internal/app/utils"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var developmentSecret = "development"
+// developmentSecret signs tokens when JWT_SECRET is unset outside of production.
+const developmentSecret = "development"
+
+// secret is the HMAC key tokens are signed and verified with, resolved on first use, see signingSecret.
+var (
+	secretMu sync.Mutex
+	secret   []byte
+)
+
+/* signingSecret is a function that returns the HMAC key, loading it from JWT_SECRET, or the file at JWT_SECRET_FILE, on first use
+ * It is resolved lazily so a secret set in .env, which main loads at startup, is used
+ * It returns an error when the secret is unset and APP_ENV is "production"
+ * Otherwise an unset secret falls back to the development secret, with a warning
+ */
+func signingSecret() ([]byte, error) {
+	secretMu.Lock()
+	defer secretMu.Unlock()
+	if secret != nil {
+		return secret, nil
+	}
+
+	value, err := utils.Secret("JWT_SECRET")
+	if err != nil {
+		return nil, fmt.Errorf("error loading JWT secret: %w", err)
+	}
+	if value == "" {
+		if os.Getenv("APP_ENV") == "production" {
+			return nil, errors.New("JWT_SECRET must be set in production")
+		}
+		log.Println("warning: JWT_SECRET is not set, using the development secret")
+		value = developmentSecret
+	}
+	secret = []byte(value)
+	return secret, nil
+}
+
+/* SetSecret is a function that replaces the secret tokens are signed and verified with
+ * It takes the secret as a parameter, and is meant for tests, nil resolving it from the environment again
+ */
+func SetSecret(s []byte) {
+	secretMu.Lock()
+	defer secretMu.Unlock()
+	secret = s
+}
 
 /* CreateJWT is a function that creates a JWT token
- * It takes a username as a parameter and returns a string and an error
+ * It takes the claims and the token options as parameters and returns a string and an error
+ * The registered claims are set from the options, overriding those of the claims
+ * The token is signed with RS256 when a private key is set, see SetPrivateKey, and HS256 otherwise
  * The string is the JWT token
  * The error is nil if the token is created successfully, otherwise it contains an error message
  */
-func CreateJWT(username string) (string, error) {
-	claims := jwt.MapClaims{}
-	claims["username"] = username
-	claims["exp"] = time.Now().Add(time.Hour * 24).Unix()
+func CreateJWT(claims Claims, opts TokenOptions) (string, error) {
+	if err := opts.apply(&claims, time.Now()); err != nil {
+		return "", err
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(developmentSecret))
+	tokenString, err := sign(claims)
 	if err != nil {
 		return "", fmt.Errorf("error creating JWT token: %w", err)
 	}
@@ -29,34 +81,114 @@ func CreateJWT(username string) (string, error) {
 
 /* VerifyJWT is a function that verifies a JWT token
  * It takes a tokenString as a parameter and returns a boolean and an error
- * The boolean is true if the token is valid, false otherwise
+ * The boolean is true if the token is a valid access token, false otherwise
  * The error is nil if the token is valid, otherwise it contains an error message
  */
 func VerifyJWT(tokenString string) (bool, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return []byte(developmentSecret), nil
-	})
-	if err != nil {
-		return false, fmt.Errorf("error parsing JWT token: %w", err)
+	if _, err := DecodeJWT(tokenString); err != nil {
+		return false, err
 	}
-	return token.Valid, nil
+	return true, nil
 }
 
 /* DecodeJWT is a function that decodes a JWT token and returns the claims
  * It takes a tokenString as a parameter and returns a map of claims and an error
  * The map of claims contains the information stored in the token
+ * Refresh tokens and revoked tokens are rejected, see CreateRefreshToken and RevokeToken
  * The error is nil if the token is decoded successfully, otherwise it contains an error message
  */
 func DecodeJWT(tokenString string) (jwt.MapClaims, error) {
 	claims := jwt.MapClaims{}
-	token, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(developmentSecret), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &claims, keyFunc)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing JWT token: %w", err)
 	}
 	if !token.Valid {
 		return nil, fmt.Errorf("invalid JWT token")
 	}
+	if isRefreshToken(claims) {
+		return nil, fmt.Errorf("refresh token used as access token")
+	}
+	jti, _ := claims["jti"].(string)
+	if err := checkRevoked(jti); err != nil {
+		return nil, err
+	}
 	return claims, nil
 }
+
+/* ClaimsFromRequest is a function that decodes the bearer token of a request
+ * It takes an http.Request as a parameter and returns a map of claims and an error
+ * The token is read from the Authorization header using the "Bearer <token>" scheme,
+ * unless the claims were already verified and stored in the request context by the auth middleware
+ * The error is nil if the token is present and valid, otherwise it contains an error message
+ */
+func ClaimsFromRequest(r *http.Request) (jwt.MapClaims, error) {
+	if claims, ok := ClaimsFromContext(r.Context()); ok {
+		return claims, nil
+	}
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return nil, fmt.Errorf("missing bearer token")
+	}
+	return DecodeJWT(strings.TrimPrefix(header, "Bearer "))
+}
+
+/* IsAdmin is a function that reports whether the claims carry the admin role
+ * It takes a map of claims as a parameter and returns a boolean
+ */
+func IsAdmin(claims jwt.MapClaims) bool {
+	return HasRole(claims, "admin")
+}
+
+/* IsUser is a function that reports whether the claims belong to a user
+ * It takes a map of claims and a user ID as parameters and returns a boolean
+ * The user ID is read from the userID claim, which is decoded as a JSON number
+ */
+func IsUser(claims jwt.MapClaims, userID int64) bool {
+	switch id := claims["userID"].(type) {
+	case float64:
+		return id == float64(userID)
+	case int64:
+		return id == userID
+	case json.Number:
+		n, err := id.Int64()
+		return err == nil && n == userID
+	}
+	return false
+}
+
+/* HasRole is a function that reports whether the claims carry a role
+ * It takes a map of claims and a role as parameters and returns a boolean
+ * The role is looked up in the roles claim, and in the single role claim of older tokens
+ */
+func HasRole(claims jwt.MapClaims, role string) bool {
+	if legacy, _ := claims["role"].(string); legacy == role {
+		return true
+	}
+	roles, _ := claims["roles"].([]interface{})
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// RefreshWindow is how long before expiry a token should be refreshed.
+const RefreshWindow = time.Hour
+
+/* ExpiresIn is a function that returns the time left before the claims expire
+ * It takes a map of claims as a parameter and returns a duration and an error
+ * The duration is computed from the exp claim, and is negative once the token has expired
+ * The error is non nil if the claims carry no valid exp claim
+ */
+func ExpiresIn(claims jwt.MapClaims) (time.Duration, error) {
+	exp, err := claims.GetExpirationTime()
+	if err != nil {
+		return 0, fmt.Errorf("error reading exp claim: %w", err)
+	}
+	if exp == nil {
+		return 0, fmt.Errorf("missing exp claim")
+	}
+	return time.Until(exp.Time), nil
+}
@@ -0,0 +1,36 @@
+package controller
+
+import (
+	neo "api/internal/app/neo4j"
+	"api/internal/app/rest"
+	"api/internal/app/routing"
+	"errors"
+	"net/http"
+)
+
+// GetOrphans is registered behind middleware.RequireRole("admin").
+func GetOrphans(w http.ResponseWriter, r *http.Request, rctx routing.Context) {
+	label := rctx.GetQueryParam("label")
+	if label == "" {
+		http.Error(w, "missing label", http.StatusBadRequest)
+		return
+	}
+
+	rel := rctx.GetQueryParam("rel")
+	if rel == "" {
+		rel = neo.RelHas
+	}
+
+	orphans, err := neo.FindOrphans(r.Context(), label, rel)
+
+	if err != nil {
+		if errors.Is(err, neo.ErrUnknownType) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		rest.InternalError(w, r, err)
+		return
+	}
+
+	rest.Respond(w, r, http.StatusOK, orphans)
+}
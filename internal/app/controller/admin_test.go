@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestGetOrphans(t *testing.T) {
+	tests := []struct {
+		name        string
+		target      string
+		wantStatus  int
+		wantQueries int
+	}{
+		{name: "registered label", target: "/api/admin/orphans?label=Zone", wantStatus: http.StatusOK, wantQueries: 1},
+		{name: "missing label", target: "/api/admin/orphans", wantStatus: http.StatusBadRequest},
+		{name: "unknown label", target: "/api/admin/orphans?label=Planet", wantStatus: http.StatusBadRequest},
+		{name: "unknown relationship", target: "/api/admin/orphans?label=Zone&rel=CONTAINS", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, nil)
+
+			w := serve(GetOrphans, "GET", "/api/admin/orphans", tt.target, "", userClaims("root", 3, "admin"))
+			if w.Code != tt.wantStatus {
+				t.Errorf("GetOrphans() status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if queries := driver.Queries(); len(queries) != tt.wantQueries {
+				t.Errorf("GetOrphans() ran %d queries, want %d", len(queries), tt.wantQueries)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package controller
+
+import (
+	neoModels "api/internal/app/models/neo"
+	neo "api/internal/app/neo4j"
+	"api/internal/app/rest"
+	"api/internal/app/routing"
+	"errors"
+	"net/http"
+)
+
+func GetCityAncestry(w http.ResponseWriter, r *http.Request, rctx routing.Context) {
+	id := rctx.GetPathParam("id")
+	if id == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+
+	var city neoModels.City
+	ancestry, err := city.Ancestry(r.Context(), id)
+
+	if err != nil {
+		if errors.Is(err, neo.ErrNotFound) {
+			http.Error(w, "City not found", http.StatusNotFound)
+			return
+		}
+		rest.InternalError(w, r, err)
+		return
+	}
+
+	rest.Respond(w, r, http.StatusOK, ancestry)
+}
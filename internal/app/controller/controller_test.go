@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"api/internal/app/auth"
+	neoModels "api/internal/app/models/neo"
+	neo "api/internal/app/neo4j"
+	"api/internal/app/neo4j/neotest"
+	"api/internal/app/rest"
+	"api/internal/app/routing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// The models are registered as in main.
+func init() {
+	neo.RegisterModel("User", &neoModels.User{})
+	neo.RegisterModel("World", &neoModels.World{})
+	neo.RegisterModel("Ocean", &neoModels.Ocean{})
+	neo.RegisterModel("Continent", &neoModels.Continent{})
+	neo.RegisterModel("Zone", &neoModels.Zone{})
+	neo.RegisterModel("Location", &neoModels.Location{})
+	neo.RegisterModel("City", &neoModels.City{})
+}
+
+// useFakeDriver makes the handlers of the test run their queries against a fake driver answering with respond.
+func useFakeDriver(t *testing.T, respond neotest.Responder) *neotest.Driver {
+	t.Helper()
+	driver := neotest.NewDriver(respond)
+	neo.SetDriver(driver)
+	t.Cleanup(func() { neo.SetDriver(nil) })
+	return driver
+}
+
+// respondTo answers the queries containing a fragment with its response, and other queries with no records.
+func respondTo(responses map[string]neotest.Response) neotest.Responder {
+	return func(query neotest.Query) neotest.Response {
+		for fragment, response := range responses {
+			if strings.Contains(query.Cypher, fragment) {
+				return response
+			}
+		}
+		return neotest.Response{}
+	}
+}
+
+// serve routes a request to handler registered under pattern, with claims stored in its context when not nil.
+func serve(handler routing.HTTPHandlerWithContext, method string, pattern string, target string, body string, claims jwt.MapClaims) *httptest.ResponseRecorder {
+	router := routing.NewRouter()
+	router.Handle(method, pattern, handler)
+
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if claims != nil {
+		r = r.WithContext(auth.WithClaims(r.Context(), claims))
+	}
+	w := httptest.NewRecorder()
+	router.NewServer("0", routing.ServeOptions{}).Handler.ServeHTTP(w, r)
+	return w
+}
+
+// userClaims returns the claims of a token issued to a user, with the given roles.
+func userClaims(username string, userID int64, roles ...any) jwt.MapClaims {
+	return jwt.MapClaims{"username": username, "userID": float64(userID), "roles": roles}
+}
+
+func TestAfterResponseEndpoints(t *testing.T) {
+	// Only requests carrying X-Stamp are stamped, so the post-processor leaves other tests alone.
+	rest.AfterResponse(func(meta *rest.ResponseMeta) {
+		if meta.Request.Header.Get("X-Stamp") == "" {
+			return
+		}
+		meta.Header.Set("X-Stamped", "true")
+		if body, ok := meta.Body.(map[string]interface{}); ok {
+			body["stamped"] = true
+		}
+	})
+
+	claims := jwt.MapClaims{"username": "alice", "exp": float64(time.Now().Add(time.Hour).Unix())}
+	world := neotest.Node("4:db:1", []string{"World"}, map[string]any{"name": "Atlantis"})
+
+	tests := []struct {
+		name        string
+		handler     routing.HTTPHandlerWithContext
+		pattern     string
+		target      string
+		accept      string
+		wantStamped bool
+	}{
+		{name: "world", handler: GetWorld, pattern: "/api/world/:id", target: "/api/world/4:db:1", wantStamped: true},
+		{name: "session", handler: GetSession, pattern: "/api/auth/session", target: "/api/auth/session", wantStamped: true},
+		// A session is not a model, so it stays plain JSON when a JSON:API document is asked for.
+		{name: "session as json api", handler: GetSession, pattern: "/api/auth/session", target: "/api/auth/session", accept: rest.JSONAPIMediaType, wantStamped: true},
+		// Arrays are passed to post-processors too, which leave them as is here.
+		{name: "labels", handler: GetLabels, pattern: "/api/meta/labels", target: "/api/meta/labels"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useFakeDriver(t, func(neotest.Query) neotest.Response {
+				return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", world, "relatedNodes", []any{})}}
+			})
+
+			router := routing.NewRouter()
+			router.Handle("GET", tt.pattern, tt.handler)
+			r := httptest.NewRequest("GET", tt.target, nil)
+			r.Header.Set("X-Stamp", "1")
+			r.Header.Set("Accept", tt.accept)
+			r = r.WithContext(auth.WithClaims(r.Context(), claims))
+			w := httptest.NewRecorder()
+			router.NewServer("0", routing.ServeOptions{}).Handler.ServeHTTP(w, r)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("GET %s status = %d: %s", tt.target, w.Code, w.Body)
+			}
+			if w.Header().Get("X-Stamped") != "true" {
+				t.Errorf("GET %s skipped the post-processors", tt.target)
+			}
+			var body any
+			if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+				t.Fatalf("GET %s body: %v", tt.target, err)
+			}
+			object, _ := body.(map[string]any)
+			if stamped := object["stamped"] == true; stamped != tt.wantStamped {
+				t.Errorf("GET %s body = %v, want stamped %v", tt.target, body, tt.wantStamped)
+			}
+		})
+	}
+}
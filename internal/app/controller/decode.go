@@ -0,0 +1,14 @@
+package controller
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// decodeJSON decodes a request body into v. Numbers decoded into interface{} values are kept as json.Number
+// rather than float64, so integer ids keep matching integer node properties once they reach a query.
+func decodeJSON(body io.Reader, v interface{}) error {
+	decoder := json.NewDecoder(body)
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
@@ -0,0 +1,22 @@
+package controller
+
+import "encoding/json"
+
+// withRelationshipIDs replaces the relationship fields of a model's JSON representation
+// with the elementIds of the related nodes, for ?expand=ids responses.
+func withRelationshipIDs(model interface{}, ids map[string][]string) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(model)
+	if err != nil {
+		return nil, err
+	}
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(encoded, &response); err != nil {
+		return nil, err
+	}
+
+	for field, values := range ids {
+		response[field] = values
+	}
+	return response, nil
+}
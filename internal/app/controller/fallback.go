@@ -0,0 +1,18 @@
+package controller
+
+import (
+	"api/internal/app/rest"
+	"api/internal/app/routing"
+	"net/http"
+)
+
+// NotFound answers requests matching no route with a JSON error, see routing.Router.NotFoundHandler.
+func NotFound(w http.ResponseWriter, r *http.Request, rctx routing.Context) {
+	rest.RespondError(w, http.StatusNotFound, "not found")
+}
+
+// MethodNotAllowed answers requests using a method the path is not registered under with a JSON error,
+// see routing.Router.MethodNotAllowedHandler. The router has already set the Allow header.
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request, rctx routing.Context) {
+	rest.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
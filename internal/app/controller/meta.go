@@ -0,0 +1,12 @@
+package controller
+
+import (
+	neo "api/internal/app/neo4j"
+	"api/internal/app/rest"
+	"api/internal/app/routing"
+	"net/http"
+)
+
+func GetLabels(w http.ResponseWriter, r *http.Request, rctx routing.Context) {
+	rest.Respond(w, r, http.StatusOK, neo.Schema())
+}
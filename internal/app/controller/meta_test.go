@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	neo "api/internal/app/neo4j"
+)
+
+func TestGetLabelsZone(t *testing.T) {
+	w := serve(GetLabels, "GET", "/api/meta/labels", "/api/meta/labels", "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GetLabels() status = %d, want 200", w.Code)
+	}
+
+	var labels []neo.LabelSchema
+	if err := json.NewDecoder(w.Body).Decode(&labels); err != nil {
+		t.Fatalf("GetLabels() body: %v", err)
+	}
+	var zone *neo.LabelSchema
+	for i := range labels {
+		if labels[i].Label == "Zone" {
+			zone = &labels[i]
+		}
+	}
+	if zone == nil {
+		t.Fatalf("GetLabels() = %v, want a Zone entry", labels)
+	}
+
+	properties := map[string]string{}
+	for _, property := range zone.Properties {
+		properties[property.Name] = property.Type
+	}
+	relationships := map[string]neo.RelationshipSchema{}
+	for _, relationship := range zone.Relationships {
+		relationships[relationship.Field] = relationship
+	}
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{name: "biome property", got: properties["biome"], want: "string"},
+		{name: "name property", got: properties["name"], want: "string"},
+		{name: "cities relationship", got: relationships["cities"], want: neo.RelationshipSchema{
+			Field: "cities", Type: neo.RelHas, Direction: "->", Target: "City", Many: true,
+		}},
+		{name: "locations relationship", got: relationships["locations"], want: neo.RelationshipSchema{
+			Field: "locations", Type: neo.RelHas, Direction: "->", Target: "Location", Many: true,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("Zone %s = %v, want %v", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package controller
+
+import (
+	neo "api/internal/app/neo4j"
+	"api/internal/app/routing"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// defaultPageSize is the page size of a relationship collection given a page but no page size.
+const defaultPageSize = 50
+
+// maxPageSize is the largest page size of a relationship collection, larger requested sizes are clamped to it.
+const maxPageSize = 200
+
+// relationshipPages reads the <field>.page and <field>.pageSize query parameters
+// into the pages of the relationship collections to populate.
+func relationshipPages(rctx routing.Context) (map[string]neo.Page, error) {
+	pages := make(map[string]neo.Page)
+	for key, value := range rctx.QueryParams {
+		field, param, ok := strings.Cut(key, ".")
+		if !ok || (param != "page" && param != "pageSize") {
+			continue
+		}
+
+		number, err := strconv.Atoi(value)
+		if err != nil || number < 1 {
+			return nil, fmt.Errorf("invalid %s: must be a positive integer", key)
+		}
+
+		page, ok := pages[field]
+		if !ok {
+			page = neo.Page{Page: 1, PageSize: defaultPageSize}
+		}
+		if param == "page" {
+			page.Page = number
+		} else {
+			page.PageSize = min(number, maxPageSize)
+		}
+		pages[field] = page
+	}
+	return pages, nil
+}
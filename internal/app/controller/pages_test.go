@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"api/internal/app/neo4j/neotest"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+func TestGetWorldPages(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantPage   string // fragment of the populate query paging the collection
+	}{
+		{
+			name:       "nested collection",
+			query:      "cities.page=2&cities.pageSize=50",
+			wantStatus: http.StatusOK,
+			wantPage:   `SKIP 50 LIMIT 50 RETURN {node: r3, rel: properties(e3), field: "cities"`,
+		},
+		{
+			name:       "default page size",
+			query:      "zones.page=3",
+			wantStatus: http.StatusOK,
+			wantPage:   `SKIP 100 LIMIT 50 RETURN {node: r2, rel: properties(e2), field: "zones"`,
+		},
+		{
+			name:       "page size clamped",
+			query:      "continents.pageSize=1000",
+			wantStatus: http.StatusOK,
+			wantPage:   `SKIP 0 LIMIT 200 RETURN {node: r1, rel: properties(e1), field: "continents"`,
+		},
+		{
+			name:       "zero page",
+			query:      "cities.page=0",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "non numeric page size",
+			query:      "cities.pageSize=ten",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown relationship",
+			query:      "rivers.page=2",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "collection below depth",
+			query:      "depth=1&cities.page=2",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	world := neotest.Node("4:db:1", []string{"World"}, map[string]any{"name": "Atlantis"})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, func(neotest.Query) neotest.Response {
+				return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", world, "relatedNodes", []any{})}}
+			})
+
+			w := serve(GetWorld, "GET", "/api/world/:id", "/api/world/4:db:1?"+tt.query, "", nil)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("GetWorld() status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				if queries := driver.Queries(); len(queries) != 0 {
+					t.Errorf("GetWorld() ran %d queries, want none", len(queries))
+				}
+				return
+			}
+
+			query := driver.Queries()[0].Cypher
+			if !strings.Contains(query, tt.wantPage) {
+				t.Errorf("GetWorld() ran %q, want it to contain %q", query, tt.wantPage)
+			}
+			if strings.Count(query, "SKIP") != 1 {
+				t.Errorf("GetWorld() ran %q, want a single paged collection", query)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	neoModels "api/internal/app/models/neo"
+	neo "api/internal/app/neo4j"
+	"api/internal/app/routing"
+)
+
+// relType returns the relationship type of the rel tag of a model field.
+func relType(model any, field string) string {
+	f, _ := reflect.TypeOf(model).FieldByName(field)
+	return strings.Split(f.Tag.Get("rel"), ",")[0]
+}
+
+func TestControllersUseModelRelationshipTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		handler  routing.HTTPHandlerWithContext
+		method   string
+		pattern  string
+		target   string
+		body     string
+		constant string
+		tag      string
+		fragment string
+	}{
+		{
+			name:     "create world",
+			handler:  CreateWorld,
+			method:   "POST",
+			pattern:  "/api/user/:id/world",
+			target:   "/api/user/1/world",
+			body:     `{"name": "Atlantis"}`,
+			constant: neo.RelOwns,
+			tag:      relType(neoModels.User{}, "Worlds"),
+			fragment: "(n)<-[e:%s]-(r)",
+		},
+		{
+			name:     "transfer world",
+			handler:  TransferWorld,
+			method:   "POST",
+			pattern:  "/api/world/:id/transfer",
+			target:   "/api/world/4:db:1/transfer",
+			body:     `{"newUserId": 2}`,
+			constant: neo.RelOwns,
+			tag:      relType(neoModels.User{}, "Worlds"),
+			fragment: "OPTIONAL MATCH (o:User)-[:%s]->(n)",
+		},
+		{
+			name:     "orphans",
+			handler:  GetOrphans,
+			method:   "GET",
+			pattern:  "/api/admin/orphans",
+			target:   "/api/admin/orphans?label=Continent",
+			constant: neo.RelHas,
+			tag:      relType(neoModels.World{}, "Continents"),
+			fragment: "NOT ()-[:%s]->(n)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.tag != tt.constant {
+				t.Fatalf("model tag uses %q, controller constant is %q", tt.tag, tt.constant)
+			}
+
+			driver := useFakeDriver(t, nil)
+			serve(tt.handler, tt.method, tt.pattern, tt.target, tt.body, userClaims("alice", 1, "admin"))
+
+			want := fmt.Sprintf(tt.fragment, tt.tag)
+			queries := driver.Queries()
+			if len(queries) == 0 || !strings.Contains(queries[0].Cypher, want) {
+				t.Errorf("%s ran %v, want a query containing %q", tt.name, queries, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"api/internal/app/auth"
+	"api/internal/app/rest"
+	"api/internal/app/routing"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// session describes the bearer token of a request, see GetSession.
+type session struct {
+	Username      string   `json:"username"`
+	Roles         []string `json:"roles"`
+	ExpiresIn     int64    `json:"expiresIn"`     // seconds until the token expires
+	ShouldRefresh bool     `json:"shouldRefresh"` // whether the token is within auth.RefreshWindow of expiring
+}
+
+func GetSession(w http.ResponseWriter, r *http.Request, rctx routing.Context) {
+	claims, err := auth.ClaimsFromRequest(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	expiresIn, err := auth.ExpiresIn(claims)
+	if err != nil || expiresIn <= 0 {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	username, _ := claims["username"].(string)
+
+	rest.Respond(w, r, http.StatusOK, session{
+		Username:      username,
+		Roles:         claimRoles(claims),
+		ExpiresIn:     int64(expiresIn.Seconds()),
+		ShouldRefresh: expiresIn <= auth.RefreshWindow,
+	})
+}
+
+// claimRoles returns the roles claim, or the single role claim of legacy tokens.
+func claimRoles(claims jwt.MapClaims) []string {
+	roles := []string{}
+	values, _ := claims["roles"].([]interface{})
+	for _, value := range values {
+		if role, ok := value.(string); ok {
+			roles = append(roles, role)
+		}
+	}
+	if legacy, _ := claims["role"].(string); len(roles) == 0 && legacy != "" {
+		roles = append(roles, legacy)
+	}
+	return roles
+}
+
+// tokenPair is the Refresh response.
+type tokenPair struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+// refreshIdentity reads the current claims of the user of a refresh token, replaced in tests.
+var refreshIdentity auth.IdentityFunc = userIdentity
+
+/*
+Refresh exchanges the refresh token of the request body for a new access token and a rotated refresh token.
+The access token carries the claims the user has now, not those they had when logging in.
+An expired refresh token is answered with a 401 and a distinct message, so clients know to log in again.
+*/
+func Refresh(w http.ResponseWriter, r *http.Request, context routing.Context) {
+	var body struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+	if err := decodeJSON(r.Body, &body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if body.RefreshToken == "" {
+		http.Error(w, "missing refreshToken", http.StatusBadRequest)
+		return
+	}
+
+	token, refreshToken, err := auth.RefreshAccessToken(body.RefreshToken, refreshIdentity)
+	if errors.Is(err, auth.ErrRefreshTokenExpired) {
+		http.Error(w, "Refresh token expired", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	rest.Respond(w, r, http.StatusOK, tokenPair{Token: token, RefreshToken: refreshToken})
+}
+
+/*
+Logout revokes the bearer token of the request, and the refresh token of the request body when one is given.
+It is registered behind middleware.RequireAuth.
+*/
+func Logout(w http.ResponseWriter, r *http.Request, rctx routing.Context) {
+	var body struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+	if r.ContentLength != 0 {
+		if err := decodeJSON(r.Body, &body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if err := auth.RevokeToken(token); err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if body.RefreshToken != "" {
+		if err := auth.RevokeToken(body.RefreshToken); err != nil {
+			http.Error(w, "invalid refreshToken", http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
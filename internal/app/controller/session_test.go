@@ -0,0 +1,195 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"api/internal/app/auth"
+	"api/internal/app/routing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestGetSession(t *testing.T) {
+	claimsExpiringIn := func(ttl time.Duration) jwt.MapClaims {
+		return jwt.MapClaims{"username": "alice", "roles": []any{"admin", "editor"}, "exp": float64(time.Now().Add(ttl).Unix())}
+	}
+	fresh, err := auth.CreateJWT(auth.Claims{Username: "alice", Roles: []string{"editor"}}, auth.TokenOptions{TTL: 2 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name              string
+		claims            jwt.MapClaims
+		token             string
+		wantStatus        int
+		wantUsername      string
+		wantRoles         []string
+		wantShouldRefresh bool
+		wantMinExpiresIn  int64
+		wantMaxExpiresIn  int64
+	}{
+		{
+			name:             "fresh token",
+			claims:           claimsExpiringIn(24 * time.Hour),
+			wantStatus:       http.StatusOK,
+			wantUsername:     "alice",
+			wantRoles:        []string{"admin", "editor"},
+			wantMinExpiresIn: int64((24*time.Hour - time.Minute).Seconds()),
+			wantMaxExpiresIn: int64((24 * time.Hour).Seconds()),
+		},
+		{
+			name:              "near expiry",
+			claims:            claimsExpiringIn(10 * time.Minute),
+			wantStatus:        http.StatusOK,
+			wantUsername:      "alice",
+			wantRoles:         []string{"admin", "editor"},
+			wantShouldRefresh: true,
+			wantMinExpiresIn:  int64((9 * time.Minute).Seconds()),
+			wantMaxExpiresIn:  int64((10 * time.Minute).Seconds()),
+		},
+		{
+			name:             "legacy role claim",
+			claims:           jwt.MapClaims{"username": "alice", "role": "admin", "exp": float64(time.Now().Add(24 * time.Hour).Unix())},
+			wantStatus:       http.StatusOK,
+			wantUsername:     "alice",
+			wantRoles:        []string{"admin"},
+			wantMinExpiresIn: int64((24*time.Hour - time.Minute).Seconds()),
+			wantMaxExpiresIn: int64((24 * time.Hour).Seconds()),
+		},
+		{
+			name:             "no role",
+			claims:           jwt.MapClaims{"username": "alice", "exp": float64(time.Now().Add(24 * time.Hour).Unix())},
+			wantStatus:       http.StatusOK,
+			wantUsername:     "alice",
+			wantRoles:        []string{},
+			wantMinExpiresIn: int64((24*time.Hour - time.Minute).Seconds()),
+			wantMaxExpiresIn: int64((24 * time.Hour).Seconds()),
+		},
+		{
+			name:       "expired",
+			claims:     claimsExpiringIn(-time.Minute),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "no exp claim",
+			claims:     jwt.MapClaims{"username": "alice"},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:             "bearer token",
+			token:            fresh,
+			wantStatus:       http.StatusOK,
+			wantUsername:     "alice",
+			wantRoles:        []string{"editor"},
+			wantMinExpiresIn: int64((2*time.Hour - time.Minute).Seconds()),
+			wantMaxExpiresIn: int64((2 * time.Hour).Seconds()),
+		},
+		{
+			name:       "malformed bearer token",
+			token:      "not-a-token",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "no token",
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var w *httptest.ResponseRecorder
+			if tt.token != "" {
+				router := routing.NewRouter()
+				router.Handle("GET", "/api/auth/session", GetSession)
+				r := httptest.NewRequest("GET", "/api/auth/session", nil)
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+				w = httptest.NewRecorder()
+				router.NewServer("0", routing.ServeOptions{}).Handler.ServeHTTP(w, r)
+			} else {
+				w = serve(GetSession, "GET", "/api/auth/session", "/api/auth/session", "", tt.claims)
+			}
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("GetSession() status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var got session
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatalf("GetSession() body: %v", err)
+			}
+			if got.Username != tt.wantUsername || !slices.Equal(got.Roles, tt.wantRoles) {
+				t.Errorf("GetSession() = %+v, want username %q and roles %q", got, tt.wantUsername, tt.wantRoles)
+			}
+			if got.ShouldRefresh != tt.wantShouldRefresh {
+				t.Errorf("GetSession() shouldRefresh = %v, want %v", got.ShouldRefresh, tt.wantShouldRefresh)
+			}
+			if got.ExpiresIn < tt.wantMinExpiresIn || got.ExpiresIn > tt.wantMaxExpiresIn {
+				t.Errorf("GetSession() expiresIn = %d, want between %d and %d", got.ExpiresIn, tt.wantMinExpiresIn, tt.wantMaxExpiresIn)
+			}
+		})
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	roles := []string{"editor"}
+	refreshIdentity = func(username string) (auth.Claims, error) {
+		if username != "alice" {
+			return auth.Claims{}, errors.New("record not found")
+		}
+		return auth.Claims{UserID: 1, Roles: roles}, nil
+	}
+	t.Cleanup(func() { refreshIdentity = userIdentity })
+
+	refreshToken := func(username string) string {
+		token, err := auth.CreateRefreshToken(username)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantRoles  []string
+	}{
+		{name: "current roles", body: `{"refreshToken": "` + refreshToken("alice") + `"}`, wantStatus: http.StatusOK, wantRoles: roles},
+		{name: "deleted user", body: `{"refreshToken": "` + refreshToken("bob") + `"}`, wantStatus: http.StatusUnauthorized},
+		{name: "missing token", body: `{}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(Refresh, "POST", "/api/auth/refresh", "/api/auth/refresh", tt.body, nil)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Refresh() status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var pair tokenPair
+			if err := json.NewDecoder(w.Body).Decode(&pair); err != nil {
+				t.Fatalf("Refresh() body: %v", err)
+			}
+			claims, err := auth.DecodeClaims(pair.Token)
+			if err != nil {
+				t.Fatalf("DecodeClaims() error = %v", err)
+			}
+			if claims.Username != "alice" || !slices.Equal(claims.Roles, tt.wantRoles) {
+				t.Errorf("Refresh() issued %+v, want alice with roles %q", claims, tt.wantRoles)
+			}
+		})
+	}
+}
@@ -1,25 +1,29 @@
 package controller
 
 import (
+	"api/internal/app/auth"
 	"api/internal/app/models"
 	neoModels "api/internal/app/models/neo"
 	neo "api/internal/app/neo4j"
 	"api/internal/app/postgres"
+	"api/internal/app/rest"
 	"api/internal/app/routing"
-	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+
+	"gorm.io/gorm"
 )
 
 func CreateUser(w http.ResponseWriter, r *http.Request, context routing.Context) {
 	var user models.User
-	db, err := postgres.Connect()
+	db, err := postgres.Shared()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		rest.InternalError(w, r, err)
 		return
 	}
 
-	err = json.NewDecoder(r.Body).Decode(&user)
+	err = decodeJSON(r.Body, &user)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -27,32 +31,28 @@ func CreateUser(w http.ResponseWriter, r *http.Request, context routing.Context)
 
 	res := db.Create(&user).Omit("password")
 	if res.Error != nil {
-		http.Error(w, res.Error.Error(), http.StatusInternalServerError)
+		rest.InternalError(w, r, res.Error)
 		return
 	}
 
-	neoUser := neoModels.User{
-		Username: user.Username,
-		UserID:   int64(user.ID),
-	}
+	neoUser := neoModels.ToNeoModel(user)
 
-	err = neoUser.Create(&neoUser, neo.CreateOptions{})
+	err = neoUser.Create(r.Context(), &neoUser, neo.CreateOptions{})
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		rest.InternalError(w, r, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(neoUser)
-	
-	
+	w.Header().Set("Location", routing.BuildPath("/api/user/:id", map[string]string{"id": strconv.Itoa(user.ID)}))
+	rest.Respond(w, r, http.StatusCreated, neoUser)
+
 }
 
 func GetUser(w http.ResponseWriter, r *http.Request, context routing.Context) {
-	db, err := postgres.Connect()
+	db, err := postgres.Shared()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		rest.InternalError(w, r, err)
 		return
 	}
 
@@ -71,16 +71,28 @@ func GetUser(w http.ResponseWriter, r *http.Request, context routing.Context) {
 	res := db.First(&user, id).Omit("password")
 
 	if res.Error != nil {
-		http.Error(w, res.Error.Error(), http.StatusNotFound)
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+		rest.InternalError(w, r, res.Error)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(user)
+	rest.Respond(w, r, http.StatusOK, user)
 
 }
 
+// totalCountHeader carries the total number of items of a collection response.
+const totalCountHeader = "X-Total-Count"
+
 func GetUserWorlds(w http.ResponseWriter, r *http.Request, context routing.Context) {
+	claims, err := auth.ClaimsFromRequest(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
 	id := context.GetPathParam("id")
 	if id == "" {
 		http.Error(w, "Missing user ID", http.StatusBadRequest)
@@ -94,13 +106,21 @@ func GetUserWorlds(w http.ResponseWriter, r *http.Request, context routing.Conte
 		return
 	}
 
-	var user neoModels.User
-	err = user.Find(&user, "userID", parsedID).Populate(neo.PopulateOptions{
+	if !auth.IsAdmin(claims) && !auth.IsUser(claims, parsedID) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	user, err := neo.FindOne[neoModels.User](r.Context(), "userID", parsedID, neo.PopulateOptions{
 		Depth: 1,
 	})
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if errors.Is(err, neo.ErrNotFound) {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+		rest.InternalError(w, r, err)
 		return
 	}
 
@@ -108,19 +128,26 @@ func GetUserWorlds(w http.ResponseWriter, r *http.Request, context routing.Conte
 		http.Error(w, "No worlds found for this user", http.StatusNotFound)
 		return
 	}
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(user.Worlds)
+
+	count, err := user.CountRelated(r.Context(), user.ID, neo.RelOwns, "->", "World")
+	if err != nil {
+		rest.InternalError(w, r, err)
+		return
+	}
+
+	w.Header().Set(totalCountHeader, strconv.FormatInt(count, 10))
+	rest.Respond(w, r, http.StatusOK, user.Worlds)
 }
 
 func Login(w http.ResponseWriter, r *http.Request, context routing.Context) {
 	var user models.User
-	db, err := postgres.Connect()
+	db, err := postgres.Shared()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		rest.InternalError(w, r, err)
 		return
 	}
 
-	err = json.NewDecoder(r.Body).Decode(&user)
+	err = decodeJSON(r.Body, &user)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -139,8 +166,44 @@ func Login(w http.ResponseWriter, r *http.Request, context routing.Context) {
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(dbUser)
+	token, err := auth.CreateJWT(identityClaims(dbUser), auth.TokenOptions{Subject: dbUser.Username})
+	if err != nil {
+		rest.InternalError(w, r, err)
+		return
+	}
+	refreshToken, err := auth.CreateRefreshToken(dbUser.Username)
+	if err != nil {
+		rest.InternalError(w, r, err)
+		return
+	}
+
+	rest.Respond(w, r, http.StatusOK, loggedInUser{User: dbUser, Token: token, RefreshToken: refreshToken})
+}
+
+// identityClaims are the application claims of the access tokens issued to a user by Login and Refresh.
+func identityClaims(user models.User) auth.Claims {
+	return auth.Claims{Username: user.Username, UserID: int64(user.ID)}
+}
+
+// userIdentity reads the current claims of a user, so the access tokens issued by Refresh follow changes to the user.
+func userIdentity(username string) (auth.Claims, error) {
+	db, err := postgres.Shared()
+	if err != nil {
+		return auth.Claims{}, err
+	}
+
+	var dbUser models.User
+	if err := db.Where("username = ?", username).First(&dbUser).Error; err != nil {
+		return auth.Claims{}, err
+	}
+	return identityClaims(dbUser), nil
+}
+
+// loggedInUser is the Login response: the user along with its access and refresh tokens.
+type loggedInUser struct {
+	models.User
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
 }
 
 func GetNeoUser(w http.ResponseWriter, r *http.Request, context routing.Context) {
@@ -158,15 +221,39 @@ func GetNeoUser(w http.ResponseWriter, r *http.Request, context routing.Context)
 	}
 
 	var user neoModels.User
-	err = user.Find(&user, "userID", id).Populate(neo.PopulateOptions{
+	if context.GetQueryParam("expand") == "ids" {
+		ids, err := user.Find(r.Context(), &user, "userID", id).PopulateIDs()
+		if err != nil {
+			if errors.Is(err, neo.ErrNotFound) {
+				http.Error(w, "User not found", http.StatusNotFound)
+				return
+			}
+			rest.InternalError(w, r, err)
+			return
+		}
+
+		response, err := withRelationshipIDs(user, ids)
+		if err != nil {
+			rest.InternalError(w, r, err)
+			return
+		}
+
+		rest.Respond(w, r, http.StatusOK, response)
+		return
+	}
+
+	found, err := neo.FindOne[neoModels.User](r.Context(), "userID", id, neo.PopulateOptions{
 		Depth: 1,
 	})
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if errors.Is(err, neo.ErrNotFound) {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+		rest.InternalError(w, r, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(user)
+	rest.Respond(w, r, http.StatusOK, found)
 }
@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"api/internal/app/neo4j/neotest"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// ownedWorld is the related node entry of a world owned by the populated user.
+func ownedWorld(id string, name string) map[string]any {
+	return map[string]any{
+		"node":     neotest.Node(id, []string{"World"}, map[string]any{"name": name}),
+		"rel":      map[string]any{},
+		"field":    "worlds",
+		"children": []any{},
+	}
+}
+
+func TestGetUserWorlds(t *testing.T) {
+	user := neotest.Node("4:db:10", []string{"User"}, map[string]any{"username": "alice", "userID": int64(1)})
+	responses := map[string]neotest.Response{
+		"relatedNodes": {Records: []*neo4j.Record{neotest.Record("n", user, "relatedNodes", []any{
+			ownedWorld("4:db:1", "Atlantis"),
+			ownedWorld("4:db:2", "Lemuria"),
+		})}},
+		"AS count": {Records: []*neo4j.Record{neotest.Record("count", int64(2))}},
+	}
+
+	tests := []struct {
+		name       string
+		claims     jwt.MapClaims
+		responses  map[string]neotest.Response
+		wantStatus int
+		wantWorlds int
+	}{
+		{name: "owner", claims: userClaims("alice", 1), responses: responses, wantStatus: http.StatusOK, wantWorlds: 2},
+		{name: "admin", claims: userClaims("root", 3, "admin"), responses: responses, wantStatus: http.StatusOK, wantWorlds: 2},
+		{name: "other user", claims: userClaims("bob", 2), responses: responses, wantStatus: http.StatusForbidden},
+		{name: "anonymous", responses: responses, wantStatus: http.StatusUnauthorized},
+		{name: "unknown user", claims: userClaims("root", 3, "admin"), wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, respondTo(tt.responses))
+
+			w := serve(GetUserWorlds, "GET", "/api/user/:id/worlds", "/api/user/1/worlds", "", tt.claims)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("GetUserWorlds() status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus == http.StatusForbidden && len(driver.Queries()) != 0 {
+				t.Errorf("GetUserWorlds() ran %d queries before refusing, want none", len(driver.Queries()))
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var worlds []map[string]any
+			if err := json.NewDecoder(w.Body).Decode(&worlds); err != nil {
+				t.Fatalf("GetUserWorlds() body: %v", err)
+			}
+			if len(worlds) != tt.wantWorlds {
+				t.Errorf("GetUserWorlds() returned %d worlds, want %d", len(worlds), tt.wantWorlds)
+			}
+			if total := w.Header().Get(totalCountHeader); total != "2" {
+				t.Errorf("GetUserWorlds() %s = %q, want 2", totalCountHeader, total)
+			}
+		})
+	}
+}
@@ -1,14 +1,26 @@
 package controller
 
 import (
+	"api/internal/app/auth"
 	neoModels "api/internal/app/models/neo"
 	neo "api/internal/app/neo4j"
+	"api/internal/app/rest"
 	"api/internal/app/routing"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
+// createdWorld is the CreateWorld response: the world and the elementId of the user now owning it.
+type createdWorld struct {
+	neoModels.World
+	OwnerID string `json:"ownerId"`
+}
+
 func CreateWorld(w http.ResponseWriter, r *http.Request, rctx routing.Context) {
 	var world neoModels.World
 
@@ -18,7 +30,7 @@ func CreateWorld(w http.ResponseWriter, r *http.Request, rctx routing.Context) {
 		return
 	}
 
-	err := json.NewDecoder(r.Body).Decode(&world)
+	err := decodeJSON(r.Body, &world)
 
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -32,21 +44,27 @@ func CreateWorld(w http.ResponseWriter, r *http.Request, rctx routing.Context) {
 		return
 	}
 
-	err = world.Create(&world, neo.CreateOptions{
-		Rel:          "OWNS",
+	world.CreatedAt = time.Now().UTC()
+	ownerID, err := world.CreateRelated(r.Context(), &world, neo.CreateOptions{
+		Rel:          neo.RelOwns,
 		RelDirection: "<-",
 		Label:        "User",
 		Field:        "userID",
 		Value:        userIDInt,
+		MustExist:    true,
 	})
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if errors.Is(err, neo.ErrRelatedNotFound) {
+			rest.RespondUnprocessable(w, []string{"user not found"})
+			return
+		}
+		rest.InternalError(w, r, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(world)
+	w.Header().Set("Location", routing.BuildPath("/api/world/:id", map[string]string{"id": world.ID}))
+	rest.Respond(w, r, http.StatusCreated, createdWorld{World: world, OwnerID: ownerID})
 
 }
 
@@ -58,53 +76,126 @@ func GetWorld(w http.ResponseWriter, r *http.Request, rctx routing.Context) {
 	}
 
 	var world neoModels.World
-	err := world.Find(&world, "elementID", id).Populate(neo.PopulateOptions{
-		Depth: 0,
+	if rctx.GetQueryParam("expand") == "ids" {
+		ids, err := world.Find(r.Context(), &world, "elementID", id).PopulateIDs()
+		if err != nil {
+			if errors.Is(err, neo.ErrNotFound) {
+				http.Error(w, "World not found", http.StatusNotFound)
+				return
+			}
+			rest.InternalError(w, r, err)
+			return
+		}
+
+		response, err := withRelationshipIDs(world, ids)
+		if err != nil {
+			rest.InternalError(w, r, err)
+			return
+		}
+
+		rest.Respond(w, r, http.StatusOK, response)
+		return
+	}
+
+	pages, err := relationshipPages(rctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Without a depth, the world is populated as deep as neo.MaxPopulateDepth allows, which also clamps deeper
+	// requests. Depth 0 returns the world alone.
+	depth := neo.Unbounded
+	if value := rctx.GetQueryParam("depth"); value != "" {
+		depth, err = strconv.Atoi(value)
+		if err != nil || depth < 0 {
+			http.Error(w, "invalid depth", http.StatusBadRequest)
+			return
+		}
+	}
+
+	found, err := neo.FindOne[neoModels.World](r.Context(), "elementID", id, neo.PopulateOptions{
+		Depth: depth,
+		Pages: pages,
 	})
 
 	if err != nil {
-		if err.Error() == "not found" {
+		if errors.Is(err, neo.ErrInvalidPage) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, neo.ErrNotFound) {
 			http.Error(w, "World not found", http.StatusNotFound)
 			return
 		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		rest.InternalError(w, r, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(world)
+	rest.Respond(w, r, http.StatusOK, found)
+}
+
+// worldEditableFields maps the JSON keys PutWorld accepts to their node tags.
+var worldEditableFields = map[string]string{
+	"name":        "name",
+	"type":        "type",
+	"description": "description",
 }
 
+/*
+PutWorld updates only the fields present in the request body, so omitted fields keep their values.
+*/
 func PutWorld(w http.ResponseWriter, r *http.Request, rctx routing.Context) {
-	var world neoModels.World
 	worldID := rctx.GetPathParam("id")
 
 	if worldID == "" {
 		http.Error(w, "missing id", http.StatusBadRequest)
 		return
 	}
-	err := json.NewDecoder(r.Body).Decode(&world)
 
-	if err != nil {
+	var provided map[string]interface{}
+	if err := decodeJSON(r.Body, &provided); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	world.ID = worldID
+	fields := make(map[string]interface{})
+	for key, value := range provided {
+		nodeTag, ok := worldEditableFields[key]
+		if !ok {
+			continue
+		}
+		str, ok := value.(string)
+		if !ok {
+			http.Error(w, fmt.Sprintf("%s must be a string", key), http.StatusBadRequest)
+			return
+		}
+		fields[nodeTag] = str
+	}
+	if len(fields) == 0 {
+		http.Error(w, "no updatable fields provided", http.StatusBadRequest)
+		return
+	}
 
-	err = world.Update(&world, neo.CreateOptions{})
+	var world neoModels.World
+	err := world.UpdateFields(r.Context(), "elementID", worldID, fields)
 
 	if err != nil {
-		if err.Error() == "not found" {
+		if errors.Is(err, neo.ErrNotFound) {
 			http.Error(w, "World not found", http.StatusNotFound)
 			return
 		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		rest.InternalError(w, r, err)
+		return
+	}
+
+	updated, err := neo.FindOne[neoModels.World](r.Context(), "elementID", worldID, neo.PopulateOptions{})
+	if err != nil {
+		rest.InternalError(w, r, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(world)
+	rest.Respond(w, r, http.StatusOK, updated)
 }
 
 func DeleteWorld(w http.ResponseWriter, r *http.Request, rctx routing.Context) {
@@ -115,19 +206,194 @@ func DeleteWorld(w http.ResponseWriter, r *http.Request, rctx routing.Context) {
 	}
 
 	var world neoModels.World
-	err := world.Delete(&world, "elementID", id, neo.DeleteOptions{
+	err := world.Delete(r.Context(), &world, "elementID", id, neo.DeleteOptions{
 		Detach: true,
 	})
 
 	if err != nil {
-		if err.Error() == "not found" {
+		if errors.Is(err, neo.ErrNotFound) {
 			http.Error(w, "World not found", http.StatusNotFound)
 			return
 		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		rest.InternalError(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 	json.NewEncoder(w).Encode(nil)
 }
+
+var errForbidden = errors.New("forbidden")
+
+func TransferWorld(w http.ResponseWriter, r *http.Request, rctx routing.Context) {
+	id := rctx.GetPathParam("id")
+	if id == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+
+	claims, err := auth.ClaimsFromRequest(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var body struct {
+		NewUserID int64 `json:"newUserId"`
+	}
+	err = decodeJSON(r.Body, &body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if body.NewUserID == 0 {
+		http.Error(w, "missing newUserId", http.StatusBadRequest)
+		return
+	}
+
+	var world neoModels.World
+	err = world.Transfer(r.Context(), id, neo.TransferOptions{
+		Rel:   neo.RelOwns,
+		Label: "User",
+		Field: "userID",
+		Value: body.NewUserID,
+		Authorize: func(current map[string]interface{}) error {
+			if auth.IsAdmin(claims) {
+				return nil
+			}
+			// The owner is identified by userID; a missing userID on either side never matches.
+			if ownerID, ok := current["userID"].(int64); ok && auth.IsUser(claims, ownerID) {
+				return nil
+			}
+			return errForbidden
+		},
+	})
+
+	if err != nil {
+		if errors.Is(err, errForbidden) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, neo.ErrNotFound) {
+			http.Error(w, "World or user not found", http.StatusNotFound)
+			return
+		}
+		rest.InternalError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+const maxImportSize = 5 << 20
+
+var importLimits = neo.TreeLimits{
+	MaxDepth: 4,
+	MaxNodes: 10000,
+}
+
+func ImportWorld(w http.ResponseWriter, r *http.Request, rctx routing.Context) {
+	claims, err := auth.ClaimsFromRequest(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userID := rctx.GetPathParam("id")
+	if userID == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+
+	userIDInt, err := strconv.ParseInt(userID, 10, 64)
+
+	if err != nil {
+		http.Error(w, "invalid userID", http.StatusBadRequest)
+		return
+	}
+
+	if !auth.IsAdmin(claims) && !auth.IsUser(claims, userIDInt) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	var world neoModels.World
+	err = decodeImport(w, r, &world)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if problems := neo.ValidateTree(&world, importLimits); len(problems) > 0 {
+		rest.RespondUnprocessable(w, treeErrors(problems))
+		return
+	}
+
+	world.CreatedAt = time.Now().UTC()
+	err = world.CreateTree(r.Context(), &world, neo.CreateOptions{
+		Rel:          neo.RelOwns,
+		RelDirection: "<-",
+		Label:        "User",
+		Field:        "userID",
+		Value:        userIDInt,
+		MustExist:    true,
+	})
+
+	if err != nil {
+		if errors.Is(err, neo.ErrRelatedNotFound) {
+			rest.RespondUnprocessable(w, []string{"user not found"})
+			return
+		}
+		rest.InternalError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", routing.BuildPath("/api/world/:id", map[string]string{"id": world.ID}))
+	rest.Respond(w, r, http.StatusCreated, map[string]string{"id": world.ID})
+}
+
+// ValidateWorld runs the import validation on a world tree without writing anything.
+func ValidateWorld(w http.ResponseWriter, r *http.Request, rctx routing.Context) {
+	var world neoModels.World
+	err := decodeImport(w, r, &world)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if problems := neo.ValidateTree(&world, importLimits); len(problems) > 0 {
+		rest.RespondUnprocessable(w, treeErrors(problems))
+		return
+	}
+
+	rest.Respond(w, r, http.StatusOK, map[string]bool{"valid": true})
+}
+
+// treeErrors describes each problem of a tree by its path and message, ie: "$.continents[1]: missing name".
+func treeErrors(problems []neo.TreeProblem) []string {
+	messages := make([]string, len(problems))
+	for i, problem := range problems {
+		messages[i] = problem.Path + ": " + problem.Message
+	}
+	return messages
+}
+
+// decodeImport decodes a world tree from a JSON body or from the "file" part of a multipart form.
+func decodeImport(w http.ResponseWriter, r *http.Request, world *neoModels.World) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		return decodeJSON(r.Body, world)
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return decodeJSON(file, world)
+}
@@ -0,0 +1,547 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"api/internal/app/auth"
+	"api/internal/app/neo4j/neotest"
+	"api/internal/app/rest"
+	"api/internal/app/routing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+func TestTransferWorld(t *testing.T) {
+	owner := neotest.Node("4:db:10", []string{"User"}, map[string]any{"username": "alice", "userID": int64(1)})
+	world := neotest.Node("4:db:1", []string{"World"}, map[string]any{"name": "Atlantis"})
+	ownerWithoutID := neotest.Node("4:db:11", []string{"User"}, map[string]any{})
+
+	tests := []struct {
+		name        string
+		claims      jwt.MapClaims
+		body        string
+		responses   map[string]neotest.Response
+		wantStatus  int
+		wantWritten bool
+	}{
+		{
+			name:   "owner transfers",
+			claims: userClaims("alice", 1),
+			body:   `{"newUserId": 2}`,
+			responses: map[string]neotest.Response{
+				"RETURN o": {Records: []*neo4j.Record{neotest.Record("o", owner)}},
+				"DELETE e": {Records: []*neo4j.Record{neotest.Record("n", world)}},
+			},
+			wantStatus:  http.StatusNoContent,
+			wantWritten: true,
+		},
+		{
+			name:   "admin transfers",
+			claims: userClaims("root", 3, "admin"),
+			body:   `{"newUserId": 2}`,
+			responses: map[string]neotest.Response{
+				"RETURN o": {Records: []*neo4j.Record{neotest.Record("o", owner)}},
+				"DELETE e": {Records: []*neo4j.Record{neotest.Record("n", world)}},
+			},
+			wantStatus:  http.StatusNoContent,
+			wantWritten: true,
+		},
+		{
+			name:   "other user forbidden",
+			claims: userClaims("bob", 2),
+			body:   `{"newUserId": 2}`,
+			responses: map[string]neotest.Response{
+				"RETURN o": {Records: []*neo4j.Record{neotest.Record("o", owner)}},
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:   "same username, other user id",
+			claims: userClaims("alice", 2),
+			body:   `{"newUserId": 2}`,
+			responses: map[string]neotest.Response{
+				"RETURN o": {Records: []*neo4j.Record{neotest.Record("o", owner)}},
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:   "token without identity, owner without properties",
+			claims: jwt.MapClaims{"roles": []any{}},
+			body:   `{"newUserId": 2}`,
+			responses: map[string]neotest.Response{
+				"RETURN o": {Records: []*neo4j.Record{neotest.Record("o", ownerWithoutID)}},
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:   "unknown new owner",
+			claims: userClaims("alice", 1),
+			body:   `{"newUserId": 9}`,
+			responses: map[string]neotest.Response{
+				"RETURN o": {Records: []*neo4j.Record{neotest.Record("o", owner)}},
+			},
+			wantStatus:  http.StatusNotFound,
+			wantWritten: true,
+		},
+		{
+			name:       "unknown world",
+			claims:     userClaims("alice", 1),
+			body:       `{"newUserId": 2}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "missing new owner",
+			claims:     userClaims("alice", 1),
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "anonymous",
+			body:       `{"newUserId": 2}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, respondTo(tt.responses))
+
+			w := serve(TransferWorld, "POST", "/api/world/:id/transfer", "/api/world/4:db:1/transfer", tt.body, tt.claims)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("TransferWorld() status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+
+			var written bool
+			for _, query := range driver.Queries() {
+				if strings.Contains(query.Cypher, "CREATE (s)-[:OWNS]->(n)") {
+					written = true
+				}
+				if query.Params["value"] != "4:db:1" {
+					t.Errorf("TransferWorld() matched world %v, want 4:db:1", query.Params["value"])
+				}
+			}
+			if written != tt.wantWritten {
+				t.Errorf("TransferWorld() ran the transfer = %v, want %v", written, tt.wantWritten)
+			}
+			if driver.Transactions() > 1 {
+				t.Errorf("TransferWorld() ran %d transactions, want a single one", driver.Transactions())
+			}
+		})
+	}
+}
+
+func TestCreateLocation(t *testing.T) {
+	created := neotest.Node("4:db:7", []string{"World"}, map[string]any{"name": "Atlantis"})
+
+	tests := []struct {
+		name         string
+		handler      routing.HTTPHandlerWithContext
+		pattern      string
+		target       string
+		records      []*neo4j.Record
+		wantStatus   int
+		wantLocation string
+	}{
+		{
+			name:         "create world",
+			handler:      CreateWorld,
+			pattern:      "/api/user/:id/world",
+			target:       "/api/user/1/world",
+			records:      []*neo4j.Record{neotest.Record("n", created, "relatedID", "4:db:10")},
+			wantStatus:   http.StatusCreated,
+			wantLocation: "/api/world/4:db:7",
+		},
+		{
+			name:         "import world",
+			handler:      ImportWorld,
+			pattern:      "/api/user/:id/world/import",
+			target:       "/api/user/1/world/import",
+			records:      []*neo4j.Record{neotest.Record("n", created)},
+			wantStatus:   http.StatusCreated,
+			wantLocation: "/api/world/4:db:7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useFakeDriver(t, func(neotest.Query) neotest.Response { return neotest.Response{Records: tt.records} })
+
+			w := serve(tt.handler, "POST", tt.pattern, tt.target, `{"name": "Atlantis"}`, userClaims("alice", 1))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("%s status = %d, want %d: %s", tt.name, w.Code, tt.wantStatus, w.Body)
+			}
+			if location := w.Header().Get("Location"); location != tt.wantLocation {
+				t.Errorf("%s Location = %q, want %q", tt.name, location, tt.wantLocation)
+			}
+		})
+	}
+}
+
+func TestCreateWorldMissingParent(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    routing.HTTPHandlerWithContext
+		pattern    string
+		target     string
+		body       string
+		wantStatus int
+		wantErrors []string
+	}{
+		{
+			name:       "create for unknown user",
+			handler:    CreateWorld,
+			pattern:    "/api/user/:id/world",
+			target:     "/api/user/42/world",
+			body:       `{"name": "Atlantis"}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantErrors: []string{"user not found"},
+		},
+		{
+			name:       "import for unknown user",
+			handler:    ImportWorld,
+			pattern:    "/api/user/:id/world/import",
+			target:     "/api/user/42/world/import",
+			body:       `{"name": "Atlantis"}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantErrors: []string{"user not found"},
+		},
+		{
+			name:       "malformed body",
+			handler:    CreateWorld,
+			pattern:    "/api/user/:id/world",
+			target:     "/api/user/42/world",
+			body:       `{"name": `,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "malformed user id",
+			handler:    CreateWorld,
+			pattern:    "/api/user/:id/world",
+			target:     "/api/user/forty-two/world",
+			body:       `{"name": "Atlantis"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The user is never found: the create returns no rows.
+			useFakeDriver(t, func(neotest.Query) neotest.Response { return neotest.Response{} })
+
+			w := serve(tt.handler, "POST", tt.pattern, tt.target, tt.body, userClaims("root", 3, "admin"))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("%s status = %d, want %d: %s", tt.name, w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusUnprocessableEntity {
+				return
+			}
+
+			var response rest.ErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("%s body: %v", tt.name, err)
+			}
+			if !slices.Equal(response.Errors, tt.wantErrors) {
+				t.Errorf("%s errors = %q, want %q", tt.name, response.Errors, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func TestGetWorldExpand(t *testing.T) {
+	world := neotest.Node("4:db:1", []string{"World"}, map[string]any{"name": "Atlantis"})
+	continent := neotest.Node("4:db:2", []string{"Continent"}, map[string]any{"name": "North"})
+
+	tests := []struct {
+		name       string
+		target     string
+		records    []*neo4j.Record
+		wantStatus int
+		wantFirst  any
+	}{
+		{
+			name:       "ids",
+			target:     "/api/world/4:db:1?expand=ids",
+			records:    []*neo4j.Record{neotest.Record("n", world, "ids0", []any{"4:db:2"}, "ids1", []any{})},
+			wantStatus: http.StatusOK,
+			wantFirst:  "4:db:2",
+		},
+		{
+			name:   "full objects by default",
+			target: "/api/world/4:db:1",
+			records: []*neo4j.Record{neotest.Record("n", world, "relatedNodes", []any{map[string]any{
+				"node": continent, "rel": map[string]any{}, "field": "continents", "children": []any{},
+			}})},
+			wantStatus: http.StatusOK,
+			wantFirst:  map[string]any{"id": "4:db:2", "name": "North"},
+		},
+		{
+			name:       "ids of unknown world",
+			target:     "/api/world/4:db:1?expand=ids",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, func(neotest.Query) neotest.Response { return neotest.Response{Records: tt.records} })
+
+			w := serve(GetWorld, "GET", "/api/world/:id", tt.target, "", nil)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("GetWorld() status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Continents []any `json:"continents"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("GetWorld() body: %v", err)
+			}
+			if len(response.Continents) != 1 || !reflect.DeepEqual(response.Continents[0], tt.wantFirst) {
+				t.Errorf("GetWorld() continents = %v, want [%v]", response.Continents, tt.wantFirst)
+			}
+
+			// The ids query collects elementIds instead of populating the related nodes.
+			query := driver.Queries()[0].Cypher
+			if expandIDs := strings.Contains(tt.target, "expand=ids"); expandIDs != strings.Contains(query, "collect(DISTINCT elementId(r))") {
+				t.Errorf("GetWorld() ran %q", query)
+			}
+		})
+	}
+}
+
+func TestImportWorld(t *testing.T) {
+	const small = `{"name": "Atlantis", "continents": [{"name": "North", "zones": [{"name": "Coast"}]}], "oceans": [{"name": "Deep"}]}`
+
+	tests := []struct {
+		name        string
+		claims      jwt.MapClaims
+		contentType string
+		body        string
+		wantStatus  int
+		wantErrors  []string
+		wantLabels  []string
+	}{
+		{
+			name:       "raw json",
+			claims:     userClaims("alice", 1),
+			body:       small,
+			wantStatus: http.StatusCreated,
+			wantLabels: []string{"World", "Continent", "Zone", "Ocean"},
+		},
+		{
+			name:       "admin imports for another user",
+			claims:     userClaims("root", 3, "admin"),
+			body:       small,
+			wantStatus: http.StatusCreated,
+			wantLabels: []string{"World", "Continent", "Zone", "Ocean"},
+		},
+		{
+			name:       "anonymous",
+			body:       small,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "other user",
+			claims:     userClaims("bob", 2),
+			body:       small,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:        "uploaded file",
+			claims:      userClaims("alice", 1),
+			contentType: "multipart/form-data; boundary=import",
+			body: "--import\r\nContent-Disposition: form-data; name=\"file\"; filename=\"world.json\"\r\n" +
+				"Content-Type: application/json\r\n\r\n" + small + "\r\n--import--\r\n",
+			wantStatus: http.StatusCreated,
+			wantLabels: []string{"World", "Continent", "Zone", "Ocean"},
+		},
+		{
+			name:       "malformed json",
+			claims:     userClaims("alice", 1),
+			body:       `{"name": `,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			// A world exported by GetWorld carries the ids of its nodes, which the import replaces.
+			name:       "exported tree",
+			claims:     userClaims("alice", 1),
+			body:       `{"id": "4:db:8", "name": "Atlantis", "continents": [{"id": "4:db:9", "name": "North", "zones": [{"id": "4:db:10", "name": "Coast"}]}]}`,
+			wantStatus: http.StatusCreated,
+			wantLabels: []string{"World", "Continent", "Zone"},
+		},
+		{
+			name:       "too many nodes",
+			claims:     userClaims("alice", 1),
+			body:       `{"name": "Atlantis", "oceans": [` + strings.Repeat(`{"name": "Deep"}, `, importLimits.MaxNodes) + `{"name": "Deep"}]}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantErrors: []string{fmt.Sprintf("$: tree has %d nodes, the maximum is %d", importLimits.MaxNodes+2, importLimits.MaxNodes)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created int
+			driver := useFakeDriver(t, func(query neotest.Query) neotest.Response {
+				created++
+				node := neotest.Node(fmt.Sprintf("4:db:%d", created), nil, nil)
+				return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", node)}}
+			})
+
+			router := routing.NewRouter()
+			router.Handle("POST", "/api/user/:id/world/import", ImportWorld)
+			r := httptest.NewRequest("POST", "/api/user/1/world/import", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			if tt.claims != nil {
+				r = r.WithContext(auth.WithClaims(r.Context(), tt.claims))
+			}
+			w := httptest.NewRecorder()
+			router.NewServer("0", routing.ServeOptions{}).Handler.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("ImportWorld() status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusCreated {
+				if len(driver.Queries()) != 0 {
+					t.Errorf("ImportWorld() ran %d queries for a rejected import, want none", len(driver.Queries()))
+				}
+				if tt.wantErrors != nil {
+					var response rest.ErrorResponse
+					if err := json.NewDecoder(w.Body).Decode(&response); err != nil || !slices.Equal(response.Errors, tt.wantErrors) {
+						t.Errorf("ImportWorld() errors = %q (%v), want %q", response.Errors, err, tt.wantErrors)
+					}
+				}
+				return
+			}
+
+			var response map[string]string
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil || response["id"] != "4:db:1" {
+				t.Errorf("ImportWorld() body = %v (%v), want the root world id 4:db:1", response, err)
+			}
+
+			var labels []string
+			for _, query := range driver.Queries() {
+				_, label, _ := strings.Cut(query.Cypher, "CREATE (n:")
+				label, _, _ = strings.Cut(label, ")")
+				labels = append(labels, label)
+			}
+			if !slices.Equal(labels, tt.wantLabels) {
+				t.Errorf("ImportWorld() created %v, want %v", labels, tt.wantLabels)
+			}
+			if driver.Transactions() != 1 {
+				t.Errorf("ImportWorld() ran %d transactions, want a single one", driver.Transactions())
+			}
+		})
+	}
+}
+
+func TestValidateWorld(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantErrors []string
+	}{
+		{
+			name:       "valid tree",
+			body:       `{"name": "Atlantis", "continents": [{"name": "North", "zones": [{"name": "Coast", "cities": [{"name": "Port Royal"}]}]}]}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "exported tree with ids",
+			body:       `{"id": "4:db:1", "name": "Atlantis", "continents": [{"name": "North"}, {"id": "4:db:2", "zones": [{"id": "4:db:3"}]}]}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "too many nodes",
+			body:       `{"name": "Atlantis", "oceans": [` + strings.Repeat(`{}, `, importLimits.MaxNodes) + `{}]}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantErrors: []string{fmt.Sprintf("$: tree has %d nodes, the maximum is %d", importLimits.MaxNodes+2, importLimits.MaxNodes)},
+		},
+		{
+			name:       "malformed body",
+			body:       `{"name": "Atlantis", "continents": {}}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, nil)
+
+			w := serve(ValidateWorld, "POST", "/api/world/validate", "/api/world/validate", tt.body, nil)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("ValidateWorld() status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if queries := driver.Queries(); len(queries) != 0 {
+				t.Errorf("ValidateWorld() ran %d queries, want none", len(queries))
+			}
+
+			switch {
+			case tt.wantStatus == http.StatusOK:
+				var response map[string]bool
+				if err := json.NewDecoder(w.Body).Decode(&response); err != nil || !response["valid"] {
+					t.Errorf("ValidateWorld() body = %v, %v, want valid", response, err)
+				}
+			case tt.wantErrors != nil:
+				var response rest.ErrorResponse
+				if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+					t.Fatalf("ValidateWorld() body: %v", err)
+				}
+				if !slices.Equal(response.Errors, tt.wantErrors) {
+					t.Errorf("ValidateWorld() errors = %q, want %q", response.Errors, tt.wantErrors)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateWorldOwner(t *testing.T) {
+	created := neotest.Node("4:db:7", []string{"World"}, map[string]any{"name": "Atlantis"})
+
+	tests := []struct {
+		name       string
+		target     string
+		ownerID    string
+		wantUserID int64
+	}{
+		{name: "first user", target: "/api/user/1/world", ownerID: "4:db:10", wantUserID: 1},
+		{name: "other user", target: "/api/user/42/world", ownerID: "4:db:11", wantUserID: 42},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, func(neotest.Query) neotest.Response {
+				return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", created, "relatedID", tt.ownerID)}}
+			})
+
+			w := serve(CreateWorld, "POST", "/api/user/:id/world", tt.target, `{"name": "Atlantis"}`, nil)
+			if w.Code != http.StatusCreated {
+				t.Fatalf("CreateWorld() status = %d: %s", w.Code, w.Body)
+			}
+
+			var response map[string]any
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("CreateWorld() body: %v", err)
+			}
+			if response["id"] != "4:db:7" || response["name"] != "Atlantis" || response["ownerId"] != tt.ownerID {
+				t.Errorf("CreateWorld() = %v, want world 4:db:7 owned by %s", response, tt.ownerID)
+			}
+			if userID := driver.Queries()[0].Params["relatedValue"]; userID != tt.wantUserID {
+				t.Errorf("CreateWorld() related the world to userID %v, want %d", userID, tt.wantUserID)
+			}
+		})
+	}
+}
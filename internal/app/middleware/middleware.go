@@ -1,15 +1,258 @@
 package middleware
 
 import (
+	"api/internal/app/auth"
+	"api/internal/app/rest"
+	"fmt"
+	"log"
 	"net/http"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
-func Cors(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+/*
+CorsOptions is the CORS policy applied by NewCors.
+  - @property AllowOrigins: The origins allowed to call the API, ie: https://app.example.com, or * for any origin.
+    The origin of an allowed request is echoed in Access-Control-Allow-Origin, other requests get no CORS headers.
+  - @property AllowMethods: The methods listed in Access-Control-Allow-Methods.
+  - @property AllowHeaders: The request headers listed in Access-Control-Allow-Headers.
+  - @property ExposeHeaders: The response headers listed in Access-Control-Expose-Headers.
+  - @property AllowCredentials: Sends Access-Control-Allow-Credentials: true. It cannot be combined with *:
+    credentials must only be shared with the origins listed.
+  - @property MaxAge: How long browsers may cache a preflight response, sent in Access-Control-Max-Age when set.
+*/
+type CorsOptions struct {
+	AllowOrigins     []string
+	AllowMethods     []string
+	AllowHeaders     []string
+	ExposeHeaders    []string
+	AllowCredentials bool
+	MaxAge           time.Duration
 }
 
-func ContentTypeJSON(w http.ResponseWriter, r *http.Request) {
+var defaultCors = NewCors(CorsOptions{
+	AllowOrigins:  []string{"*"},
+	AllowMethods:  []string{"GET", "POST", "PUT", "DELETE"},
+	AllowHeaders:  []string{"Content-Type", "Authorization"},
+	ExposeHeaders: []string{"X-Total-Count"},
+})
+
+// Cors applies the default, permissive CORS policy.
+func Cors(w http.ResponseWriter, r *http.Request) bool {
+	return defaultCors(w, r)
+}
+
+/*
+NewCors returns a middleware applying the given CORS policy.
+It replaces any CORS header set before it, so a policy registered on a route overrides the router-wide one:
+route middleware always runs after the router middleware.
+It panics when AllowOrigins contains * and AllowCredentials is set, which would let any site make
+credentialed requests.
+
+Example usage:
+
+	router.Use(middleware.Cors)
+	router.Handle("POST", "/api/world/:id/transfer", controller.TransferWorld, middleware.NewCors(middleware.CorsOptions{
+		AllowOrigins:     []string{"https://app.example.com"},
+		AllowMethods:     []string{"POST"},
+		AllowHeaders:     []string{"Content-Type", "Authorization"},
+		AllowCredentials: true,
+	}))
+*/
+func NewCors(options CorsOptions) func(http.ResponseWriter, *http.Request) bool {
+	allowMethods := strings.Join(options.AllowMethods, ", ")
+	allowHeaders := strings.Join(options.AllowHeaders, ", ")
+	exposeHeaders := strings.Join(options.ExposeHeaders, ", ")
+	anyOrigin := slices.Contains(options.AllowOrigins, "*")
+	if anyOrigin && options.AllowCredentials {
+		panic("CORS policy cannot allow credentials for any origin: list the allowed origins instead of *")
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) bool {
+		header := w.Header()
+		for key := range header {
+			if strings.HasPrefix(key, "Access-Control-") {
+				header.Del(key)
+			}
+		}
+
+		origin := r.Header.Get("Origin")
+		switch {
+		case anyOrigin:
+			header.Set("Access-Control-Allow-Origin", "*")
+		case origin != "" && slices.Contains(options.AllowOrigins, origin):
+			header.Set("Access-Control-Allow-Origin", origin)
+			header.Add("Vary", "Origin")
+		default:
+			header.Add("Vary", "Origin")
+			return true
+		}
+
+		if allowMethods != "" {
+			header.Set("Access-Control-Allow-Methods", allowMethods)
+		}
+		if allowHeaders != "" {
+			header.Set("Access-Control-Allow-Headers", allowHeaders)
+		}
+		if exposeHeaders != "" {
+			header.Set("Access-Control-Expose-Headers", exposeHeaders)
+		}
+		if options.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+		if options.MaxAge > 0 && r.Method == http.MethodOptions {
+			header.Set("Access-Control-Max-Age", strconv.Itoa(int(options.MaxAge.Seconds())))
+		}
+		return true
+	}
+}
+
+func ContentTypeJSON(w http.ResponseWriter, r *http.Request) bool {
 	w.Header().Set("Content-Type", "application/json")
+	return true
+}
+
+/*
+MaxConcurrent limits the number of requests processed at the same time to n.
+Requests arriving while n requests are in flight are rejected with a 503 Service Unavailable
+and a Retry-After header instead of being queued.
+It wraps an http.Handler, so it is registered with router.Wrap.
+It panics if n is lower than 1, which would reject every request.
+*/
+func MaxConcurrent(n int) func(http.Handler) http.Handler {
+	if n < 1 {
+		panic(fmt.Sprintf("middleware.MaxConcurrent: n must be at least 1, got %d", n))
+	}
+	semaphore := make(chan struct{}, n)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+				next.ServeHTTP(w, r)
+			default:
+				w.Header().Set("Retry-After", "1")
+				http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+			}
+		})
+	}
+}
+
+/*
+RequireAuth rejects requests without a valid "Authorization: Bearer <token>" header with a 401 Unauthorized,
+and stops them before the handler runs.
+The verified claims are stored in the context of the request handed to the handler, where handlers read them
+with auth.ClaimsFromRequest or auth.ClaimsFromContext.
+It wraps an http.Handler, so it is registered with Route.Wrap, or router.Wrap to protect every route.
+
+Example usage:
+
+	router.Handle("GET", "/api/auth/session", controller.GetSession).Wrap(middleware.RequireAuth)
+*/
+func RequireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if !strings.HasPrefix(header, "Bearer ") {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		claims, err := auth.DecodeJWT(strings.TrimPrefix(header, "Bearer "))
+		if err != nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.WithClaims(r.Context(), claims)))
+	})
+}
+
+/*
+RequireRole returns a wrapper rejecting requests whose bearer token carries none of the given roles.
+Requests without a valid token are rejected with a 401 Unauthorized, and those lacking the roles with a 403 Forbidden.
+It can be used on its own, or after RequireAuth which it then reuses the verified claims of.
+The claims are stored in the context of the request handed to the handler, like RequireAuth.
+
+Example usage:
+
+	router.Handle("GET", "/api/admin/orphans", controller.GetOrphans).Wrap(middleware.RequireRole("admin"))
+*/
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := auth.ClaimsFromRequest(r)
+			if err != nil {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			for _, role := range roles {
+				if auth.HasRole(claims, role) {
+					next.ServeHTTP(w, r.WithContext(auth.WithClaims(r.Context(), claims)))
+					return
+				}
+			}
+			http.Error(w, "Forbidden", http.StatusForbidden)
+		})
+	}
+}
+
+/*
+Recover turns a panic in the middleware or the handler into a 500 Internal Server Error with a JSON body,
+logging the panic once with the request id and the stack trace, instead of crashing the server. When the
+response was already started, only the log is written, since its status can no longer be changed.
+It wraps an http.Handler, so it is registered with router.Wrap, last to be closest to the handler.
+
+Example usage:
+
+	router.Wrap(middleware.MaxConcurrent(256))
+	router.Wrap(middleware.Recover)
+*/
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &writeRecorder{ResponseWriter: w}
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// ErrAbortHandler is how a handler aborts its response on purpose.
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			log.Printf("panic: request_id=%q method=%s path=%s err=%q\n%s",
+				r.Header.Get(rest.RequestIDHeader), r.Method, r.URL.Path, fmt.Sprint(recovered), debug.Stack())
+			if !recorder.written {
+				rest.RespondInternalError(w, r)
+			}
+		}()
+
+		next.ServeHTTP(recorder, r)
+	})
+}
+
+// writeRecorder records whether a response was started through it.
+type writeRecorder struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (w *writeRecorder) WriteHeader(status int) {
+	w.written = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *writeRecorder) Write(b []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, ie: to flush it.
+func (w *writeRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
 }
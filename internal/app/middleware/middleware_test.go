@@ -0,0 +1,265 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"api/internal/app/rest"
+	"api/internal/app/routing"
+)
+
+func TestMaxConcurrent(t *testing.T) {
+	for _, n := range []int{1, 3} {
+		t.Run(fmt.Sprintf("n=%d", n), func(t *testing.T) {
+			entered := make(chan struct{})
+			release := make(chan struct{})
+			handler := MaxConcurrent(n)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				entered <- struct{}{}
+				<-release
+			}))
+
+			var wg sync.WaitGroup
+			codes := make([]int, n)
+			for i := 0; i < n; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					w := httptest.NewRecorder()
+					handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+					codes[i] = w.Code
+				}(i)
+				<-entered
+			}
+
+			// n requests are in flight, so the next one is turned away instead of queued.
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+			if w.Code != http.StatusServiceUnavailable {
+				t.Errorf("request %d status = %d, want 503", n+1, w.Code)
+			}
+			if w.Header().Get("Retry-After") == "" {
+				t.Error("rejected request has no Retry-After header")
+			}
+
+			close(release)
+			wg.Wait()
+			for i, code := range codes {
+				if code != http.StatusOK {
+					t.Errorf("in-flight request %d status = %d, want 200", i, code)
+				}
+			}
+
+			// Finished requests free their slot.
+			go func() { <-entered }()
+			w = httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+			if w.Code != http.StatusOK {
+				t.Errorf("request after release status = %d, want 200", w.Code)
+			}
+		})
+	}
+}
+
+func TestMaxConcurrentInvalid(t *testing.T) {
+	for _, n := range []int{0, -1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("MaxConcurrent(%d) did not panic", n)
+				}
+			}()
+			MaxConcurrent(n)
+		}()
+	}
+}
+
+func TestNewCorsCredentials(t *testing.T) {
+	tests := []struct {
+		name      string
+		options   CorsOptions
+		wantPanic bool
+	}{
+		{name: "any origin", options: CorsOptions{AllowOrigins: []string{"*"}}},
+		{name: "listed origins with credentials", options: CorsOptions{AllowOrigins: []string{"https://app.example.com"}, AllowCredentials: true}},
+		{name: "any origin with credentials", options: CorsOptions{AllowOrigins: []string{"*"}, AllowCredentials: true}, wantPanic: true},
+		{name: "any origin among others with credentials", options: CorsOptions{AllowOrigins: []string{"https://app.example.com", "*"}, AllowCredentials: true}, wantPanic: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recovered := recover(); (recovered != nil) != tt.wantPanic {
+					t.Errorf("NewCors() panic = %v, want panic %v", recovered, tt.wantPanic)
+				}
+			}()
+			NewCors(tt.options)
+		})
+	}
+}
+
+func TestRouteCorsPolicy(t *testing.T) {
+	router := routing.NewRouter()
+	router.Use(Cors)
+	ok := func(w http.ResponseWriter, r *http.Request, rctx routing.Context) { w.Write([]byte("ok")) }
+	router.Handle("GET", "/api/world", ok)
+	router.Handle("POST", "/api/world/:id/transfer", ok, NewCors(CorsOptions{
+		AllowOrigins:     []string{"https://app.example.com"},
+		AllowMethods:     []string{"POST"},
+		AllowHeaders:     []string{"Content-Type", "Authorization"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}))
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		origin string
+		// preflight is the Access-Control-Request-Method of an OPTIONS request.
+		preflight string
+		want      map[string]string
+	}{
+		{
+			name:   "global policy",
+			method: "GET",
+			target: "/api/world",
+			origin: "https://other.example.com",
+			want: map[string]string{
+				"Access-Control-Allow-Origin":      "*",
+				"Access-Control-Allow-Methods":     "GET, POST, PUT, DELETE",
+				"Access-Control-Expose-Headers":    "X-Total-Count",
+				"Access-Control-Allow-Credentials": "",
+			},
+		},
+		{
+			name:   "route policy overrides the global one",
+			method: "POST",
+			target: "/api/world/4:db:1/transfer",
+			origin: "https://app.example.com",
+			want: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.example.com",
+				"Access-Control-Allow-Methods":     "POST",
+				"Access-Control-Expose-Headers":    "",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Max-Age":           "",
+			},
+		},
+		{
+			name:   "route policy rejects other origins",
+			method: "POST",
+			target: "/api/world/4:db:1/transfer",
+			origin: "https://other.example.com",
+			want: map[string]string{
+				"Access-Control-Allow-Origin":  "",
+				"Access-Control-Allow-Methods": "",
+				"Vary":                         "Origin",
+			},
+		},
+		{
+			name:      "preflight uses the route policy",
+			method:    "OPTIONS",
+			target:    "/api/world/4:db:1/transfer",
+			origin:    "https://app.example.com",
+			preflight: "POST",
+			want: map[string]string{
+				"Access-Control-Allow-Origin":  "https://app.example.com",
+				"Access-Control-Allow-Methods": "POST",
+				"Access-Control-Max-Age":       "600",
+			},
+		},
+		{
+			name:      "preflight of a route without a policy",
+			method:    "OPTIONS",
+			target:    "/api/world",
+			origin:    "https://other.example.com",
+			preflight: "GET",
+			want: map[string]string{
+				"Access-Control-Allow-Origin":  "*",
+				"Access-Control-Allow-Methods": "GET, POST, PUT, DELETE",
+				"Access-Control-Max-Age":       "",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.target, nil)
+			r.Header.Set("Origin", tt.origin)
+			if tt.preflight != "" {
+				r.Header.Set("Access-Control-Request-Method", tt.preflight)
+			}
+			w := httptest.NewRecorder()
+			router.NewServer("0", routing.ServeOptions{}).Handler.ServeHTTP(w, r)
+
+			for key, want := range tt.want {
+				if got := w.Header().Get(key); got != want {
+					t.Errorf("%s %s %s = %q, want %q", tt.method, tt.target, key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRecover(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantStatus int
+		wantBody   bool
+	}{
+		{
+			name:       "panic before the response",
+			handler:    func(w http.ResponseWriter, r *http.Request) { panic("boom") },
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   true,
+		},
+		{
+			name: "panic after the response started",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+				panic("boom")
+			},
+			wantStatus: http.StatusAccepted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logged bytes.Buffer
+			log.SetOutput(&logged)
+			defer log.SetOutput(os.Stderr)
+
+			r := httptest.NewRequest("GET", "/api/world", nil)
+			r.Header.Set(rest.RequestIDHeader, "req-42")
+			w := httptest.NewRecorder()
+			Recover(tt.handler).ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Recover() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantBody {
+				var response rest.ErrorResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil || response.RequestID != "req-42" {
+					t.Errorf("Recover() body = %s (%v), want the request id", w.Body, err)
+				}
+			}
+
+			// The panic is logged once, with the request id and the stack trace.
+			if count := strings.Count(logged.String(), "boom"); count != 1 {
+				t.Errorf("Recover() logged the panic %d times, want once: %s", count, logged.String())
+			}
+			if !strings.Contains(logged.String(), `request_id="req-42"`) || !strings.Contains(logged.String(), "goroutine") {
+				t.Errorf("Recover() logged %q, want the request id and the stack trace", logged.String())
+			}
+		})
+	}
+}
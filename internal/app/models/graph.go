@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// World is the plain representation of a world node, without the OGM plumbing of neoModels.World,
+// for callers exchanging worlds without touching the graph. Related nodes are not carried.
+type World struct {
+	ID          string    `json:"id,omitempty"`
+	Name        string    `json:"name,omitempty"`
+	Type        string    `json:"type,omitempty"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// City is the plain representation of a city node, see World.
+type City struct {
+	ID          string `json:"id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+	Capital     bool   `json:"capital,omitempty"`
+}
@@ -1,3 +1,6 @@
+// Package models contains the relational models persisted in PostgreSQL through gorm,
+// and plain representations of graph nodes such as World and City.
+// Graph data is persisted through the neoModels package instead.
 package models
 
 import (
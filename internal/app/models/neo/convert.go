@@ -0,0 +1,79 @@
+package neoModels
+
+import "api/internal/app/models"
+
+/*
+ToNeoModel converts a PostgreSQL user into its Neo4j counterpart.
+The PostgreSQL user is the source of truth for credentials, while the Neo4j user only
+mirrors the identity needed to own nodes in the graph, so the password is never copied.
+*/
+func ToNeoModel(user models.User) User {
+	return User{
+		Username: user.Username,
+		UserID:   int64(user.ID),
+	}
+}
+
+/*
+ToDTO converts a Neo4j user back into a PostgreSQL user.
+Only the identity fields are populated; the password is left empty.
+*/
+func (u User) ToDTO() models.User {
+	return models.User{
+		ID:       int(u.UserID),
+		Username: u.Username,
+	}
+}
+
+/*
+WorldToNeoModel converts a plain world into its OGM counterpart, ready to be created or updated.
+Related nodes are not part of the plain world, so the relationship fields are left empty.
+*/
+func WorldToNeoModel(world models.World) World {
+	return World{
+		ID:          world.ID,
+		Name:        world.Name,
+		Type:        world.Type,
+		Description: world.Description,
+		CreatedAt:   world.CreatedAt,
+	}
+}
+
+/*
+ToDTO converts an OGM world into a plain world, dropping its related nodes.
+*/
+func (w World) ToDTO() models.World {
+	return models.World{
+		ID:          w.ID,
+		Name:        w.Name,
+		Type:        w.Type,
+		Description: w.Description,
+		CreatedAt:   w.CreatedAt,
+	}
+}
+
+/*
+CityToNeoModel converts a plain city into its OGM counterpart.
+*/
+func CityToNeoModel(city models.City) City {
+	return City{
+		ID:          city.ID,
+		Name:        city.Name,
+		Type:        city.Type,
+		Description: city.Description,
+		Capital:     city.Capital,
+	}
+}
+
+/*
+ToDTO converts an OGM city into a plain city.
+*/
+func (c City) ToDTO() models.City {
+	return models.City{
+		ID:          c.ID,
+		Name:        c.Name,
+		Type:        c.Type,
+		Description: c.Description,
+		Capital:     c.Capital,
+	}
+}
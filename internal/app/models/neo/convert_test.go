@@ -0,0 +1,98 @@
+package neoModels
+
+import (
+	"api/internal/app/models"
+	"testing"
+	"time"
+)
+
+func TestUserRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		user models.User
+		want models.User
+	}{
+		{
+			name: "identity fields",
+			user: models.User{ID: 42, Username: "jane"},
+			want: models.User{ID: 42, Username: "jane"},
+		},
+		{
+			name: "password is not copied",
+			user: models.User{ID: 7, Username: "john", Password: "secret"},
+			want: models.User{ID: 7, Username: "john"},
+		},
+		{
+			name: "zero value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ToNeoModel(tt.user).ToDTO(); got != tt.want {
+				t.Errorf("ToNeoModel(%+v).ToDTO() = %+v, want %+v", tt.user, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorldRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		world models.World
+	}{
+		{
+			name: "all fields",
+			world: models.World{
+				ID:          "4:abc:1",
+				Name:        "Atlantis",
+				Type:        "fantasy",
+				Description: "A sunken world",
+				CreatedAt:   time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:  "new world",
+			world: models.World{Name: "Lemuria"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WorldToNeoModel(tt.world).ToDTO(); got != tt.world {
+				t.Errorf("WorldToNeoModel(%+v).ToDTO() = %+v", tt.world, got)
+			}
+		})
+	}
+}
+
+func TestCityRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		city models.City
+	}{
+		{
+			name: "all fields",
+			city: models.City{ID: "4:abc:9", Name: "Port Royal", Type: "port", Description: "A pirate haven", Capital: true},
+		},
+		{
+			name: "not a capital",
+			city: models.City{Name: "Tortuga", Type: "port"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CityToNeoModel(tt.city).ToDTO(); got != tt.city {
+				t.Errorf("CityToNeoModel(%+v).ToDTO() = %+v", tt.city, got)
+			}
+		})
+	}
+}
+
+func TestWorldToDTODropsRelatedNodes(t *testing.T) {
+	world := World{Name: "Atlantis", Continents: []*Continent{{Name: "North"}}}
+	if got := WorldToNeoModel(world.ToDTO()); len(got.Continents) != 0 {
+		t.Errorf("WorldToNeoModel(world.ToDTO()).Continents = %v, want none", got.Continents)
+	}
+}
@@ -1,10 +1,18 @@
+// Package neoModels contains the graph models persisted in Neo4j through the neo OGM.
+// Each model embeds neo.NeoBaseModel and must be registered with neo.RegisterModel.
+// Relational data such as credentials lives in the models package instead; use
+// ToNeoModel and ToDTO to move a user between the two representations, and
+// WorldToNeoModel, CityToNeoModel and their ToDTO methods for the plain world and city.
 package neoModels
 
-import neo "api/internal/app/neo4j"
+import (
+	neo "api/internal/app/neo4j"
+	"time"
+)
 
 type User struct {
 	neo.NeoBaseModel[User]
-	Username string   `node:"username" json:"username,omitempty"`
+	Username string   `node:"username,trim" json:"username,omitempty"`
 	UserID   int64    `node:"userID" json:"userID,omitempty"`
 	ID       string   `node:"id" json:"id,omitempty"`
 	Worlds   []*World `rel:"OWNS,->" json:"worlds,omitempty"`
@@ -13,17 +21,20 @@ type User struct {
 type World struct {
 	neo.NeoBaseModel[World]
 	ID          string       `node:"id" json:"id,omitempty"`
-	Name        string       `node:"name" json:"name,omitempty"`
+	Name        string       `node:"name,trim" json:"name,omitempty"`
 	Type        string       `node:"type" json:"type,omitempty"`
 	Description string       `node:"description" json:"description,omitempty"`
+	CreatedAt   time.Time    `node:"createdAt" json:"createdAt"`
 	Continents  []*Continent `rel:"HAS,->" json:"continents,omitempty"`
 	Oceans      []*Ocean     `rel:"HAS,->" json:"oceans,omitempty"`
+
+	Ownership map[string]interface{} `rel:"props" json:"ownership,omitempty"` // properties of the OWNS relationship, when populated from a User
 }
 
 type Continent struct {
 	neo.NeoBaseModel[Continent]
 	ID          string  `node:"id" json:"id,omitempty"`
-	Name        string  `node:"name" json:"name,omitempty"`
+	Name        string  `node:"name,trim" json:"name,omitempty"`
 	Description string  `node:"description" json:"description,omitempty"`
 	Type        string  `node:"type" json:"type,omitempty"`
 	Zones       []*Zone `rel:"HAS,->" json:"zones,omitempty"`
@@ -32,14 +43,14 @@ type Continent struct {
 type Ocean struct {
 	neo.NeoBaseModel[Ocean]
 	ID          string `node:"id" json:"id,omitempty"`
-	Name        string `node:"name" json:"name,omitempty"`
+	Name        string `node:"name,trim" json:"name,omitempty"`
 	Description string `node:"description" json:"description,omitempty"`
 }
 
 type Zone struct {
 	neo.NeoBaseModel[Zone]
 	ID          string      `node:"id" json:"id,omitempty"`
-	Name        string      `node:"name" json:"name,omitempty"`
+	Name        string      `node:"name,trim" json:"name,omitempty"`
 	Type        string      `node:"type" json:"type,omitempty"`
 	Description string      `node:"description" json:"description,omitempty"`
 	Locations   []*Location `rel:"HAS,->" json:"locations,omitempty"`
@@ -50,7 +61,7 @@ type Zone struct {
 type Location struct {
 	neo.NeoBaseModel[Location]
 	ID          string `node:"id" json:"id,omitempty"`
-	Name        string `node:"name" json:"name,omitempty"`
+	Name        string `node:"name,trim" json:"name,omitempty"`
 	Type        string `node:"type" json:"type,omitempty"`
 	Description string `node:"description" json:"description,omitempty"`
 }
@@ -58,8 +69,13 @@ type Location struct {
 type City struct {
 	neo.NeoBaseModel[City]
 	ID          string `node:"id" json:"id,omitempty"`
-	Name        string `node:"name" json:"name,omitempty"`
+	Name        string `node:"name,trim" json:"name,omitempty"`
 	Type        string `node:"type" json:"type,omitempty"`
 	Description string `node:"description" json:"description,omitempty"`
 	Capital     bool   `node:"capital" json:"capital,omitempty"`
 }
+
+// Indexes are the indexes and constraints the queries on these models rely on, checked at startup by neo.VerifySchema.
+var Indexes = []neo.IndexSpec{
+	{Label: "User", Properties: []string{"userID"}, Unique: true},
+}
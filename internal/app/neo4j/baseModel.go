@@ -20,14 +20,14 @@
 //	}
 //
 //	// Create a new user
-//	err := dbUser.Create(user, CreateOptions{})
+//	err := dbUser.Create(ctx, user, CreateOptions{})
 //	if err != nil {
 //		log.Fatal(err)
 //	}
 //	fmt.Println(user)
 //
 //	// Find a user by ID
-//	err := dbUser.Find(user, "id", 1).Populate(PopulateOptions{
+//	err := dbUser.Find(ctx, user, "id", 1).Populate(PopulateOptions{
 //		Limit: 1,
 //		Depth: 1,
 //	})
@@ -41,14 +41,14 @@
 //		ID:   1,
 //		Name: "John Doe Updated",
 //	}
-//	err := dbUser.Update(user, CreateOptions{})
+//	err := dbUser.Update(ctx, user, CreateOptions{})
 //	if err != nil {
 //		log.Fatal(err)
 //	}
 //	fmt.Println(user)
 //
 //	// Delete a user
-//	err := dbUser.Delete(user, "id", 1, DeleteOptions{
+//	err := dbUser.Delete(ctx, user, "id", 1, DeleteOptions{
 //		Detach: true,
 //	})
 //	if err != nil {
@@ -57,7 +57,7 @@
 //	fmt.Println("User deleted")
 //
 //	// You can establish relationships as well by supply a CreateOptions struct
-//	err := dbUser.Create(user, CreateOptions{
+//	err := dbUser.Create(ctx, user, CreateOptions{
 //		Field:        "id",
 //		Value:        123,
 //		Label:        "Book",
@@ -80,6 +80,7 @@ package neo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -109,7 +110,7 @@ Example:
 	}
 
 	dbUser := &User{}
-	err := dbUser.Find(user, FindOptions{
+	err := dbUser.Find(ctx, user, FindOptions{
 	Field}).Populate(PopulateOptions{
 			Limit: 1,
 			Depth:1})
@@ -119,7 +120,8 @@ Example:
 	fmt.Println(user)
 */
 type NeoBaseModel[T any] struct {
-	Label  string `json:"-"`
+	Label  string   `json:"-"`
+	Labels []string `json:"labels,omitempty"` // the node's Neo4j labels, filled when PopulateOptions.IncludeLabels is set
 	driver neo4j.DriverWithContext
 }
 
@@ -139,49 +141,81 @@ Example:
 	}
 
 	// Create a new node
-	err := dbUser.Create(user, options)
+	err := dbUser.Create(ctx, user, options)
 	if err != nil {
 		log.Fatal(err)
 	}
 */
 type CreateOptions struct {
-	Field        string      // Field name you want to target ie: id
-	Value        interface{} // Value you want to target ie: 123
-	Label        string      // Label of node you want to establish a relationship with ie: World
-	Rel          string      // Relationship type you want to establish ie: OWNS
-	RelDirection string      // Relationship direction you want to establish ie: ->
+	Field        string                 // Field name you want to target ie: name, or id (alias elementID) to match the node by its elementId
+	Value        interface{}            // Value you want to target ie: 123
+	Label        string                 // Label of node you want to establish a relationship with ie: World
+	Rel          string                 // Relationship type you want to establish ie: OWNS
+	RelDirection string                 // Relationship direction you want to establish ie: ->
+	MustExist    bool                   // Match the related node instead of merging it; the create fails with ErrRelatedNotFound when it is missing
+	RelProps     map[string]interface{} // Properties set on the relationship ie: {"since": 2020}, read back through rel:"props" fields
+}
+
+// describesRelated reports whether the options target a node to relate the created node to.
+func (o CreateOptions) describesRelated() bool {
+	return o.Field != "" && o.Value != nil && o.Label != ""
+}
+
+// validateField validates the optional field a query matches on.
+func validateField(field string) error {
+	if field == "" {
+		return nil
+	}
+	return validateIdentifiers(field)
+}
+
+// ErrNotFound is returned when the node an operation targets does not exist.
+var ErrNotFound = errors.New("not found")
+
+// ErrRelatedNotFound is returned when creating a node related to a node that does not exist,
+// see CreateOptions.MustExist. Nothing is created in that case.
+var ErrRelatedNotFound = errors.New("related node not found")
+
+/*
+isElementIDField reports whether a field name targets the node's elementId rather than a property.
+The ID field of a model always holds the elementId, so both "id" and "elementID" match on elementId(n).
+
+Migration note: the deprecated numeric id() is never used. Clients holding numeric ids from id()
+must refetch the nodes to obtain their elementIds, which are opaque strings such as "4:2f1c...:12".
+*/
+func isElementIDField(field string) bool {
+	return field == "id" || field == "elementID"
 }
 
 type DeleteOptions struct {
-	Detach bool // Whether to detach the node from relationships before deletion
+	Detach     bool // Whether to detach the node from relationships before deletion
+	SoftDelete bool // Flag the node as deleted instead of deleting it, see Restore; Detach is ignored
 }
 
 func (b *NeoBaseModel[T]) initDriver() error {
-	if b.Label == "" {
-		b.Label = reflect.TypeOf(*new(T)).Name()
+	b.initLabel()
+	if err := validateIdentifiers(b.Label); err != nil {
+		return err
 	}
 	if b.driver == nil {
 		var err error
-		b.driver, err = NewDriver()
+		b.driver, err = getDriver()
 		if err != nil {
-			return fmt.Errorf("failed to initialize Neo4j driver: %w", err)
+			return err
 		}
 	}
 	return nil
 }
 
 /*
-CloseDriver closes the Neo4j driver connection.
-This should be called when the application is shutting down to release resources.
+CloseDriver detaches the model from the process-wide driver.
+The shared driver itself is kept open, since the other models keep using its connection pool.
 Example:
 
-	// Close the Neo4j driver connection
 	defer dbUser.CloseDriver()
 */
 func (b *NeoBaseModel[T]) CloseDriver() {
-	if b.driver != nil {
-		b.driver.Close(context.Background())
-	}
+	b.driver = nil
 }
 
 /*
@@ -201,7 +235,7 @@ func (b *NeoBaseModel[T]) CloseDriver() {
 
 	// Find a single node in the Neo4j database
 	user := &User{}
-	err := dbUser.Find(user, "id", 123).Populate(PopulateOptions{
+	err := dbUser.Find(ctx, user, "id", 123).Populate(PopulateOptions{
 		Limit: 1,
 		Depth: 1,
 	})
@@ -210,13 +244,45 @@ func (b *NeoBaseModel[T]) CloseDriver() {
 	}
 	fmt.Println(user)
 */
-func (b *NeoBaseModel[T]) Find(model *T, field string, value interface{}) *PopulateQuery[T] {
+func (b *NeoBaseModel[T]) Find(ctx context.Context, model *T, field string, value interface{}) *PopulateQuery[T] {
 	return &PopulateQuery[T]{
+		ctx:       ctx,
 		baseModel: b,
 		model:     model,
 		field:     field,
 		value:     value,
+		err:       validateField(field),
+	}
+}
+
+/*
+@method FindOne
+
+@description Find a single node by a specific field and value, and return it as a new model.
+Unlike Find, no model has to be allocated beforehand. ErrNotFound is returned when no node matches.
+
+@params field string - The field name to search for in the database.
+
+@params value interface{} - The value to search for in the database.
+
+@params options PopulateOptions - Options for populating the node, as accepted by Populate.
+
+@returns (*T, error) - The found model, or an error.
+
+@example
+
+	world, err := FindOne[World](ctx, "elementID", id, PopulateOptions{Depth: 1})
+	if errors.Is(err, ErrNotFound) {
+		fmt.Println("no such world")
 	}
+*/
+func FindOne[T any](ctx context.Context, field string, value interface{}, options PopulateOptions) (*T, error) {
+	var base NeoBaseModel[T]
+	model := new(T)
+	if err := base.Find(ctx, model, field, value).Populate(options); err != nil {
+		return nil, err
+	}
+	return model, nil
 }
 
 /*
@@ -236,7 +302,7 @@ func (b *NeoBaseModel[T]) Find(model *T, field string, value interface{}) *Popul
 
 	// Find all nodes in the Neo4j database
 	users := []User{}
-	err := dbUser.FindAll(&users, "id", 123).Populate(PopulateOptions{
+	err := dbUser.FindAll(ctx, &users, "id", 123).Populate(PopulateOptions{
 		Limit: 1,
 		Depth: 1,
 	})
@@ -245,13 +311,134 @@ func (b *NeoBaseModel[T]) Find(model *T, field string, value interface{}) *Popul
 	}
 	fmt.Println(users)
 */
-func (b *NeoBaseModel[T]) FindAll(models *[]T, field string, value interface{}) *PopulateQuery[T] {
+func (b *NeoBaseModel[T]) FindAll(ctx context.Context, models *[]T, field string, value interface{}) *PopulateQuery[T] {
 	return &PopulateQuery[T]{
+		ctx:       ctx,
 		baseModel: b,
 		models:    models,
 		field:     field,
 		value:     value,
+		err:       validateField(field),
+	}
+}
+
+/*
+@method FindAllByIDs
+
+@description Find the nodes with the given elementIds in a single query, ie: to hydrate search results.
+Ids without a matching node are skipped, and the order of the results is not guaranteed.
+
+@params models *[]T - A pointer to a slice of models to populate with the found nodes data.
+
+@params elementIDs []string - The elementIds of the nodes to find.
+
+@returns *PopulateQuery[T] - A pointer to a PopulateQuery struct that can be used to further refine the query.
+
+@example
+
+	worlds := []World{}
+	err := dbWorld.FindAllByIDs(ctx, &worlds, hits).Populate(PopulateOptions{
+		Depth: 1,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+*/
+func (b *NeoBaseModel[T]) FindAllByIDs(ctx context.Context, models *[]T, elementIDs []string) *PopulateQuery[T] {
+	return b.FindAll(ctx, models, "", nil).Where("elementID", "IN", elementIDs)
+}
+
+/*
+@method FindWhere
+
+@description Find a single node in the Neo4j database matching every condition, each comparing a field to a value for equality.
+Other comparisons can be chained with Where.
+
+@params model *T - The model to populate with the found node data.
+
+@params conditions map[string]interface{} - The values to match, keyed by node tag.
+
+@returns *PopulateQuery[T] - A pointer to a PopulateQuery struct that can be used to further refine the query.
+
+@example
+
+	city := &City{}
+	err := dbCity.FindWhere(ctx, city, map[string]interface{}{
+		"capital": true,
+		"type":    "port",
+	}).Populate(PopulateOptions{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(city)
+*/
+func (b *NeoBaseModel[T]) FindWhere(ctx context.Context, model *T, conditions map[string]interface{}) *PopulateQuery[T] {
+	query := &PopulateQuery[T]{
+		ctx:       ctx,
+		baseModel: b,
+		model:     model,
+	}
+	for _, field := range mapKeys(conditions) {
+		query.Where(field, "=", conditions[field])
+	}
+	return query
+}
+
+/*
+@method Exists
+
+@description Check whether a node with a specific field value exists, without materializing it.
+As with Delete, the field "elementID" (or "id") matches the node by its elementId.
+
+@params field string - The field name to search for in the database.
+
+@params value interface{} - The value to search for in the database.
+
+@returns (bool, error) - Whether a matching node exists, and an error if the check failed.
+
+@example
+
+	exists, err := dbWorld.Exists(ctx, "elementID", worldID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(exists)
+*/
+func (b *NeoBaseModel[T]) Exists(ctx context.Context, field string, value interface{}) (bool, error) {
+	if err := validateIdentifiers(field); err != nil {
+		return false, err
+	}
+	if err := b.initDriver(); err != nil {
+		return false, err
 	}
+
+	session := b.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+	defer session.Close(ctx)
+
+	query := fmt.Sprintf("MATCH (n:%s) WHERE %s AND %s RETURN count(n) > 0 AS exists",
+		b.Label, nodeMatch("n", field, "value"), notDeleted("n"))
+
+	result, err := runRead(ctx, session, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		res, err := tx.Run(ctx, query, map[string]interface{}{"value": queryParam(value)})
+		if err != nil {
+			return nil, err
+		}
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		exists, _ := record.Get("exists")
+		return exists, nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	exists, ok := result.(bool)
+	if !ok {
+		return false, fmt.Errorf("unexpected result type: %T", result)
+	}
+	return exists, nil
 }
 
 /*
@@ -277,55 +464,189 @@ func (b *NeoBaseModel[T]) FindAll(models *[]T, field string, value interface{})
 		Rel:          "OWNS",
 		RelDirection: "->",
 	}
-	err := dbUser.Create(user, options)
+	err := dbUser.Create(ctx, user, options)
 	if err != nil {
 		log.Fatal(err)
 	}
 	fmt.Println(user)
 */
-func (b *NeoBaseModel[T]) Create(model *T, options CreateOptions) error {
+func (b *NeoBaseModel[T]) Create(ctx context.Context, model *T, options CreateOptions) error {
+	_, err := b.CreateRelated(ctx, model, options)
+	return err
+}
+
+/*
+@method CreateRelated
+
+@description Create a new node in the Neo4j database, as with Create, and return the elementId of the node
+it was related to following the options. The returned id is empty when the options do not describe a relationship.
+
+@params model *T - The model to create in the database.
+
+@params options CreateOptions - Options for creating the node, including field, value, label, relationship type, and direction.
+
+@returns (string, error) - The elementId of the related node, or an error.
+
+@example
+
+	world := &World{Name: "Atlantis"}
+	ownerID, err := world.CreateRelated(ctx, world, CreateOptions{
+		Field:        "userID",
+		Value:        int64(1),
+		Label:        "User",
+		Rel:          RelOwns,
+		RelDirection: "<-",
+		MustExist:    true,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(world.ID, ownerID)
+*/
+func (b *NeoBaseModel[T]) CreateRelated(ctx context.Context, model *T, options CreateOptions) (string, error) {
+	if err := options.validate(); err != nil {
+		return "", err
+	}
 	if err := b.initDriver(); err != nil {
-		return err
+		return "", err
 	}
 
-	ctx := context.Background()
 	session := b.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
 	defer session.Close(ctx)
-	defer b.driver.Close(ctx)
 
+	result, err := runWrite(ctx, session, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		return b.createTx(ctx, tx, model, options)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	id, _ := result.(string)
+	return id, nil
+}
+
+// createTx creates the model within tx, maps the created node onto it, and returns the elementId of the related node.
+func (b *NeoBaseModel[T]) createTx(ctx context.Context, tx neo4j.ManagedTransaction, model *T, options CreateOptions) (string, error) {
 	query, params := b.buildCreateQuery(model, options)
+	returnClause := " RETURN n, null AS relatedID"
+	if options.describesRelated() {
+		returnClause = " RETURN n, elementId(r) AS relatedID"
+	}
 
-	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
-		records, err := tx.Run(ctx, query+" RETURN n", params)
+	records, err := tx.Run(ctx, query+returnClause, params)
+	if err != nil {
+		return "", err
+	}
+
+	if !records.Next(ctx) {
+		if err := records.Err(); err != nil {
+			return "", err
+		}
+		// The related node was matched but not found, so no row was created.
+		return "", fmt.Errorf("%w: %s {%s: %v}", ErrRelatedNotFound, options.Label, options.Field, options.Value)
+	}
+	record := records.Record()
+
+	value, ok := record.Get("n")
+	if !ok {
+		return "", fmt.Errorf("failed to retrieve 'n' from record")
+	}
+	createdNode, ok := value.(neo4j.Node)
+	if !ok {
+		return "", fmt.Errorf("failed to cast result to neo4j.Node")
+	}
+
+	relatedID, _ := record.Get("relatedID")
+	id, _ := relatedID.(string)
+
+	return id, mapNodeToModel(createdNode, model)
+}
+
+/*
+@method CreateMany
+
+@description Create many nodes in the Neo4j database in a single transaction, using one UNWIND query.
+Each created node is mapped back onto its model, so the ID fields are populated.
+If any row fails, the transaction is rolled back and no node is created.
+
+@params models []*T - The models to create in the database.
+
+@params options CreateOptions - Options relating every created node to the same node, as in Create.
+
+@example
+
+	worlds := []*World{{Name: "Aldoria"}, {Name: "Brynmor"}}
+	err := dbWorld.CreateMany(ctx, worlds, CreateOptions{
+		Field:        "userID",
+		Value:        123,
+		Label:        "User",
+		Rel:          "OWNS",
+		RelDirection: "<-",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(worlds[0].ID)
+*/
+func (b *NeoBaseModel[T]) CreateMany(ctx context.Context, models []*T, options CreateOptions) error {
+	if len(models) == 0 {
+		return nil
+	}
+	if err := options.validate(); err != nil {
+		return err
+	}
+	if err := b.initDriver(); err != nil {
+		return err
+	}
+
+	session := b.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close(ctx)
+
+	rows := make([]interface{}, len(models))
+	for i, model := range models {
+		rows[i] = nodeProperties(reflect.ValueOf(*model))
+	}
+	params := map[string]interface{}{
+		"rows": rows,
+	}
+	query := fmt.Sprintf("UNWIND $rows AS row CREATE (n:%s) SET n = row, %s", b.Label, touchUpdatedAt) +
+		buildRelatedClause(options, "CREATE", params) + " RETURN n"
+
+	result, err := runWrite(ctx, session, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		res, err := tx.Run(ctx, query, params)
 		if err != nil {
 			return nil, err
 		}
 
-		if records.Next(ctx) {
-			value, ok := records.Record().Get("n")
-			if !ok {
-				return nil, fmt.Errorf("failed to retrieve 'n' from record")
-			}
+		var nodes []neo4j.Node
+		for res.Next(ctx) {
+			value, _ := res.Record().Get("n")
 			node, ok := value.(neo4j.Node)
 			if !ok {
 				return nil, fmt.Errorf("failed to cast result to neo4j.Node")
 			}
-			return node, nil
+			nodes = append(nodes, node)
+		}
+		if err := res.Err(); err != nil {
+			return nil, err
 		}
 
-		return nil, fmt.Errorf("failed to create node")
+		// A missing related node yields no rows; fail so the whole batch is rolled back.
+		if len(nodes) != len(models) {
+			return nil, fmt.Errorf("%w: %s {%s: %v}", ErrRelatedNotFound, options.Label, options.Field, options.Value)
+		}
+		return nodes, nil
 	})
-
 	if err != nil {
 		return err
 	}
 
-	createdNode, ok := result.(neo4j.Node)
-	if !ok {
-		return fmt.Errorf("unexpected result type: %T", result)
+	for i, node := range result.([]neo4j.Node) {
+		if err := mapNodeToModel(node, models[i]); err != nil {
+			return err
+		}
 	}
-
-	return mapNodeToModel(createdNode, model)
+	return nil
 }
 
 /*
@@ -349,12 +670,15 @@ func (b *NeoBaseModel[T]) buildCreateQuery(model *T, options CreateOptions) (str
 	queryBuilder.WriteString(fmt.Sprintf("CREATE (n:%s {", b.Label))
 	for i := 0; i < modelType.NumField(); i++ {
 		field := modelType.Field(i)
-		nodeTag := field.Tag.Get("node")
+		nodeTag := nodeTagName(field)
 		if nodeTag == "" {
 			continue
 		}
 
-		fieldValue := modelValue.Field(i).Interface()
+		fieldValue := normalizeValue(field, modelValue.Field(i).Interface())
+		if isZeroTime(fieldValue) {
+			continue
+		}
 		queryBuilder.WriteString(fmt.Sprintf("%s: $%s, ", nodeTag, nodeTag))
 		params[nodeTag] = fieldValue
 	}
@@ -364,20 +688,51 @@ func (b *NeoBaseModel[T]) buildCreateQuery(model *T, options CreateOptions) (str
 	queryBuilder.Reset()
 	queryBuilder.WriteString(query)
 	queryBuilder.WriteString("})")
+	queryBuilder.WriteString(" SET " + touchUpdatedAt)
 
-	if options.Field != "" && options.Value != nil && options.Label != "" {
-		queryBuilder.WriteString(fmt.Sprintf(" MERGE (r:%s {%s: $relatedValue})", options.Label, options.Field))
-		if options.RelDirection == "->" {
-			queryBuilder.WriteString(fmt.Sprintf(" CREATE (n)-[:%s]->(r)", options.Rel))
-		} else if options.RelDirection == "<-" {
-			queryBuilder.WriteString(fmt.Sprintf(" CREATE (n)<-[:%s]-(r)", options.Rel))
-		}
-		params["relatedValue"] = options.Value
-	}
+	queryBuilder.WriteString(buildRelatedClause(options, "CREATE", params))
 
 	return queryBuilder.String(), params
 }
 
+/*
+@method @private buildRelatedClause
+
+@description Build the clause relating n to the node described by the options, using the given
+keyword (CREATE or MERGE) for the relationship. The related node is merged on options.Field, or
+matched when options.MustExist is set or options.Field is "id" or "elementID" (matching by elementId),
+in which case it must already exist.
+It returns an empty string when the options do not describe a relationship.
+*/
+func buildRelatedClause(options CreateOptions, keyword string, params map[string]interface{}) string {
+	if !options.describesRelated() {
+		return ""
+	}
+
+	var clause string
+	if isElementIDField(options.Field) {
+		clause = fmt.Sprintf(" WITH n MATCH (r:%s) WHERE elementId(r) = $relatedValue", options.Label)
+	} else if options.MustExist {
+		clause = fmt.Sprintf(" WITH n MATCH (r:%s {%s: $relatedValue})", options.Label, options.Field)
+	} else {
+		clause = fmt.Sprintf(" MERGE (r:%s {%s: $relatedValue})", options.Label, options.Field)
+	}
+
+	if options.RelDirection == "->" {
+		clause += fmt.Sprintf(" %s (n)-[e:%s]->(r)", keyword, options.Rel)
+	} else if options.RelDirection == "<-" {
+		clause += fmt.Sprintf(" %s (n)<-[e:%s]-(r)", keyword, options.Rel)
+	}
+	params["relatedValue"] = queryParam(options.Value)
+
+	if len(options.RelProps) > 0 && options.RelDirection != "" {
+		clause += " SET e += $relProps"
+		params["relProps"] = queryParam(options.RelProps)
+	}
+
+	return clause
+}
+
 /*
 @method Delete
 
@@ -394,7 +749,7 @@ func (b *NeoBaseModel[T]) buildCreateQuery(model *T, options CreateOptions) (str
 
 	// Delete a node in the Neo4j database
 	user := &User{}
-	err := dbUser.Delete(user, "id", 123, DeleteOptions{
+	err := dbUser.Delete(ctx, user, "id", 123, DeleteOptions{
 		Detach: true,
 	})
 	if err != nil {
@@ -402,74 +757,72 @@ func (b *NeoBaseModel[T]) buildCreateQuery(model *T, options CreateOptions) (str
 	}
 	fmt.Println("Node deleted")
 */
-func (b *NeoBaseModel[T]) Delete(model *T, field string, value interface{}, options DeleteOptions) error {
+func (b *NeoBaseModel[T]) Delete(ctx context.Context, model *T, field string, value interface{}, options DeleteOptions) error {
+	if err := validateIdentifiers(field); err != nil {
+		return err
+	}
 	if err := b.initDriver(); err != nil {
 		return err
 	}
 
-	ctx := context.Background()
 	session := b.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
 	defer session.Close(ctx)
-	defer b.driver.Close(ctx)
 
+	_, err := runWrite(ctx, session, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		return nil, b.deleteTx(ctx, tx, model, field, value, options)
+	})
+
+	return err
+}
+
+// deleteTx maps the node matched on field onto the model, then deletes it, within tx.
+func (b *NeoBaseModel[T]) deleteTx(ctx context.Context, tx neo4j.ManagedTransaction, model *T, field string, value interface{}, options DeleteOptions) error {
 	queryRetrieve := fmt.Sprintf("MATCH (n:%s {%s: $value}) RETURN n", b.Label, field)
-	if field == "elementID" {
+	if isElementIDField(field) {
 		queryRetrieve = fmt.Sprintf("MATCH (n:%s) WHERE elementId(n) = $value RETURN n", b.Label)
 	}
 
 	params := map[string]interface{}{
-		"value": value,
+		"value": queryParam(value),
 	}
 
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
-		res, err := tx.Run(ctx, queryRetrieve, params)
-		if err != nil {
-			return nil, err
-		}
-
-		if res.Next(ctx) {
-			node, ok := res.Record().Get("n")
-			if !ok {
-				return nil, fmt.Errorf("failed to retrieve node before deletion")
-			}
-			return node, nil
-		}
-
-		return nil, fmt.Errorf("node not found for deletion")
-	})
-
+	res, err := tx.Run(ctx, queryRetrieve, params)
 	if err != nil {
 		return err
 	}
+	if !res.Next(ctx) {
+		if err := res.Err(); err != nil {
+			return err
+		}
+		return ErrNotFound
+	}
 
-	node, ok := result.(neo4j.Node)
+	node, ok := res.Record().Get("n")
 	if !ok {
-		return fmt.Errorf("unexpected result type: %T", result)
+		return fmt.Errorf("failed to retrieve node before deletion")
 	}
-
-	if err := mapNodeToModel(node, model); err != nil {
+	if err := mapNodeToModel(toNode(node), model); err != nil {
 		return fmt.Errorf("failed to map node to model: %w", err)
 	}
 
 	queryDelete := fmt.Sprintf("MATCH (n:%s {%s: $value}) DELETE n", b.Label, field)
 
-	if field == "elementID" {
+	if isElementIDField(field) {
 		queryDelete = fmt.Sprintf("MATCH (n:%s) WHERE elementId(n) = $value DELETE n", b.Label)
 	}
 
-	if options.Detach {
+	if options.SoftDelete {
+		queryDelete = softDeleteQuery(strings.TrimSuffix(queryDelete, " DELETE n"))
+	} else if options.Detach {
 		detachDelete := "DETACH DELETE n"
 		queryDelete = strings.Replace(queryDelete, "DELETE n", detachDelete, 1)
 	}
 
-	_, err = session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
-		result, err := tx.Run(ctx, queryDelete, params)
-		if err != nil {
-			return nil, err
-		}
-		return result.Consume(ctx)
-	})
-
+	result, err := tx.Run(ctx, queryDelete, params)
+	if err != nil {
+		return err
+	}
+	_, err = result.Consume(ctx)
 	return err
 }
 
@@ -477,6 +830,7 @@ func (b *NeoBaseModel[T]) Delete(model *T, field string, value interface{}, opti
 @method Update
 
 @description Update a node in the Neo4j database by a specific field and value.
+ErrNotFound is returned when no node matches.
 
 @params model *T - The model to update in the database.
 @params options CreateOptions - Options for adding a relationship to the node, including field, value, label, relationship type, and direction.
@@ -494,77 +848,297 @@ func (b *NeoBaseModel[T]) Delete(model *T, field string, value interface{}, opti
 		Rel:          "OWNS",
 		RelDirection: "->",
 	}
-	err := dbUser.Update(user, options)
+	err := dbUser.Update(ctx, user, options)
 	if err != nil {
 		log.Fatal(err)
 	}
 	fmt.Println(user)
 */
-func (b *NeoBaseModel[T]) Update(model *T, options CreateOptions) error {
-	if err := b.initDriver(); err != nil {
+func (b *NeoBaseModel[T]) Update(ctx context.Context, model *T, options CreateOptions) error {
+	_, err := b.update(ctx, model, "id", options)
+	return err
+}
+
+/*
+@method UpdateBy
+
+@description Update a node like Update, matching it on the given field instead of its elementId.
+The field is a node tag of the model, ie: userID, and is matched against the model's value for it;
+it is not itself updated. "id" (alias "elementID") matches on the elementId held by the model's ID field.
+
+@params model *T - The model to update in the database.
+@params field string - The node tag to match the node on.
+@params options CreateOptions - Options for adding a relationship to the node.
+@example
+
+	user := &User{UserID: 42, Username: "jane"}
+	err := dbUser.UpdateBy(ctx, user, "userID", CreateOptions{})
+	if err != nil {
+		log.Fatal(err)
+	}
+*/
+func (b *NeoBaseModel[T]) UpdateBy(ctx context.Context, model *T, field string, options CreateOptions) error {
+	_, err := b.update(ctx, model, field, options)
+	return err
+}
+
+/*
+@method UpdateChanged
+
+@description Update a node like Update, and report whether any property was actually changed.
+Properties are only written when at least one of them differs from the stored value,
+so updating a node with an identical payload reports no change.
+
+@params model *T - The model to update in the database.
+@params options CreateOptions - Options for adding a relationship to the node.
+@returns (bool, error) - Whether any property was changed, and an error if the update failed.
+@example
+
+	changed, err := dbWorld.UpdateChanged(ctx, world, CreateOptions{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !changed {
+		fmt.Println("nothing to do")
+	}
+*/
+func (b *NeoBaseModel[T]) UpdateChanged(ctx context.Context, model *T, options CreateOptions) (bool, error) {
+	summary, err := b.update(ctx, model, "id", options)
+	if err != nil {
+		return false, err
+	}
+	return summary.Counters().PropertiesSet() > 0, nil
+}
+
+/*
+@method UpdateFields
+
+@description Set only the given properties of the node matched on a field, leaving the others untouched,
+for PATCH-like partial updates. Keys must be node tags of the model. ErrNotFound is returned when no node matches.
+
+@params field string - The node tag to match the node on, or "id" (alias "elementID") for its elementId.
+@params value interface{} - The value of the field.
+@params fields map[string]interface{} - The properties to set.
+@example
+
+	// Rename a world without touching its description
+	err := dbWorld.UpdateFields(ctx, "elementID", worldID, map[string]interface{}{"name": "Atlantis"})
+	if err != nil {
+		log.Fatal(err)
+	}
+*/
+func (b *NeoBaseModel[T]) UpdateFields(ctx context.Context, field string, value interface{}, fields map[string]interface{}) error {
+	if isElementIDField(field) {
+		field = "id"
+	}
+
+	updated, err := b.UpdateAll(ctx, map[string]interface{}{field: value}, fields)
+	if err != nil {
 		return err
 	}
+	if updated == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+/*
+@method UpdateAll
+
+@description Set properties on every node matching all of the given field values, in a single query.
+Keys of both maps must be node tags of the model. An empty match is refused to avoid updating every node of the label.
+
+@params match map[string]interface{} - The field values a node must have to be updated.
+@params set map[string]interface{} - The properties to set on the matched nodes.
+@returns (int64, error) - The number of updated nodes, and an error if the update failed.
+@example
+
+	// Mark every fantasy world as legacy
+	updated, err := dbWorld.UpdateAll(ctx,
+		map[string]interface{}{"type": "fantasy"},
+		map[string]interface{}{"type": "legacy"},
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(updated, "worlds updated")
+*/
+func (b *NeoBaseModel[T]) UpdateAll(ctx context.Context, match map[string]interface{}, set map[string]interface{}) (int64, error) {
+	if len(match) == 0 {
+		return 0, fmt.Errorf("refusing to update all %s nodes: empty match", reflect.TypeOf(*new(T)).Name())
+	}
+	if len(set) == 0 {
+		return 0, fmt.Errorf("no properties to set")
+	}
+
+	modelType := reflect.TypeOf(*new(T))
+	if err := validateFields(modelType, mapKeys(match)); err != nil {
+		return 0, err
+	}
+	if err := validateFields(modelType, mapKeys(set)); err != nil {
+		return 0, err
+	}
+	if _, ok := set["id"]; ok {
+		return 0, fmt.Errorf("the id field cannot be updated")
+	}
+
+	if err := b.initDriver(); err != nil {
+		return 0, err
+	}
 
-	ctx := context.Background()
 	session := b.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
 	defer session.Close(ctx)
 
-	query, params := b.buildUpdateQuery(model, options)
+	params := map[string]interface{}{
+		"set": queryParam(normalizeProperties(modelType, set)),
+	}
+	var conditions []string
+	for field, value := range match {
+		param := "match_" + field
+		if isElementIDField(field) {
+			conditions = append(conditions, fmt.Sprintf("elementId(n) = $%s", param))
+		} else {
+			conditions = append(conditions, fmt.Sprintf("n.%s = $%s", field, param))
+		}
+		params[param] = queryParam(value)
+	}
+	conditions = append(conditions, notDeleted("n"))
+
+	query := fmt.Sprintf("MATCH (n:%s) WHERE %s SET n += $set, %s RETURN count(n) AS updated",
+		b.Label, strings.Join(conditions, " AND "), touchUpdatedAt)
 
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
-		result, err := tx.Run(ctx, query, params)
+	result, err := runWrite(ctx, session, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		res, err := tx.Run(ctx, query, params)
 		if err != nil {
 			return nil, err
 		}
-		return result.Consume(ctx)
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		updated, _ := record.Get("updated")
+		return updated, nil
 	})
+	if err != nil {
+		return 0, err
+	}
 
-	return err
+	updated, ok := result.(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected result type: %T", result)
+	}
+	return updated, nil
+}
+
+func mapKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (b *NeoBaseModel[T]) update(ctx context.Context, model *T, field string, options CreateOptions) (neo4j.ResultSummary, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+	if err := b.initDriver(); err != nil {
+		return nil, err
+	}
+
+	query, params, err := b.buildUpdateQuery(model, field, options)
+	if err != nil {
+		return nil, err
+	}
+
+	session := b.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close(ctx)
+
+	result, err := runWrite(ctx, session, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		return runUpdate(ctx, tx, query, params)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	summary, ok := result.(neo4j.ResultSummary)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type: %T", result)
+	}
+	return summary, nil
 }
 
-func (b *NeoBaseModel[T]) buildUpdateQuery(model *T, options CreateOptions) (string, map[string]interface{}) {
+// runUpdate runs an update query built by buildUpdateQuery within tx, returning ErrNotFound when it matched no node.
+func runUpdate(ctx context.Context, tx neo4j.ManagedTransaction, query string, params map[string]interface{}) (neo4j.ResultSummary, error) {
+	result, err := tx.Run(ctx, query, params)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Next(ctx) {
+		if err := result.Err(); err != nil {
+			return nil, err
+		}
+		return nil, ErrNotFound
+	}
+	return result.Consume(ctx)
+}
+
+// buildUpdateQuery builds the query updating the node matched on matchField, the node tag whose
+// model value identifies it. "id" and "elementID" match on the elementId held by the id-tagged field.
+func (b *NeoBaseModel[T]) buildUpdateQuery(model *T, matchField string, options CreateOptions) (string, map[string]interface{}, error) {
 	modelType := reflect.TypeOf(*model)
 	modelValue := reflect.ValueOf(*model)
 
+	if isElementIDField(matchField) {
+		matchField = "id"
+	}
+
 	var queryBuilder strings.Builder
 	params := make(map[string]interface{})
 
-	queryBuilder.WriteString(fmt.Sprintf("MATCH (n:%s WHERE elementId(n) = $value) ", b.Label))
-	params["value"] = modelValue.FieldByName("ID").Interface()
-
-	queryBuilder.WriteString("SET ")
+	var assignments, changes []string
 	for i := 0; i < modelType.NumField(); i++ {
 		field := modelType.Field(i)
-		nodeTag := field.Tag.Get("node")
+		nodeTag := nodeTagName(field)
 		if nodeTag == "" {
 			continue
 		}
 
-		fieldValue := modelValue.Field(i).Interface()
+		fieldValue := normalizeValue(field, modelValue.Field(i).Interface())
 
-		if nodeTag == "id" {
+		if nodeTag == matchField {
+			params["matchValue"] = queryParam(fieldValue)
+			continue
+		}
+		if nodeTag == "id" || isZeroTime(fieldValue) {
 			continue
 		}
 
 		// Default behavior for other fields
-		queryBuilder.WriteString(fmt.Sprintf("n.%s = $%s, ", nodeTag, nodeTag))
+		assignments = append(assignments, fmt.Sprintf("n.%s = $%s", nodeTag, nodeTag))
+		changes = append(changes, fmt.Sprintf("coalesce(n.%s <> $%s, (n.%s IS NULL) <> ($%s IS NULL))", nodeTag, nodeTag, nodeTag, nodeTag))
 		params[nodeTag] = fieldValue
 	}
 
-	query := queryBuilder.String()
-	query = strings.TrimSuffix(query, ", ")
-	queryBuilder.Reset()
-	queryBuilder.WriteString(query)
-
-	if options.Field != "" && options.Value != nil && options.Label != "" {
-		queryBuilder.WriteString(fmt.Sprintf(" MERGE (r:%s {%s: $relatedValue})", options.Label, options.Field))
-		if options.RelDirection == "->" {
-			queryBuilder.WriteString(fmt.Sprintf(" CREATE (n)-[:%s]->(r)", options.Rel))
-		} else if options.RelDirection == "<-" {
-			queryBuilder.WriteString(fmt.Sprintf(" CREATE (n)<-[:%s]-(r)", options.Rel))
+	value, ok := params["matchValue"]
+	switch {
+	case !ok:
+		return "", nil, fmt.Errorf("unknown field %q for %s", matchField, modelType.Name())
+	case matchField == "id":
+		if id, _ := value.(string); id == "" {
+			return "", nil, fmt.Errorf("missing elementId to update %s", modelType.Name())
 		}
-		params["relatedValue"] = options.Value
 	}
+	queryBuilder.WriteString(fmt.Sprintf("MATCH (n:%s) WHERE %s AND %s", b.Label, nodeMatch("n", matchField, "matchValue"), notDeleted("n")))
 
-	return queryBuilder.String(), params
+	// Only SET when a property differs, so the write counters reflect real changes.
+	if len(changes) > 0 {
+		queryBuilder.WriteString(fmt.Sprintf(" CALL { WITH n WITH n WHERE %s SET %s }",
+			strings.Join(changes, " OR "), strings.Join(append(assignments, touchUpdatedAt), ", ")))
+	}
+
+	queryBuilder.WriteString(buildRelatedClause(options, "CREATE", params))
+	queryBuilder.WriteString(" RETURN n")
+
+	return queryBuilder.String(), params, nil
 }
@@ -0,0 +1,524 @@
+package neo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"api/internal/app/neo4j/neotest"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+func TestUpdateChanged(t *testing.T) {
+	updated := []*neo4j.Record{neotest.Record("n", neotest.Node("4:db:1", []string{"World"}, nil))}
+
+	tests := []struct {
+		name        string
+		response    neotest.Response
+		wantChanged bool
+		wantErr     error
+	}{
+		{
+			name:     "unchanged values",
+			response: neotest.Response{Records: updated},
+		},
+		{
+			name:        "changed values",
+			response:    neotest.Response{Records: updated, PropertiesSet: 3},
+			wantChanged: true,
+		},
+		{
+			name:    "unknown node",
+			wantErr: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, func(neotest.Query) neotest.Response { return tt.response })
+
+			world := &World{ID: "4:db:1", Name: "Atlantis", Type: "fantasy"}
+			changed, err := world.UpdateChanged(context.Background(), world, CreateOptions{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateChanged() error = %v, want %v", err, tt.wantErr)
+			}
+			if changed != tt.wantChanged {
+				t.Errorf("UpdateChanged() = %v, want %v", changed, tt.wantChanged)
+			}
+
+			// The SET only runs when a property differs, so an identical payload sets nothing.
+			query := driver.Queries()[0].Cypher
+			if !strings.Contains(query, "WITH n WHERE coalesce(n.name <> $name") {
+				t.Errorf("UpdateChanged() ran %q, want the SET guarded by a change condition", query)
+			}
+		})
+	}
+}
+
+func TestUpdateAll(t *testing.T) {
+	tests := []struct {
+		name      string
+		match     map[string]any
+		set       map[string]any
+		updated   int64
+		want      int64
+		wantQuery string
+		wantErr   bool
+	}{
+		{
+			name:      "several matched nodes",
+			match:     map[string]any{"type": "fantasy"},
+			set:       map[string]any{"type": "legacy"},
+			updated:   3,
+			want:      3,
+			wantQuery: "MATCH (n:World) WHERE n.type = $match_type AND coalesce(n.deleted, false) = false SET n += $set",
+		},
+		{
+			name:      "no matched node",
+			match:     map[string]any{"type": "sci-fi"},
+			set:       map[string]any{"type": "legacy"},
+			wantQuery: "SET n += $set",
+		},
+		{
+			name:    "empty match refused",
+			set:     map[string]any{"type": "legacy"},
+			wantErr: true,
+		},
+		{
+			name:    "empty set refused",
+			match:   map[string]any{"type": "fantasy"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown match field",
+			match:   map[string]any{"owner": "alice"},
+			set:     map[string]any{"type": "legacy"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown set field",
+			match:   map[string]any{"type": "fantasy"},
+			set:     map[string]any{"owner": "alice"},
+			wantErr: true,
+		},
+		{
+			name:    "id not settable",
+			match:   map[string]any{"type": "fantasy"},
+			set:     map[string]any{"id": "4:db:2"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, func(neotest.Query) neotest.Response {
+				return neotest.Response{Records: []*neo4j.Record{neotest.Record("updated", tt.updated)}}
+			})
+
+			updated, err := (&World{}).UpdateAll(context.Background(), tt.match, tt.set)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("UpdateAll() = %d, want an error", updated)
+				}
+				if len(driver.Queries()) != 0 {
+					t.Errorf("UpdateAll() ran %d queries, want none", len(driver.Queries()))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateAll() error = %v", err)
+			}
+			if updated != tt.want {
+				t.Errorf("UpdateAll() = %d, want %d", updated, tt.want)
+			}
+
+			queries := driver.Queries()
+			if len(queries) != 1 || !strings.Contains(queries[0].Cypher, tt.wantQuery) {
+				t.Errorf("UpdateAll() ran %v, want a single query containing %q", queries, tt.wantQuery)
+			}
+			if set, _ := queries[0].Params["set"].(map[string]any); set["type"] != "legacy" {
+				t.Errorf("UpdateAll() set %v, want type legacy", queries[0].Params["set"])
+			}
+		})
+	}
+}
+
+func TestCreateRelatedByElementID(t *testing.T) {
+	created := []*neo4j.Record{neotest.Record("n", neotest.Node("4:db:1", []string{"World"}, nil), "relatedID", "4:db:10")}
+
+	tests := []struct {
+		name       string
+		options    CreateOptions
+		records    []*neo4j.Record
+		wantClause string
+		wantErr    error
+	}{
+		{
+			name:       "elementID field",
+			options:    CreateOptions{Field: "elementID", Value: "4:db:10", Label: "User", Rel: RelOwns, RelDirection: "<-"},
+			records:    created,
+			wantClause: "WITH n MATCH (r:User) WHERE elementId(r) = $relatedValue CREATE (n)<-[e:OWNS]-(r)",
+		},
+		{
+			name:       "id alias",
+			options:    CreateOptions{Field: "id", Value: "4:db:10", Label: "User", Rel: RelOwns, RelDirection: "<-"},
+			records:    created,
+			wantClause: "WITH n MATCH (r:User) WHERE elementId(r) = $relatedValue CREATE (n)<-[e:OWNS]-(r)",
+		},
+		{
+			name:       "property field",
+			options:    CreateOptions{Field: "userID", Value: int64(1), Label: "User", Rel: RelOwns, RelDirection: "<-"},
+			records:    created,
+			wantClause: "MERGE (r:User {userID: $relatedValue}) CREATE (n)<-[e:OWNS]-(r)",
+		},
+		{
+			name:       "unknown elementId",
+			options:    CreateOptions{Field: "elementID", Value: "4:db:99", Label: "User", Rel: RelOwns, RelDirection: "<-"},
+			wantClause: "WHERE elementId(r) = $relatedValue",
+			wantErr:    ErrRelatedNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, func(neotest.Query) neotest.Response { return neotest.Response{Records: tt.records} })
+
+			world := &World{Name: "Atlantis"}
+			relatedID, err := world.CreateRelated(context.Background(), world, tt.options)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateRelated() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (relatedID != "4:db:10" || world.ID != "4:db:1") {
+				t.Errorf("CreateRelated() = %q with ID %q, want 4:db:10 with ID 4:db:1", relatedID, world.ID)
+			}
+
+			query := driver.Queries()[0]
+			if !strings.Contains(query.Cypher, tt.wantClause) {
+				t.Errorf("CreateRelated() ran %q, want it to contain %q", query.Cypher, tt.wantClause)
+			}
+			if query.Params["relatedValue"] != tt.options.Value {
+				t.Errorf("CreateRelated() relatedValue = %v, want %v", query.Params["relatedValue"], tt.options.Value)
+			}
+		})
+	}
+}
+
+type contextKey struct{}
+
+func TestContextReachesQueries(t *testing.T) {
+	node := neotest.Node("4:db:1", []string{"World"}, map[string]any{"name": "Atlantis"})
+	records := []*neo4j.Record{neotest.Record("n", node, "relatedNodes", []any{})}
+
+	tests := []struct {
+		name string
+		run  func(ctx context.Context) error
+	}{
+		{
+			name: "create",
+			run: func(ctx context.Context) error {
+				world := &World{Name: "Atlantis"}
+				return world.Create(ctx, world, CreateOptions{})
+			},
+		},
+		{
+			name: "find",
+			run: func(ctx context.Context) error {
+				var world World
+				return world.Find(ctx, &world, "elementID", "4:db:1").Populate(PopulateOptions{})
+			},
+		},
+		{
+			name: "find all",
+			run: func(ctx context.Context) error {
+				var worlds []World
+				return (&World{}).FindAll(ctx, &worlds, "type", "fantasy").Populate(PopulateOptions{})
+			},
+		},
+		{
+			name: "update",
+			run: func(ctx context.Context) error {
+				world := &World{ID: "4:db:1", Name: "Atlantis"}
+				return world.Update(ctx, world, CreateOptions{})
+			},
+		},
+		{
+			name: "delete",
+			run: func(ctx context.Context) error {
+				var world World
+				return world.Delete(ctx, &world, "elementID", "4:db:1", DeleteOptions{Detach: true})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, func(neotest.Query) neotest.Response {
+				return neotest.Response{Records: records}
+			})
+
+			ctx := context.WithValue(context.Background(), contextKey{}, tt.name)
+			tt.run(ctx)
+			queries := driver.Queries()
+			if len(queries) == 0 {
+				t.Fatalf("%s ran no query", tt.name)
+			}
+			for _, query := range queries {
+				if query.Context.Value(contextKey{}) != tt.name {
+					t.Errorf("%s ran %q without the caller's context", tt.name, query.Cypher)
+				}
+			}
+
+			// A canceled request aborts the operation before it reaches the database.
+			canceled, cancel := context.WithCancel(ctx)
+			cancel()
+			if err := tt.run(canceled); !errors.Is(err, context.Canceled) {
+				t.Errorf("%s with a canceled context error = %v, want context.Canceled", tt.name, err)
+			}
+			if len(driver.Queries()) != len(queries) {
+				t.Errorf("%s with a canceled context ran a query", tt.name)
+			}
+		})
+	}
+}
+
+func TestCreateMany(t *testing.T) {
+	// createRows answers the UNWIND query with one node per row, up to limit rows.
+	createRows := func(limit int) neotest.Responder {
+		return func(query neotest.Query) neotest.Response {
+			var records []*neo4j.Record
+			for i, row := range query.Params["rows"].([]interface{}) {
+				if i == limit {
+					break
+				}
+				node := neotest.Node(fmt.Sprintf("4:db:%d", i+1), []string{"City"}, row.(map[string]interface{}))
+				records = append(records, neotest.Record("n", node))
+			}
+			return neotest.Response{Records: records}
+		}
+	}
+	unavailable := errors.New("database unavailable")
+
+	tests := []struct {
+		name    string
+		respond neotest.Responder
+		options CreateOptions
+		wantErr error
+	}{
+		{
+			name:    "every row created",
+			respond: createRows(3),
+		},
+		{
+			name:    "every row related",
+			respond: createRows(3),
+			options: CreateOptions{Field: "elementID", Value: "4:db:10", Label: "Zone", Rel: "HAS", RelDirection: "<-"},
+		},
+		{
+			name:    "missing related node",
+			respond: createRows(0),
+			options: CreateOptions{Field: "elementID", Value: "4:db:99", Label: "Zone", Rel: "HAS", RelDirection: "<-"},
+			wantErr: ErrRelatedNotFound,
+		},
+		{
+			name:    "failing row",
+			respond: func(neotest.Query) neotest.Response { return neotest.Response{Err: unavailable} },
+			wantErr: unavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, tt.respond)
+
+			cities := []*City{{Name: "Port Royal"}, {Name: "Tortuga"}, {Name: "Nassau"}}
+			err := cities[0].CreateMany(context.Background(), cities, tt.options)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateMany() error = %v, want %v", err, tt.wantErr)
+			}
+
+			queries := driver.Queries()
+			if len(queries) != 1 || driver.Transactions() != 1 {
+				t.Fatalf("CreateMany() ran %d queries in %d transactions, want one", len(queries), driver.Transactions())
+			}
+			if !strings.HasPrefix(queries[0].Cypher, "UNWIND $rows AS row CREATE (n:City) SET n = row") {
+				t.Errorf("CreateMany() ran %q", queries[0].Cypher)
+			}
+
+			for i, city := range cities {
+				wantID := ""
+				if tt.wantErr == nil {
+					wantID = fmt.Sprintf("4:db:%d", i+1)
+				}
+				if city.ID != wantID {
+					t.Errorf("cities[%d].ID = %q, want %q", i, city.ID, wantID)
+				}
+			}
+		})
+	}
+}
+
+func TestExists(t *testing.T) {
+	tests := []struct {
+		name      string
+		field     string
+		value     any
+		exists    bool
+		wantMatch string
+		wantValue any
+		wantErr   bool
+	}{
+		{
+			name:      "by element id",
+			field:     "elementID",
+			value:     "4:db:1",
+			exists:    true,
+			wantMatch: "MATCH (n:World) WHERE elementId(n) = $value AND ",
+			wantValue: "4:db:1",
+		},
+		{
+			name:      "by id alias",
+			field:     "id",
+			value:     "4:db:1",
+			exists:    true,
+			wantMatch: "MATCH (n:World) WHERE elementId(n) = $value AND ",
+			wantValue: "4:db:1",
+		},
+		{
+			name:      "missing property value",
+			field:     "name",
+			value:     "Lemuria",
+			wantMatch: "MATCH (n:World) WHERE n.name = $value AND ",
+			wantValue: "Lemuria",
+		},
+		{name: "injected field", field: "name = '' OR true //", value: "x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, func(neotest.Query) neotest.Response {
+				return neotest.Response{Records: []*neo4j.Record{neotest.Record("exists", tt.exists)}}
+			})
+
+			exists, err := new(World).Exists(context.Background(), tt.field, tt.value)
+			if tt.wantErr {
+				if err == nil || len(driver.Queries()) != 0 {
+					t.Fatalf("Exists() error = %v after %d queries, want an error and none", err, len(driver.Queries()))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exists() error = %v", err)
+			}
+			if exists != tt.exists {
+				t.Errorf("Exists() = %v, want %v", exists, tt.exists)
+			}
+
+			query := driver.Queries()[0]
+			if !strings.HasPrefix(query.Cypher, tt.wantMatch) || !strings.HasSuffix(query.Cypher, "RETURN count(n) > 0 AS exists") {
+				t.Errorf("Exists() ran %q, want it to start with %q", query.Cypher, tt.wantMatch)
+			}
+			if query.AccessMode != neo4j.AccessModeRead {
+				t.Error("Exists() ran in a write session")
+			}
+			if query.Params["value"] != tt.wantValue {
+				t.Errorf("Exists() value = %#v, want %#v", query.Params["value"], tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestUpdateBy(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       *User
+		field      string
+		wantMatch  string
+		wantValue  any
+		wantSet    []string
+		wantNotSet string
+		wantErr    string
+	}{
+		{
+			name:       "user id",
+			user:       &User{UserID: 42, Username: "jane"},
+			field:      "userID",
+			wantMatch:  "n.userID = $matchValue",
+			wantValue:  int64(42),
+			wantSet:    []string{"n.username = $username"},
+			wantNotSet: "n.userID = $userID",
+		},
+		{
+			name:       "element id",
+			user:       &User{ID: "4:db:10", UserID: 42, Username: "jane"},
+			field:      "id",
+			wantMatch:  "elementId(n) = $matchValue",
+			wantValue:  "4:db:10",
+			wantSet:    []string{"n.username = $username", "n.userID = $userID"},
+			wantNotSet: "n.id = $id",
+		},
+		{
+			name:       "element id alias",
+			user:       &User{ID: "4:db:10", Username: "jane"},
+			field:      "elementID",
+			wantMatch:  "elementId(n) = $matchValue",
+			wantValue:  "4:db:10",
+			wantSet:    []string{"n.username = $username"},
+			wantNotSet: "n.id = $id",
+		},
+		{
+			name:    "unknown field",
+			user:    &User{Username: "jane"},
+			field:   "email",
+			wantErr: `unknown field "email"`,
+		},
+		{
+			name:    "missing element id",
+			user:    &User{Username: "jane"},
+			field:   "id",
+			wantErr: "missing elementId",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, func(neotest.Query) neotest.Response {
+				node := neotest.Node("4:db:10", []string{"User"}, map[string]any{"username": "jane", "userID": int64(42)})
+				return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", node)}, PropertiesSet: 1}
+			})
+
+			err := tt.user.UpdateBy(context.Background(), tt.user, tt.field, CreateOptions{})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("UpdateBy() error = %v, want %q", err, tt.wantErr)
+				}
+				if len(driver.Queries()) != 0 {
+					t.Errorf("UpdateBy() ran %d queries, want none", len(driver.Queries()))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateBy() error = %v", err)
+			}
+
+			query := driver.Queries()[0]
+			if !strings.HasPrefix(query.Cypher, "MATCH (n:User) WHERE "+tt.wantMatch) {
+				t.Errorf("UpdateBy() ran %q, want it to match on %q", query.Cypher, tt.wantMatch)
+			}
+			if query.Params["matchValue"] != tt.wantValue {
+				t.Errorf("UpdateBy() matchValue = %#v, want %#v", query.Params["matchValue"], tt.wantValue)
+			}
+			for _, set := range tt.wantSet {
+				if !strings.Contains(query.Cypher, set) {
+					t.Errorf("UpdateBy() ran %q, want it to set %q", query.Cypher, set)
+				}
+			}
+			// The matched field is never itself written.
+			if strings.Contains(query.Cypher, tt.wantNotSet) {
+				t.Errorf("UpdateBy() ran %q, want it not to set %q", query.Cypher, tt.wantNotSet)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package neo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+/*
+WriteStats holds the write counters reported by Neo4j for a single operation.
+*/
+type WriteStats struct {
+	NodesCreated         int `json:"nodesCreated"`
+	NodesDeleted         int `json:"nodesDeleted"`
+	RelationshipsCreated int `json:"relationshipsCreated"`
+	RelationshipsDeleted int `json:"relationshipsDeleted"`
+	PropertiesSet        int `json:"propertiesSet"`
+}
+
+func newWriteStats(counters neo4j.Counters) WriteStats {
+	return WriteStats{
+		NodesCreated:         counters.NodesCreated(),
+		NodesDeleted:         counters.NodesDeleted(),
+		RelationshipsCreated: counters.RelationshipsCreated(),
+		RelationshipsDeleted: counters.RelationshipsDeleted(),
+		PropertiesSet:        counters.PropertiesSet(),
+	}
+}
+
+type batchOperation struct {
+	query  string
+	params map[string]interface{}
+	mapper func(node neo4j.Node) error
+	err    error // set when the operation could not be built, returned by Flush when reached
+	// missing is returned by Flush when the query returns no node, "failed to create node" when nil
+	missing error
+}
+
+/*
+Batch accumulates independent write operations and runs them over a single session when flushed.
+Each operation runs in its own transaction, so a failing operation does not roll back the ones before it.
+Example:
+
+	batch := NewBatch()
+	for _, world := range worlds {
+		dbWorld.QueueCreate(batch, world, CreateOptions{})
+	}
+	stats, err := batch.Flush(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(stats)
+*/
+type Batch struct {
+	operations []batchOperation
+}
+
+/*
+NewBatch creates an empty Batch.
+*/
+func NewBatch() *Batch {
+	return &Batch{}
+}
+
+/*
+Len returns the number of operations waiting to be flushed.
+*/
+func (batch *Batch) Len() int {
+	return len(batch.operations)
+}
+
+/*
+@method Flush
+
+@description Run every queued operation over a single session, in the order they were queued.
+The batch is emptied once all operations succeed. On error, the operations that already ran are kept
+out of the batch and the stats collected so far are returned alongside the error. The failed operation stays
+queued to be retried by the next Flush, unless it could not be built, ie: for an invalid label: it is
+dropped, as it would fail again.
+
+@returns ([]WriteStats, error) - The write stats of each executed operation, and an error if an operation failed.
+*/
+func (batch *Batch) Flush(ctx context.Context) ([]WriteStats, error) {
+	if len(batch.operations) == 0 {
+		return nil, nil
+	}
+
+	driver, err := getDriver()
+	if err != nil {
+		return nil, err
+	}
+
+	session := driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close(ctx)
+
+	stats := make([]WriteStats, 0, len(batch.operations))
+	for len(batch.operations) > 0 {
+		operation := batch.operations[0]
+		if operation.err != nil {
+			batch.operations = batch.operations[1:]
+			return stats, operation.err
+		}
+
+		result, err := runWrite(ctx, session, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+			res, err := tx.Run(ctx, operation.query, operation.params)
+			if err != nil {
+				return nil, err
+			}
+
+			if operation.mapper != nil {
+				if !res.Next(ctx) {
+					if err := res.Err(); err != nil {
+						return nil, err
+					}
+					if operation.missing != nil {
+						return nil, operation.missing
+					}
+					return nil, fmt.Errorf("failed to create node")
+				}
+				value, _ := res.Record().Get("n")
+				node, ok := value.(neo4j.Node)
+				if !ok {
+					return nil, fmt.Errorf("failed to cast result to neo4j.Node")
+				}
+				if err := operation.mapper(node); err != nil {
+					return nil, err
+				}
+			}
+
+			return res.Consume(ctx)
+		})
+		if err != nil {
+			return stats, err
+		}
+
+		summary, ok := result.(neo4j.ResultSummary)
+		if !ok {
+			return stats, fmt.Errorf("unexpected result type: %T", result)
+		}
+		stats = append(stats, newWriteStats(summary.Counters()))
+		batch.operations = batch.operations[1:]
+	}
+
+	return stats, nil
+}
+
+/*
+@method QueueCreate
+
+@description Queue the creation of a node in a Batch. The model is populated with the created node when the batch is flushed.
+
+@params batch *Batch - The batch to queue the operation in.
+
+@params model *T - The model to create in the database.
+
+@params options CreateOptions - Options for creating the node, as accepted by Create.
+*/
+func (b *NeoBaseModel[T]) QueueCreate(batch *Batch, model *T, options CreateOptions) {
+	b.initLabel()
+	query, params := b.buildCreateQuery(model, options)
+	batch.operations = append(batch.operations, batchOperation{
+		query:  query + " RETURN n",
+		params: params,
+		err:    errors.Join(validateIdentifiers(b.Label), options.validate()),
+		mapper: func(node neo4j.Node) error {
+			return mapNodeToModel(node, model)
+		},
+	})
+}
+
+/*
+@method QueueUpdate
+
+@description Queue the update of a node in a Batch. Flush returns ErrNotFound when the node does not exist.
+
+@params batch *Batch - The batch to queue the operation in.
+
+@params model *T - The model to update in the database.
+
+@params options CreateOptions - Options for adding a relationship to the node, as accepted by Update.
+*/
+func (b *NeoBaseModel[T]) QueueUpdate(batch *Batch, model *T, options CreateOptions) {
+	b.initLabel()
+	query, params, err := b.buildUpdateQuery(model, "id", options)
+	batch.operations = append(batch.operations, batchOperation{
+		query:  query,
+		params: params,
+		err:    errors.Join(err, validateIdentifiers(b.Label), options.validate()),
+		mapper: func(neo4j.Node) error {
+			return nil
+		},
+		missing: ErrNotFound,
+	})
+}
+
+func (b *NeoBaseModel[T]) initLabel() {
+	if b.Label == "" {
+		b.Label = reflect.TypeOf(*new(T)).Name()
+	}
+}
@@ -0,0 +1,124 @@
+package neo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"api/internal/app/neo4j/neotest"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+func TestBatchFlush(t *testing.T) {
+	errTransient := errors.New("transient failure")
+
+	tests := []struct {
+		name      string
+		creates   int
+		failAt    int // 1-based query failing with errTransient, 0 for none
+		wantStats int
+		wantLeft  int
+		wantErr   error
+	}{
+		{name: "ten creates", creates: 10, wantStats: 10},
+		{name: "single create", creates: 1, wantStats: 1},
+		{name: "failing create", creates: 10, failAt: 3, wantStats: 2, wantLeft: 8, wantErr: errTransient},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var queries int
+			driver := useFakeDriver(t, func(neotest.Query) neotest.Response {
+				queries++
+				if queries == tt.failAt {
+					return neotest.Response{Err: errTransient}
+				}
+				node := neotest.Node(fmt.Sprintf("4:db:%d", queries), []string{"City"}, nil)
+				return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", node)}, NodesCreated: 1, PropertiesSet: 1}
+			})
+
+			batch := NewBatch()
+			cities := make([]*City, tt.creates)
+			for i := range cities {
+				cities[i] = &City{Name: fmt.Sprintf("City %d", i)}
+				cities[i].QueueCreate(batch, cities[i], CreateOptions{})
+			}
+
+			stats, err := batch.Flush(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Flush() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(stats) != tt.wantStats {
+				t.Errorf("Flush() returned %d stats, want %d", len(stats), tt.wantStats)
+			}
+			for i, stat := range stats {
+				if stat.NodesCreated != 1 {
+					t.Errorf("stats[%d].NodesCreated = %d, want 1", i, stat.NodesCreated)
+				}
+				if cities[i].ID == "" {
+					t.Errorf("cities[%d].ID is empty, want the created elementId", i)
+				}
+			}
+			if batch.Len() != tt.wantLeft {
+				t.Errorf("Len() = %d after Flush, want %d", batch.Len(), tt.wantLeft)
+			}
+			if driver.Sessions() != 1 {
+				t.Errorf("Flush() opened %d sessions, want 1", driver.Sessions())
+			}
+		})
+	}
+}
+
+func TestBatchFlushInvalidOperation(t *testing.T) {
+	driver := useFakeDriver(t, func(neotest.Query) neotest.Response {
+		node := neotest.Node("4:db:1", []string{"City"}, nil)
+		return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", node)}, NodesCreated: 1}
+	})
+
+	batch := NewBatch()
+	for i := 0; i < 4; i++ {
+		options := CreateOptions{}
+		if i == 1 {
+			options = CreateOptions{Label: "World Map", Field: "name", Value: "Atlantis", Rel: RelHas, RelDirection: "<-"}
+		}
+		city := &City{Name: fmt.Sprintf("City %d", i)}
+		city.QueueCreate(batch, city, options)
+	}
+
+	flushes := []struct {
+		wantErr   error
+		wantStats int
+		wantLeft  int
+	}{
+		// The invalid operation is reported once and dropped, so the operations after it still run.
+		{wantErr: ErrInvalidIdentifier, wantStats: 1, wantLeft: 2},
+		{wantStats: 2},
+	}
+	for i, flush := range flushes {
+		stats, err := batch.Flush(context.Background())
+		if !errors.Is(err, flush.wantErr) {
+			t.Fatalf("Flush() #%d error = %v, want %v", i+1, err, flush.wantErr)
+		}
+		if len(stats) != flush.wantStats || batch.Len() != flush.wantLeft {
+			t.Errorf("Flush() #%d returned %d stats leaving %d operations, want %d stats leaving %d",
+				i+1, len(stats), batch.Len(), flush.wantStats, flush.wantLeft)
+		}
+	}
+	if queries := driver.Queries(); len(queries) != 3 {
+		t.Errorf("Flush() ran %d queries, want 3", len(queries))
+	}
+}
+
+func TestBatchQueueUpdateNotFound(t *testing.T) {
+	useFakeDriver(t, nil)
+
+	batch := NewBatch()
+	city := &City{ID: "4:db:1", Name: "Port Royal"}
+	city.QueueUpdate(batch, city, CreateOptions{})
+
+	if _, err := batch.Flush(context.Background()); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Flush() error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,180 @@
+package neo
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+/*
+QueryBuilder assembles a Cypher query from clauses, in the order they are added, along with its parameters.
+Build checks that each clause follows one it can follow in Cypher, ie: WHERE after MATCH or WITH,
+and that nothing but ORDER BY, SKIP and LIMIT follows RETURN.
+
+Example usage:
+
+	query, params, err := NewQueryBuilder().
+		Match("(w:World)").
+		Where("elementId(w) = $worldID").
+		With("w").
+		Create("(w)-[:HAS]->(c:Continent {name: $name})").
+		WithParam("worldID", worldID).
+		WithParam("name", "North").
+		Return("c").
+		Build()
+*/
+type QueryBuilder struct {
+	clauses []builderClause
+	params  map[string]interface{}
+	err     error // set by a clause given an invalid value, returned by Build
+}
+
+type builderClause struct {
+	keyword string
+	body    string
+}
+
+/*
+NewQueryBuilder creates an empty QueryBuilder.
+*/
+func NewQueryBuilder() *QueryBuilder {
+	return &QueryBuilder{params: make(map[string]interface{})}
+}
+
+func (qb *QueryBuilder) add(keyword string, body string) *QueryBuilder {
+	qb.clauses = append(qb.clauses, builderClause{keyword: keyword, body: body})
+	return qb
+}
+
+// Match adds a MATCH clause, ie: Match("(n:World)").
+func (qb *QueryBuilder) Match(pattern string) *QueryBuilder {
+	return qb.add("MATCH", pattern)
+}
+
+// OptionalMatch adds an OPTIONAL MATCH clause.
+func (qb *QueryBuilder) OptionalMatch(pattern string) *QueryBuilder {
+	return qb.add("OPTIONAL MATCH", pattern)
+}
+
+// Where adds a WHERE clause filtering the preceding MATCH, OPTIONAL MATCH or WITH.
+func (qb *QueryBuilder) Where(condition string) *QueryBuilder {
+	return qb.add("WHERE", condition)
+}
+
+// With adds a WITH clause, ie: With("n, count(c) AS continents").
+func (qb *QueryBuilder) With(expressions string) *QueryBuilder {
+	return qb.add("WITH", expressions)
+}
+
+// Create adds a CREATE clause.
+func (qb *QueryBuilder) Create(pattern string) *QueryBuilder {
+	return qb.add("CREATE", pattern)
+}
+
+// Merge adds a MERGE clause.
+func (qb *QueryBuilder) Merge(pattern string) *QueryBuilder {
+	return qb.add("MERGE", pattern)
+}
+
+// Set adds a SET clause, ie: Set("n.name = $name").
+func (qb *QueryBuilder) Set(assignments string) *QueryBuilder {
+	return qb.add("SET", assignments)
+}
+
+// Remove adds a REMOVE clause, ie: Remove("n.legacy").
+func (qb *QueryBuilder) Remove(items string) *QueryBuilder {
+	return qb.add("REMOVE", items)
+}
+
+// Delete adds a DELETE clause, or a DETACH DELETE clause when detach is set.
+func (qb *QueryBuilder) Delete(variables string, detach bool) *QueryBuilder {
+	if detach {
+		return qb.add("DETACH DELETE", variables)
+	}
+	return qb.add("DELETE", variables)
+}
+
+// Return adds the RETURN clause.
+func (qb *QueryBuilder) Return(expressions string) *QueryBuilder {
+	return qb.add("RETURN", expressions)
+}
+
+// OrderBy adds an ORDER BY clause to the preceding RETURN or WITH, ie: OrderBy("n.name DESC").
+func (qb *QueryBuilder) OrderBy(expressions string) *QueryBuilder {
+	return qb.add("ORDER BY", expressions)
+}
+
+// Skip adds a SKIP clause to the preceding RETURN or WITH. A negative n makes Build fail.
+func (qb *QueryBuilder) Skip(n int) *QueryBuilder {
+	return qb.addCount("SKIP", n)
+}
+
+// Limit adds a LIMIT clause to the preceding RETURN or WITH. A negative n makes Build fail.
+func (qb *QueryBuilder) Limit(n int) *QueryBuilder {
+	return qb.addCount("LIMIT", n)
+}
+
+func (qb *QueryBuilder) addCount(keyword string, n int) *QueryBuilder {
+	if n < 0 && qb.err == nil {
+		qb.err = fmt.Errorf("%s must not be negative, got %d", keyword, n)
+	}
+	return qb.add(keyword, fmt.Sprint(n))
+}
+
+// WithParam sets a parameter of the query, referenced as $name in the clauses.
+func (qb *QueryBuilder) WithParam(name string, value interface{}) *QueryBuilder {
+	qb.params[name] = queryParam(value)
+	return qb
+}
+
+// builderFollows lists, for the clauses that cannot start a query part, the clauses they may directly follow.
+var builderFollows = map[string][]string{
+	"WHERE":    {"MATCH", "OPTIONAL MATCH", "WITH"},
+	"ORDER BY": {"RETURN", "WITH"},
+	"SKIP":     {"RETURN", "WITH", "ORDER BY"},
+	"LIMIT":    {"RETURN", "WITH", "ORDER BY", "SKIP"},
+}
+
+// builderAfterReturn lists the clauses allowed after RETURN.
+var builderAfterReturn = map[string]bool{
+	"ORDER BY": true,
+	"SKIP":     true,
+	"LIMIT":    true,
+}
+
+/*
+@method Build
+
+@description Assemble the query from its clauses, in the order they were added.
+
+@returns (string, map[string]interface{}, error) - The query, its parameters, and an error when a clause is out of order
+or was given an invalid value.
+*/
+func (qb *QueryBuilder) Build() (string, map[string]interface{}, error) {
+	if qb.err != nil {
+		return "", nil, qb.err
+	}
+	if len(qb.clauses) == 0 {
+		return "", nil, fmt.Errorf("empty query")
+	}
+
+	parts := make([]string, 0, len(qb.clauses))
+	previous := ""
+	returned := false
+	for _, clause := range qb.clauses {
+		if returned && !builderAfterReturn[clause.keyword] {
+			return "", nil, fmt.Errorf("%s cannot follow RETURN", clause.keyword)
+		}
+		if allowed, ok := builderFollows[clause.keyword]; ok && !slices.Contains(allowed, previous) {
+			return "", nil, fmt.Errorf("%s must follow %s", clause.keyword, strings.Join(allowed, " or "))
+		}
+		if clause.keyword == "RETURN" {
+			returned = true
+		}
+
+		parts = append(parts, clause.keyword+" "+clause.body)
+		previous = clause.keyword
+	}
+
+	return strings.Join(parts, " "), qb.params, nil
+}
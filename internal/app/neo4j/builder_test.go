@@ -0,0 +1,107 @@
+package neo
+
+import "testing"
+
+func TestQueryBuilderBuild(t *testing.T) {
+	tests := []struct {
+		name    string
+		build   func(qb *QueryBuilder) *QueryBuilder
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "match where return",
+			build: func(qb *QueryBuilder) *QueryBuilder {
+				return qb.Match("(w:World)").Where("w.name = $name").Return("w")
+			},
+			want: "MATCH (w:World) WHERE w.name = $name RETURN w",
+		},
+		{
+			name: "paged return",
+			build: func(qb *QueryBuilder) *QueryBuilder {
+				return qb.Match("(w:World)").Return("w").OrderBy("w.name").Skip(20).Limit(10)
+			},
+			want: "MATCH (w:World) RETURN w ORDER BY w.name SKIP 20 LIMIT 10",
+		},
+		{
+			name: "zero skip and limit",
+			build: func(qb *QueryBuilder) *QueryBuilder {
+				return qb.Match("(w:World)").Return("w").Skip(0).Limit(0)
+			},
+			want: "MATCH (w:World) RETURN w SKIP 0 LIMIT 0",
+		},
+		{
+			name: "detach delete",
+			build: func(qb *QueryBuilder) *QueryBuilder {
+				return qb.Match("(w:World)").Delete("w", true)
+			},
+			want: "MATCH (w:World) DETACH DELETE w",
+		},
+		{
+			name: "negative skip",
+			build: func(qb *QueryBuilder) *QueryBuilder {
+				return qb.Match("(w:World)").Return("w").Skip(-1)
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative limit",
+			build: func(qb *QueryBuilder) *QueryBuilder {
+				return qb.Match("(w:World)").Return("w").Limit(-5)
+			},
+			wantErr: true,
+		},
+		{
+			name: "where without match",
+			build: func(qb *QueryBuilder) *QueryBuilder {
+				return qb.Where("w.name = $name")
+			},
+			wantErr: true,
+		},
+		{
+			name: "clause after return",
+			build: func(qb *QueryBuilder) *QueryBuilder {
+				return qb.Match("(w:World)").Return("w").Set("w.name = $name")
+			},
+			wantErr: true,
+		},
+		{
+			name:    "empty query",
+			build:   func(qb *QueryBuilder) *QueryBuilder { return qb },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, _, err := tt.build(NewQueryBuilder()).Build()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Build() = %q, want an error", query)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if query != tt.want {
+				t.Errorf("Build() = %q, want %q", query, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilderParams(t *testing.T) {
+	_, params, err := NewQueryBuilder().
+		Match("(w:World)").
+		Where("w.name = $name").
+		WithParam("name", "Atlantis").
+		Return("w").
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if params["name"] != "Atlantis" {
+		t.Errorf("params[name] = %v, want Atlantis", params["name"])
+	}
+}
@@ -0,0 +1,76 @@
+package neo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// internalProperties are bookkeeping properties that are never copied onto a new node.
+var internalProperties = []string{"_version", deletedProperty, deletedAtProperty}
+
+/*
+@method Copy
+
+@description Create a new node with the same label and properties as an existing node, without its relationships.
+Internal properties such as _version and deletedAt are not copied. The new node gets its own elementId.
+A "not found" error is returned when no node has the given elementId.
+
+@params elementID string - The elementId of the node to copy.
+
+@returns (*T, error) - The model of the new node, and an error if the copy failed.
+
+@example
+
+	copied, err := dbCity.Copy(ctx, city.ID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(copied.ID != city.ID)
+*/
+func (b *NeoBaseModel[T]) Copy(ctx context.Context, elementID string) (*T, error) {
+	if err := b.initDriver(); err != nil {
+		return nil, err
+	}
+
+	session := b.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close(ctx)
+
+	removed := make([]string, len(internalProperties))
+	for i, property := range internalProperties {
+		removed[i] = "n." + property
+	}
+	query := fmt.Sprintf("MATCH (o:%s) WHERE elementId(o) = $value AND %s CREATE (n:%s) SET n = properties(o), %s REMOVE %s RETURN n",
+		b.Label, notDeleted("o"), b.Label, touchUpdatedAt, strings.Join(removed, ", "))
+
+	result, err := runWrite(ctx, session, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		res, err := tx.Run(ctx, query, map[string]interface{}{"value": elementID})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			if err := res.Err(); err != nil {
+				return nil, err
+			}
+			return nil, ErrNotFound
+		}
+		value, _ := res.Record().Get("n")
+		return value, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	node, ok := result.(neo4j.Node)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type: %T", result)
+	}
+
+	model := new(T)
+	if err := mapNodeToModel(node, model); err != nil {
+		return nil, err
+	}
+	return model, nil
+}
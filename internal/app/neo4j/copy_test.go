@@ -0,0 +1,66 @@
+package neo
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"strings"
+	"testing"
+
+	"api/internal/app/neo4j/neotest"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+func TestCopy(t *testing.T) {
+	original := City{ID: "4:db:1", Name: "Port Royal", Type: "port", Population: 6500, Capital: true}
+	stored := map[string]any{
+		"name": original.Name, "type": original.Type, "population": original.Population, "capital": original.Capital,
+		"_version": int64(3), "deleted": false, "deletedAt": nil,
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		wantErr error
+	}{
+		{name: "existing node", id: "4:db:1"},
+		{name: "unknown node", id: "4:db:99", wantErr: ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The fake copies the stored properties of 4:db:1 as the query would, internal ones removed.
+			driver := useFakeDriver(t, func(query neotest.Query) neotest.Response {
+				if query.Params["value"] != "4:db:1" {
+					return neotest.Response{}
+				}
+				props := maps.Clone(stored)
+				for _, property := range internalProperties {
+					delete(props, property)
+				}
+				return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", neotest.Node("4:db:2", []string{"City"}, props))}}
+			})
+
+			copied, err := new(City).Copy(context.Background(), tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Copy() error = %v, want %v", err, tt.wantErr)
+			}
+
+			query := driver.Queries()[0].Cypher
+			if !strings.Contains(query, "SET n = properties(o)") || !strings.Contains(query, "REMOVE n._version, n.deleted, n.deletedAt") {
+				t.Errorf("Copy() ran %q", query)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if copied.ID == original.ID {
+				t.Errorf("Copy() kept the id %q", copied.ID)
+			}
+			if copied.Name != original.Name || copied.Type != original.Type || copied.Population != original.Population || copied.Capital != original.Capital {
+				t.Errorf("Copy() = %+v, want the properties of %+v", copied, original)
+			}
+		})
+	}
+}
@@ -1,15 +1,36 @@
 package neo
 
 import (
+	"api/internal/app/utils"
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"os"
 	"reflect"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	neo4jconfig "github.com/neo4j/neo4j-go-driver/v5/neo4j/config"
 )
 
+/*
+DriverConfig holds the settings applied to the Neo4j driver. A zero value keeps the driver default.
+  - @property MaxConnectionPoolSize: The maximum number of connections per host kept in the pool.
+  - @property ConnectionAcquisitionTimeout: The maximum time spent waiting for a connection from the pool.
+  - @property MaxConnectionLifetime: The maximum age of a pooled connection before it is closed.
+  - @property MaxTransactionRetryTime: The maximum time managed transactions (ExecuteRead/ExecuteWrite) are retried on transient errors, 30s by default. MaxRetryTime adds further retries when set.
+*/
+type DriverConfig struct {
+	MaxConnectionPoolSize        int
+	ConnectionAcquisitionTimeout time.Duration
+	MaxConnectionLifetime        time.Duration
+	MaxTransactionRetryTime      time.Duration
+}
+
 /*
 NewDriver initializes a new Neo4j driver using environment variables.
 It loads the Neo4j connection details from a .env file and verifies the connectivity to the database.
@@ -18,18 +39,77 @@ The .env file should contain the following variables:
   - NEO4J_URI: The URI of the Neo4j database.
   - NEO4J_USER: The username for the Neo4j database.
   - NEO4J_PASSWORD: The password for the Neo4j database.
+    NEO4J_PASSWORD_FILE can be set instead to read the password from a file.
+
+The following variables are optional and set the matching DriverConfig settings, durations use the Go format (ie: 5s):
+  - NEO4J_MAX_CONNECTION_POOL_SIZE
+  - NEO4J_CONNECTION_ACQUISITION_TIMEOUT
+  - NEO4J_MAX_CONNECTION_LIFETIME
+  - NEO4J_MAX_TRANSACTION_RETRY_TIME
 */
 func NewDriver() (neo4j.DriverWithContext, error) {
+	// Without a .env file the settings come from the environment alone.
 	err := godotenv.Load()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	config, err := driverConfigFromEnv()
 	if err != nil {
 		return nil, err
 	}
 
+	return NewDriverWithConfig(config)
+}
+
+/*
+NewDriverWithConfig initializes a new Neo4j driver like NewDriver, applying the provided DriverConfig.
+The connection details are still read from the environment.
+*/
+func NewDriverWithConfig(driverConfig DriverConfig) (neo4j.DriverWithContext, error) {
 	uri := os.Getenv("NEO4J_URI")
 	username := os.Getenv("NEO4J_USER")
-	password := os.Getenv("NEO4J_PASSWORD")
+	password, err := utils.Secret("NEO4J_PASSWORD")
+	if err != nil {
+		return nil, err
+	}
+
+	return connect(uri, username, password, driverConfig)
+}
+
+// driverConfigFromEnv reads the optional DriverConfig settings from the environment.
+func driverConfigFromEnv() (DriverConfig, error) {
+	var config DriverConfig
+	if value := os.Getenv("NEO4J_MAX_CONNECTION_POOL_SIZE"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil {
+			return config, fmt.Errorf("invalid NEO4J_MAX_CONNECTION_POOL_SIZE: %w", err)
+		}
+		config.MaxConnectionPoolSize = size
+	}
+
+	durations := map[string]*time.Duration{
+		"NEO4J_CONNECTION_ACQUISITION_TIMEOUT": &config.ConnectionAcquisitionTimeout,
+		"NEO4J_MAX_CONNECTION_LIFETIME":        &config.MaxConnectionLifetime,
+		"NEO4J_MAX_TRANSACTION_RETRY_TIME":     &config.MaxTransactionRetryTime,
+	}
+	for name, duration := range durations {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return config, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		*duration = parsed
+	}
+	return config, nil
+}
 
-	driver, err := neo4j.NewDriverWithContext(uri, neo4j.BasicAuth(username, password, ""))
+// connect creates a driver for the given connection details and verifies the connectivity to the database.
+func connect(uri string, username string, password string, driverConfig DriverConfig) (neo4j.DriverWithContext, error) {
+	driver, err := neo4j.NewDriverWithContext(uri, neo4j.BasicAuth(username, password, ""), driverConfig.apply)
 	if err != nil {
 		return nil, err
 	}
@@ -37,30 +117,134 @@ func NewDriver() (neo4j.DriverWithContext, error) {
 	ctx := context.Background()
 	err = driver.VerifyConnectivity(ctx)
 	if err != nil {
+		driver.Close(ctx)
 		return nil, err
 	}
 	return driver, nil
 }
 
-func buildNodeTree[T any](records []neo4j.Record) ([]*T, error) {
+// sharedDriver is the driver, and so the connection pool, used by every model of the process.
+var (
+	sharedDriverMu sync.Mutex
+	sharedDriver   neo4j.DriverWithContext
+)
+
+/*
+InitDriver connects the process-wide driver shared by every model, replacing the previous one, if any.
+The optional DriverConfig settings are read from the environment, as in NewDriver.
+When InitDriver is not called, the shared driver is created by NewDriver on first use.
+Example:
+
+	err := neo.InitDriver("neo4j://localhost:7687", "neo4j", "password")
+	if err != nil {
+		log.Fatal(err)
+	}
+*/
+func InitDriver(uri string, username string, password string) error {
+	config, err := driverConfigFromEnv()
+	if err != nil {
+		return err
+	}
+
+	driver, err := connect(uri, username, password, config)
+	if err != nil {
+		return err
+	}
+
+	SetDriver(driver)
+	return nil
+}
+
+/*
+SetDriver replaces the process-wide driver shared by every model, ie: to inject a mock in tests.
+The previous driver is not closed.
+*/
+func SetDriver(driver neo4j.DriverWithContext) {
+	sharedDriverMu.Lock()
+	defer sharedDriverMu.Unlock()
+	sharedDriver = driver
+}
+
+/*
+CloseGlobalDriver closes the process-wide driver shared by every model, if it was created.
+It should be called once on shutdown; a later operation creates a new driver.
+*/
+func CloseGlobalDriver(ctx context.Context) error {
+	sharedDriverMu.Lock()
+	defer sharedDriverMu.Unlock()
+
+	if sharedDriver == nil {
+		return nil
+	}
+	err := sharedDriver.Close(ctx)
+	sharedDriver = nil
+	return err
+}
+
+// getDriver returns the shared driver, creating it with NewDriver if it was not initialized yet.
+func getDriver() (neo4j.DriverWithContext, error) {
+	sharedDriverMu.Lock()
+	defer sharedDriverMu.Unlock()
+
+	if sharedDriver == nil {
+		driver, err := NewDriver()
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Neo4j driver: %w", err)
+		}
+		sharedDriver = driver
+	}
+	return sharedDriver, nil
+}
+
+// apply copies the non-zero settings of the DriverConfig onto the driver's config.
+func (c DriverConfig) apply(config *neo4jconfig.Config) {
+	if c.MaxConnectionPoolSize > 0 {
+		config.MaxConnectionPoolSize = c.MaxConnectionPoolSize
+	}
+	if c.ConnectionAcquisitionTimeout > 0 {
+		config.ConnectionAcquisitionTimeout = c.ConnectionAcquisitionTimeout
+	}
+	if c.MaxConnectionLifetime > 0 {
+		config.MaxConnectionLifetime = c.MaxConnectionLifetime
+	}
+	if c.MaxTransactionRetryTime > 0 {
+		config.MaxTransactionRetryTime = c.MaxTransactionRetryTime
+	}
+}
+
+// defaultBinding is the variable the generated queries bind the root node to.
+const defaultBinding = "n"
+
+// buildNodeTree maps the node bound to binding in each record, along with its related nodes.
+// A record missing the binding is reported as an error rather than skipped, so a query
+// aliasing the node differently does not silently yield an empty result.
+// When includeLabels is set, the node's labels are copied onto the model's Labels field.
+func buildNodeTree[T any](records []neo4j.Record, binding string, includeLabels bool) ([]*T, error) {
 	var results []*T
 
 	for _, record := range records {
-		node, ok := record.Get("n")
+		node, ok := record.Get(binding)
 		if !ok {
-			continue
+			return nil, fmt.Errorf("record is missing the expected %q binding, got %v", binding, record.Keys)
 		}
 
-		relatedNodes, _ := record.Get("relatedNodes")
+		relatedNodes, err := recordRelatedNodes(record)
+		if err != nil {
+			return nil, err
+		}
 
 		model := new(T)
-		err := mapNodeToModel(node.(neo4j.Node), model)
+		err = mapNodeToModel(toNode(node), model)
 		if err != nil {
 			return nil, err
 		}
 
+		if includeLabels {
+			setLabels(model, toNode(node).Labels)
+		}
+
 		if relatedNodes != nil {
-			err := mapRelatedNodesToModel(relatedNodes.([]interface{}), model)
+			err := mapRelatedNodesToModel(relatedNodes, model)
 			if err != nil {
 				return nil, err
 			}
@@ -72,13 +256,156 @@ func buildNodeTree[T any](records []neo4j.Record) ([]*T, error) {
 	return results, nil
 }
 
+// recordRelatedNodes returns the relatedNodes column of a record, nil when the record has none.
+func recordRelatedNodes(record neo4j.Record) ([]interface{}, error) {
+	value, _ := record.Get("relatedNodes")
+	if value == nil {
+		return nil, nil
+	}
+	relatedNodes, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected relatedNodes type: %T", value)
+	}
+	return relatedNodes, nil
+}
+
+/*
+MapRecords maps the node bound to n in each record, along with its related nodes, into out.
+It lets raw queries get the same struct results as the query builder.
+out must be a pointer to a slice of models or of model pointers, ie: *[]City or *[]*City.
+The mapped models are appended to the slice.
+
+Example usage:
+
+	result, err := session.Run(ctx, "MATCH (n:City) WHERE n.population > $min RETURN n", params)
+	if err != nil {
+		log.Fatal(err)
+	}
+	records, err := result.Collect(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var cities []City
+	err = MapRecords(records, &cities)
+*/
+func MapRecords(records []neo4j.Record, out interface{}) error {
+	return MapRecordsAs(records, defaultBinding, out)
+}
+
+/*
+MapRecordsAs is MapRecords for queries returning the node under another variable than n.
+
+Example usage:
+
+	result, err := session.Run(ctx, "MATCH (c:City)-[:HAS]->(:Location) RETURN DISTINCT c", nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	records, err := result.Collect(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var cities []City
+	err = MapRecordsAs(records, "c", &cities)
+*/
+func MapRecordsAs(records []neo4j.Record, binding string, out interface{}) error {
+	outValue := reflect.ValueOf(out)
+	if outValue.Kind() != reflect.Ptr || outValue.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("out must be a pointer to a slice, got %T", out)
+	}
+
+	slice := outValue.Elem()
+	elemType := slice.Type().Elem()
+	modelType := elemType
+	if modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+	if modelType.Kind() != reflect.Struct {
+		return fmt.Errorf("out must be a slice of structs or struct pointers, got %T", out)
+	}
+
+	for _, record := range records {
+		node, ok := record.Get(binding)
+		if !ok {
+			return fmt.Errorf("record is missing the expected %q binding, got %v", binding, record.Keys)
+		}
+
+		model := reflect.New(modelType)
+		if err := mapNodeToModelReflect(toNode(node), model.Interface()); err != nil {
+			return err
+		}
+
+		relatedNodes, err := recordRelatedNodes(record)
+		if err != nil {
+			return err
+		}
+		if relatedNodes != nil {
+			if err := mapRelatedNodes(relatedNodes, model.Elem()); err != nil {
+				return err
+			}
+		}
+
+		if elemType.Kind() == reflect.Ptr {
+			slice = reflect.Append(slice, model)
+		} else {
+			slice = reflect.Append(slice, model.Elem())
+		}
+	}
+
+	outValue.Elem().Set(slice)
+	return nil
+}
+
+// projectionElementID and projectionLabels are the keys under which projected queries
+// return the node's elementId and labels.
+const (
+	projectionElementID = "__elementId"
+	projectionLabels    = "__labels"
+)
+
+// setLabels copies labels onto the Labels field the model inherits from NeoBaseModel, if any.
+func setLabels(model interface{}, labels []string) {
+	field := reflect.ValueOf(model).Elem().FieldByName("Labels")
+	if field.IsValid() && field.CanSet() && field.Type() == reflect.TypeOf(labels) {
+		field.Set(reflect.ValueOf(labels))
+	}
+}
+
+// toNode normalizes a returned value into a neo4j.Node.
+// Projected queries return a map of the requested properties instead of a node,
+// so the map is wrapped into a node carrying the projected elementId.
+func toNode(value interface{}) neo4j.Node {
+	switch v := value.(type) {
+	case neo4j.Node:
+		return v
+	case map[string]interface{}:
+		elementID, _ := v[projectionElementID].(string)
+		var labels []string
+		if values, ok := v[projectionLabels].([]interface{}); ok {
+			for _, label := range values {
+				if label, ok := label.(string); ok {
+					labels = append(labels, label)
+				}
+			}
+		}
+		props := make(map[string]interface{}, len(v))
+		for key, prop := range v {
+			if key != projectionElementID && key != projectionLabels {
+				props[key] = prop
+			}
+		}
+		return neo4j.Node{ElementId: elementID, Labels: labels, Props: props}
+	}
+	return neo4j.Node{}
+}
+
 func mapNodeToModel[T any](node neo4j.Node, model *T) error {
 	modelValue := reflect.ValueOf(model).Elem()
 	modelType := reflect.TypeOf(*model)
 
 	for i := 0; i < modelType.NumField(); i++ {
 		field := modelType.Field(i)
-		nodeTag := field.Tag.Get("node")
+		nodeTag := nodeTagName(field)
 
 		if field.Name == "ID" && nodeTag == "id" {
 			fieldValue := modelValue.FieldByName(field.Name)
@@ -104,7 +431,7 @@ func mapNodeToModel[T any](node neo4j.Node, model *T) error {
 		fieldValue := modelValue.FieldByName(field.Name)
 		if fieldValue.IsValid() && fieldValue.CanSet() {
 			if ok {
-				fieldValue.Set(reflect.ValueOf(value))
+				setProperty(fieldValue, value)
 			} else {
 				fieldValue.Set(reflect.Zero(fieldValue.Type()))
 			}
@@ -115,8 +442,12 @@ func mapNodeToModel[T any](node neo4j.Node, model *T) error {
 }
 
 func mapRelatedNodesToModel[T any](relatedNodes []interface{}, model *T) error {
-	modelValue := reflect.ValueOf(model).Elem()
-	modelType := reflect.TypeOf(*model)
+	return mapRelatedNodes(relatedNodes, reflect.ValueOf(model).Elem())
+}
+
+// mapRelatedNodes fills the relationship fields of a model struct value with the related nodes, recursively.
+func mapRelatedNodes(relatedNodes []interface{}, modelValue reflect.Value) error {
+	modelType := modelValue.Type()
 
 	for i := 0; i < modelType.NumField(); i++ {
 		field := modelType.Field(i)
@@ -125,36 +456,104 @@ func mapRelatedNodesToModel[T any](relatedNodes []interface{}, model *T) error {
 			continue
 		}
 
-		fieldValue := modelValue.FieldByName(field.Name)
-		if fieldValue.Kind() == reflect.Slice {
-			slice := reflect.MakeSlice(field.Type, 0, len(relatedNodes))
+		fieldValue := modelValue.Field(i)
+		switch {
+		case fieldValue.Kind() == reflect.Slice:
+			related, err := relatedModels(field, field.Type.Elem().Elem(), relatedNodes)
+			if err != nil {
+				return err
+			}
 
-			for _, relatedNode := range relatedNodes {
-				node, ok := relatedNode.(neo4j.Node)
-				if !ok {
-					continue
-				}
+			slice := reflect.MakeSlice(field.Type, 0, len(related))
+			slice = reflect.Append(slice, related...)
+			fieldValue.Set(slice)
+		case fieldValue.Kind() == reflect.Ptr && field.Type.Elem().Kind() == reflect.Struct:
+			related, err := relatedModels(field, field.Type.Elem(), relatedNodes)
+			if err != nil {
+				return err
+			}
 
-				relatedType, err := resolveTypeFromLabels(node.Labels)
-				if err != nil {
-					return err
-				}
+			// A single relationship takes the first matching node.
+			if len(related) > 0 {
+				fieldValue.Set(related[0])
+			} else {
+				fieldValue.Set(reflect.Zero(field.Type))
+			}
+		}
+	}
 
-				expectedType := field.Type.Elem().Elem()
-				if relatedType != expectedType {
-					return fmt.Errorf("type mismatch: expected %v, got %v", expectedType, relatedType)
-				}
+	return nil
+}
 
-				relatedModel := reflect.New(relatedType).Interface()
-				mapNodeToModelReflect(node, relatedModel)
-				slice = reflect.Append(slice, reflect.ValueOf(relatedModel))
-			}
+// relatedModels maps the related nodes filling a relationship field into pointers to models of the expected type,
+// along with their own related nodes.
+func relatedModels(field reflect.StructField, expectedType reflect.Type, relatedNodes []interface{}) ([]reflect.Value, error) {
+	var models []reflect.Value
+	// A node related through several relationships is listed once per relationship, keep the first.
+	seen := make(map[string]bool)
+	for _, relatedNode := range relatedNodes {
+		entry, ok := toRelatedEntry(relatedNode)
+		if !ok || (entry.field != "" && entry.field != jsonName(field)) {
+			continue
+		}
+		if seen[entry.node.ElementId] {
+			continue
+		}
+		seen[entry.node.ElementId] = true
 
-			fieldValue.Set(slice)
+		relatedType, err := resolveTypeFromLabels(entry.node.Labels)
+		if err != nil {
+			return nil, err
+		}
+		if relatedType != expectedType {
+			return nil, fmt.Errorf("type mismatch: expected %v, got %v", expectedType, relatedType)
 		}
+
+		relatedModel := reflect.New(relatedType)
+		mapNodeToModelReflect(entry.node, relatedModel.Interface())
+		setRelProps(relatedModel.Interface(), entry.relProps)
+		if err := mapRelatedNodes(entry.children, relatedModel.Elem()); err != nil {
+			return nil, err
+		}
+		models = append(models, relatedModel)
 	}
+	return models, nil
+}
 
-	return nil
+// relatedEntry is an element of the relatedNodes column: the node, the properties of the relationship
+// it was reached through, the JSON name of the field it fills, and its own related nodes.
+type relatedEntry struct {
+	node     neo4j.Node
+	relProps map[string]interface{}
+	field    string
+	children []interface{}
+}
+
+// toRelatedEntry reads an element of the relatedNodes column. Elements are {node, rel, field, children} maps,
+// or plain nodes, which fill any field of their type.
+func toRelatedEntry(value interface{}) (relatedEntry, bool) {
+	switch v := value.(type) {
+	case neo4j.Node:
+		return relatedEntry{node: v}, true
+	case map[string]interface{}:
+		node, ok := v["node"].(neo4j.Node)
+		relProps, _ := v["rel"].(map[string]interface{})
+		field, _ := v["field"].(string)
+		children, _ := v["children"].([]interface{})
+		return relatedEntry{node: node, relProps: relProps, field: field, children: children}, ok
+	}
+	return relatedEntry{}, false
+}
+
+// setRelProps copies the relationship properties onto the rel:"props" field of a related model, if any.
+func setRelProps(model interface{}, relProps map[string]interface{}) {
+	modelValue := reflect.ValueOf(model).Elem()
+	modelType := modelValue.Type()
+	for i := 0; i < modelType.NumField(); i++ {
+		if modelType.Field(i).Tag.Get("rel") == relPropsTag && len(relProps) > 0 {
+			modelValue.Field(i).Set(reflect.ValueOf(relProps))
+		}
+	}
 }
 
 func resolveTypeFromLabels(labels []string) (reflect.Type, error) {
@@ -171,7 +570,17 @@ var modelRegistry = make(map[string]reflect.Type)
 /*
 RegisterModel registers a neo4j model type with a string name.
 This allows the mapping function to resolve the correct type based on the node's labels.
-The model must be a pointer to a struct.
+The model must be a pointer to a struct, and its rel tags must use one of the Rel* relationship types or one
+registered with RegisterRelType.
+A field tagged node:"id" holds the node's elementId, so it must be a string field named ID.
+
+Relationships are described by rel:"TYPE,DIRECTION[,LABEL]" tags, DIRECTION being -> or <-.
+LABEL is the label of the related nodes, which defaults to the name of the field's element type; set it to
+relate a model to the same type through several relationships, ie: rel:"CAPITAL_OF,<-,City" once
+RegisterRelType("CAPITAL_OF") has been called.
+A related model can also carry a map[string]interface{} field tagged rel:"props", which receives the properties
+of the relationship it was populated through. Set them when creating the relationship with CreateOptions.RelProps.
+Its node and json tags must also follow the configured TagStrategy (see SetTagStrategy).
 
 Example usage:
 
@@ -181,6 +590,12 @@ Example usage:
 		Books    []*Book `rel:"HAS,->"`
 	}
 
+	type Book struct {
+		ID       string                 `node:"id"`
+		Title    string                 `node:"title"`
+		RelProps map[string]interface{} `rel:"props"` // ie: {"since": 2020} for (:User)-[:HAS {since: 2020}]->(:Book)
+	}
+
 	RegisterModel("User", &User{})
 */
 func RegisterModel(modelName string, model interface{}) {
@@ -188,16 +603,153 @@ func RegisterModel(modelName string, model interface{}) {
 	if modelType.Kind() != reflect.Ptr {
 		panic(fmt.Sprintf("model %s must be a pointer to a struct", modelName))
 	}
+	if err := validateIDField(modelName, modelType.Elem()); err != nil {
+		panic(err.Error())
+	}
+	if err := validateRelTags(modelName, modelType.Elem()); err != nil {
+		panic(err.Error())
+	}
+	if err := validateTagStrategy(modelType.Elem(), tagStrategy); err != nil {
+		panic(err.Error())
+	}
+	if err := validateModelIdentifiers(modelName, modelType.Elem()); err != nil {
+		panic(err.Error())
+	}
 	modelRegistry[modelName] = modelType.Elem()
 }
 
+// validateIDField ensures the field tagged node:"id" can hold the node's elementId.
+func validateIDField(modelName string, modelType reflect.Type) error {
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		if nodeTagName(field) != "id" {
+			continue
+		}
+		if field.Name != "ID" || field.Type.Kind() != reflect.String {
+			return fmt.Errorf("model %s: field %s tagged node:\"id\" must be a string field named ID, got %s %s",
+				modelName, field.Name, field.Name, field.Type)
+		}
+	}
+	return nil
+}
+
+// setProperty sets a field to a property value returned by Neo4j. Numbers are converted between
+// compatible kinds, ie: the int64 Neo4j returns into an int field, temporal values into
+// time.Time fields, and lists into typed slices such as []string. The field is zeroed when the value cannot be assigned or converted,
+// including numbers the field cannot hold exactly, see convertNumber.
+func setProperty(fieldValue reflect.Value, value interface{}) {
+	v := reflect.ValueOf(value)
+	switch {
+	case !v.IsValid():
+		fieldValue.Set(reflect.Zero(fieldValue.Type()))
+	case fieldValue.Type() == reflect.TypeOf(time.Time{}):
+		t, _ := toTime(value)
+		fieldValue.Set(reflect.ValueOf(t))
+	case v.Type().AssignableTo(fieldValue.Type()):
+		fieldValue.Set(v)
+	case isNumber(v.Kind()) && isNumber(fieldValue.Kind()):
+		converted, ok := convertNumber(v, fieldValue.Type())
+		if !ok {
+			converted = reflect.Zero(fieldValue.Type())
+		}
+		fieldValue.Set(converted)
+	case v.Kind() == reflect.Slice && fieldValue.Kind() == reflect.Slice:
+		// Lists come back as []interface{}, fill a slice of the field's type element by element.
+		slice := reflect.MakeSlice(fieldValue.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			setProperty(slice.Index(i), v.Index(i).Interface())
+		}
+		fieldValue.Set(slice)
+	default:
+		fieldValue.Set(reflect.Zero(fieldValue.Type()))
+	}
+}
+
+// toTime converts the temporal values the driver returns for dates and datetimes into a time.Time.
+func toTime(value interface{}) (time.Time, bool) {
+	switch t := value.(type) {
+	case time.Time:
+		return t, true
+	case neo4j.LocalDateTime:
+		return t.Time(), true
+	case neo4j.Date:
+		return t.Time(), true
+	}
+	return time.Time{}, false
+}
+
+// convertNumber converts a number to another numeric type, reporting false instead of losing data:
+// a float with a fractional part, NaN or infinity into an integer, a negative value into an unsigned
+// integer, or a value out of the range of the target type. Integers into floats may round, as in Go.
+func convertNumber(v reflect.Value, target reflect.Type) (reflect.Value, bool) {
+	zero := reflect.Zero(target)
+	switch kind := v.Kind(); {
+	case isInt(kind):
+		i := v.Int()
+		switch {
+		case isInt(target.Kind()) && zero.OverflowInt(i):
+			return zero, false
+		case isUint(target.Kind()) && (i < 0 || zero.OverflowUint(uint64(i))):
+			return zero, false
+		}
+	case isUint(kind):
+		u := v.Uint()
+		switch {
+		case isInt(target.Kind()) && (u > math.MaxInt64 || zero.OverflowInt(int64(u))):
+			return zero, false
+		case isUint(target.Kind()) && zero.OverflowUint(u):
+			return zero, false
+		}
+	default:
+		f := v.Float()
+		switch {
+		case isInt(target.Kind()) && (f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 || zero.OverflowInt(int64(f))):
+			return zero, false
+		case isUint(target.Kind()) && (f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 || zero.OverflowUint(uint64(f))):
+			return zero, false
+		case !isInt(target.Kind()) && !isUint(target.Kind()) && zero.OverflowFloat(f):
+			return zero, false
+		}
+	}
+	return v.Convert(target), true
+}
+
+// isInt reports whether a kind is a signed integer.
+func isInt(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+// isUint reports whether a kind is an unsigned integer.
+func isUint(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// isNumber reports whether a kind is an integer or floating point number.
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
 func mapNodeToModelReflect(node neo4j.Node, model interface{}) error {
 	modelValue := reflect.ValueOf(model).Elem()
 	modelType := reflect.TypeOf(model).Elem()
 
 	for i := 0; i < modelType.NumField(); i++ {
 		field := modelType.Field(i)
-		nodeTag := field.Tag.Get("node")
+		nodeTag := nodeTagName(field)
 
 		if field.Name == "ID" && nodeTag == "id" {
 			fieldValue := modelValue.FieldByName(field.Name)
@@ -223,7 +775,7 @@ func mapNodeToModelReflect(node neo4j.Node, model interface{}) error {
 		fieldValue := modelValue.FieldByName(field.Name)
 		if fieldValue.IsValid() && fieldValue.CanSet() {
 			if ok {
-				fieldValue.Set(reflect.ValueOf(value))
+				setProperty(fieldValue, value)
 			} else {
 				fieldValue.Set(reflect.Zero(fieldValue.Type()))
 			}
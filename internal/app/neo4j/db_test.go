@@ -0,0 +1,683 @@
+package neo
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"api/internal/app/neo4j/neotest"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	neo4jconfig "github.com/neo4j/neo4j-go-driver/v5/neo4j/config"
+)
+
+func TestBuildNodeTreeBinding(t *testing.T) {
+	city := neotest.Node("4:db:4", []string{"City"}, map[string]any{"name": "Port Royal"})
+
+	tests := []struct {
+		name    string
+		records []neo4j.Record
+		binding string
+		want    []string
+		wantErr string
+	}{
+		{
+			name:    "default binding",
+			records: []neo4j.Record{*neotest.Record("n", city)},
+			binding: defaultBinding,
+			want:    []string{"Port Royal"},
+		},
+		{
+			name:    "custom binding",
+			records: []neo4j.Record{*neotest.Record("c", city)},
+			binding: "c",
+			want:    []string{"Port Royal"},
+		},
+		{
+			name:    "misaligned binding",
+			records: []neo4j.Record{*neotest.Record("c", city)},
+			binding: defaultBinding,
+			wantErr: `record is missing the expected "n" binding, got [c]`,
+		},
+		{
+			name:    "no records",
+			binding: defaultBinding,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cities, err := buildNodeTree[City](tt.records, tt.binding, false)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("buildNodeTree() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildNodeTree() error = %v", err)
+			}
+
+			var names []string
+			for _, city := range cities {
+				names = append(names, city.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("buildNodeTree() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestSharedDriver(t *testing.T) {
+	driver := useFakeDriver(t, func(query neotest.Query) neotest.Response {
+		node := neotest.Node(query.Params["elementID"].(string), nil, map[string]any{"name": "Atlantis"})
+		return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", node, "relatedNodes", []any{})}}
+	})
+
+	tests := []struct {
+		name string
+		find func(ctx context.Context) error
+	}{
+		{name: "world", find: func(ctx context.Context) error {
+			var world World
+			return world.Find(ctx, &world, "elementID", "4:db:1").Populate(PopulateOptions{})
+		}},
+		{name: "zone", find: func(ctx context.Context) error {
+			var zone Zone
+			return zone.Find(ctx, &zone, "elementID", "4:db:3").Populate(PopulateOptions{})
+		}},
+		{name: "city detached", find: func(ctx context.Context) error {
+			var city City
+			defer city.CloseDriver()
+			return city.Find(ctx, &city, "elementID", "4:db:4").Populate(PopulateOptions{})
+		}},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.find(context.Background()); err != nil {
+				t.Fatalf("Find() error = %v", err)
+			}
+			if driver.Sessions() != i+1 {
+				t.Errorf("shared driver opened %d sessions, want %d", driver.Sessions(), i+1)
+			}
+			if driver.Closed() {
+				t.Error("model closed the shared driver")
+			}
+		})
+	}
+
+	if err := CloseGlobalDriver(context.Background()); err != nil {
+		t.Fatalf("CloseGlobalDriver() error = %v", err)
+	}
+	if !driver.Closed() {
+		t.Error("CloseGlobalDriver() left the shared driver open")
+	}
+}
+
+func TestDriverConfigPool(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    DriverConfig
+		wantErr string
+	}{
+		{
+			name: "pool settings",
+			env: map[string]string{
+				"NEO4J_MAX_CONNECTION_POOL_SIZE":       "20",
+				"NEO4J_CONNECTION_ACQUISITION_TIMEOUT": "10s",
+				"NEO4J_MAX_CONNECTION_LIFETIME":        "30m",
+			},
+			want: DriverConfig{
+				MaxConnectionPoolSize:        20,
+				ConnectionAcquisitionTimeout: 10 * time.Second,
+				MaxConnectionLifetime:        30 * time.Minute,
+			},
+		},
+		{
+			name: "defaults",
+		},
+		{
+			name:    "invalid pool size",
+			env:     map[string]string{"NEO4J_MAX_CONNECTION_POOL_SIZE": "many"},
+			wantErr: "invalid NEO4J_MAX_CONNECTION_POOL_SIZE",
+		},
+		{
+			name:    "invalid lifetime",
+			env:     map[string]string{"NEO4J_MAX_CONNECTION_LIFETIME": "forever"},
+			wantErr: "invalid NEO4J_MAX_CONNECTION_LIFETIME",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"NEO4J_MAX_CONNECTION_POOL_SIZE", "NEO4J_CONNECTION_ACQUISITION_TIMEOUT", "NEO4J_MAX_CONNECTION_LIFETIME"} {
+				t.Setenv(name, tt.env[name])
+			}
+
+			config, err := driverConfigFromEnv()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("driverConfigFromEnv() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("driverConfigFromEnv() error = %v", err)
+			}
+			if config != tt.want {
+				t.Fatalf("driverConfigFromEnv() = %+v, want %+v", config, tt.want)
+			}
+
+			// Unset settings keep the driver defaults.
+			defaults := neo4jconfig.Config{MaxConnectionPoolSize: 100, ConnectionAcquisitionTimeout: time.Minute, MaxConnectionLifetime: time.Hour}
+			applied := defaults
+			config.apply(&applied)
+			want := defaults
+			if tt.want.MaxConnectionPoolSize > 0 {
+				want.MaxConnectionPoolSize = tt.want.MaxConnectionPoolSize
+				want.ConnectionAcquisitionTimeout = tt.want.ConnectionAcquisitionTimeout
+				want.MaxConnectionLifetime = tt.want.MaxConnectionLifetime
+			}
+			if !reflect.DeepEqual(applied, want) {
+				t.Errorf("apply() = %+v, want %+v", applied, want)
+			}
+		})
+	}
+}
+
+func TestRegisterModelIDField(t *testing.T) {
+	type IntegerID struct {
+		NeoBaseModel[IntegerID]
+		ID   int64  `node:"id" json:"id"`
+		Name string `node:"name" json:"name"`
+	}
+	type RenamedID struct {
+		NeoBaseModel[RenamedID]
+		Key  string `node:"id" json:"id"`
+		Name string `node:"name" json:"name"`
+	}
+	type StringID struct {
+		NeoBaseModel[StringID]
+		ID   string `node:"id" json:"id"`
+		Name string `node:"name" json:"name"`
+	}
+
+	tests := []struct {
+		name    string
+		model   any
+		wantErr string
+	}{
+		{name: "integer id", model: &IntegerID{}, wantErr: `field ID tagged node:"id" must be a string field named ID, got ID int64`},
+		{name: "renamed id", model: &RenamedID{}, wantErr: `field Key tagged node:"id" must be a string field named ID, got Key string`},
+		{name: "string id", model: &StringID{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			label := strings.ReplaceAll(tt.name, " ", "_")
+			t.Cleanup(func() { delete(modelRegistry, label) })
+
+			defer func() {
+				recovered := recover()
+				if tt.wantErr == "" {
+					if recovered != nil {
+						t.Errorf("RegisterModel() panicked: %v", recovered)
+					}
+					return
+				}
+				if message, _ := recovered.(string); !strings.Contains(message, tt.wantErr) {
+					t.Errorf("RegisterModel() panic = %v, want %q", recovered, tt.wantErr)
+				}
+				if _, ok := modelRegistry[label]; ok {
+					t.Error("RegisterModel() registered a model with an invalid ID field")
+				}
+			}()
+			RegisterModel(label, tt.model)
+		})
+	}
+}
+
+func TestMapSinglePointerRelationship(t *testing.T) {
+	type OceanWorld struct {
+		NeoBaseModel[OceanWorld]
+		ID        string   `node:"id" json:"id"`
+		Oceans    []*Ocean `rel:"HAS,->" json:"oceans"`
+		MainOcean *Ocean   `rel:"HAS,->" json:"mainOcean"`
+	}
+
+	entry := func(id string, label string, field string) map[string]any {
+		node := neotest.Node(id, []string{label}, map[string]any{"name": id})
+		return map[string]any{"node": node, "rel": map[string]any{}, "field": field, "children": []any{}}
+	}
+
+	tests := []struct {
+		name       string
+		related    []any
+		wantMain   string
+		wantOceans int
+		wantErr    string
+	}{
+		{
+			name:       "main ocean",
+			related:    []any{entry("4:db:5", "Ocean", "oceans"), entry("4:db:6", "Ocean", "oceans"), entry("4:db:6", "Ocean", "mainOcean")},
+			wantMain:   "4:db:6",
+			wantOceans: 2,
+		},
+		{
+			name:     "first of several",
+			related:  []any{entry("4:db:5", "Ocean", "mainOcean"), entry("4:db:6", "Ocean", "mainOcean")},
+			wantMain: "4:db:5",
+		},
+		{
+			name:       "no main ocean",
+			related:    []any{entry("4:db:5", "Ocean", "oceans")},
+			wantOceans: 1,
+		},
+		{
+			name:       "plain node fills every field of its type",
+			related:    []any{neotest.Node("4:db:5", []string{"Ocean"}, nil)},
+			wantMain:   "4:db:5",
+			wantOceans: 1,
+		},
+		{
+			name:    "type mismatch",
+			related: []any{entry("4:db:4", "City", "mainOcean")},
+			wantErr: "type mismatch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			world := OceanWorld{MainOcean: &Ocean{ID: "4:db:99"}}
+			err := mapRelatedNodesToModel(tt.related, &world)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("mapRelatedNodesToModel() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("mapRelatedNodesToModel() error = %v", err)
+			}
+
+			main := ""
+			if world.MainOcean != nil {
+				main = world.MainOcean.ID
+			}
+			if main != tt.wantMain {
+				t.Errorf("MainOcean = %q, want %q", main, tt.wantMain)
+			}
+			if len(world.Oceans) != tt.wantOceans {
+				t.Errorf("Oceans = %d, want %d", len(world.Oceans), tt.wantOceans)
+			}
+		})
+	}
+}
+
+func TestMapNumericProperties(t *testing.T) {
+	type Measures struct {
+		NeoBaseModel[Measures]
+		ID        string  `node:"id" json:"id"`
+		Count     int     `node:"count" json:"count"`
+		Level     int32   `node:"level" json:"level"`
+		Elevation float32 `node:"elevation" json:"elevation"`
+		Area      float64 `node:"area" json:"area"`
+		Rank      uint8   `node:"rank" json:"rank"`
+	}
+
+	tests := []struct {
+		name  string
+		props map[string]any
+		want  Measures
+	}{
+		{
+			name:  "integers into narrower kinds",
+			props: map[string]any{"count": int64(7), "level": int64(3), "rank": int64(2)},
+			want:  Measures{ID: "4:db:1", Count: 7, Level: 3, Rank: 2},
+		},
+		{
+			name:  "floats into float32",
+			props: map[string]any{"elevation": 812.5, "area": 1.25e6},
+			want:  Measures{ID: "4:db:1", Elevation: 812.5, Area: 1.25e6},
+		},
+		{
+			name:  "integer into float",
+			props: map[string]any{"area": int64(400)},
+			want:  Measures{ID: "4:db:1", Area: 400},
+		},
+		{
+			name:  "integral floats into integers",
+			props: map[string]any{"count": 12.0, "rank": 3.0},
+			want:  Measures{ID: "4:db:1", Count: 12, Rank: 3},
+		},
+		{
+			name:  "fractional floats not truncated",
+			props: map[string]any{"count": 12.7, "rank": 3.5},
+			want:  Measures{ID: "4:db:1"},
+		},
+		{
+			name:  "integers out of range",
+			props: map[string]any{"level": int64(math.MaxInt32) + 1, "rank": int64(256)},
+			want:  Measures{ID: "4:db:1"},
+		},
+		{
+			name:  "negative into unsigned",
+			props: map[string]any{"rank": int64(-1)},
+			want:  Measures{ID: "4:db:1"},
+		},
+		{
+			name:  "floats out of range",
+			props: map[string]any{"count": 1e19, "level": -3e9, "rank": -1.0, "elevation": 1e39},
+			want:  Measures{ID: "4:db:1"},
+		},
+		{
+			name:  "NaN and infinity into integers",
+			props: map[string]any{"count": math.NaN(), "level": math.Inf(1)},
+			want:  Measures{ID: "4:db:1"},
+		},
+		{
+			name:  "non numeric value zeroed",
+			props: map[string]any{"count": "seven", "area": true},
+			want:  Measures{ID: "4:db:1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := neotest.Node("4:db:1", []string{"Measures"}, tt.props)
+
+			// Both mapping helpers convert the same way.
+			var generic Measures
+			if err := mapNodeToModel(node, &generic); err != nil {
+				t.Fatalf("mapNodeToModel() error = %v", err)
+			}
+			reflected := Measures{Count: 99, Area: 99}
+			if err := mapNodeToModelReflect(node, &reflected); err != nil {
+				t.Fatalf("mapNodeToModelReflect() error = %v", err)
+			}
+			if !reflect.DeepEqual(generic, tt.want) {
+				t.Errorf("mapNodeToModel() = %+v, want %+v", generic, tt.want)
+			}
+			if !reflect.DeepEqual(reflected, tt.want) {
+				t.Errorf("mapNodeToModelReflect() = %+v, want %+v", reflected, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeProperties(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		createdAt time.Time
+		stored    any // the createdAt value as the driver returns it, when not the written one
+		want      time.Time
+	}{
+		{name: "round trip", createdAt: createdAt, want: createdAt},
+		{name: "zero time not written", want: time.Time{}},
+		{name: "local datetime", stored: neo4j.LocalDateTime(createdAt), want: createdAt},
+		{name: "date", stored: neo4j.Date(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)), want: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The fake stores the properties written by the create and returns them on the next read.
+			props := map[string]any{}
+			driver := useFakeDriver(t, func(query neotest.Query) neotest.Response {
+				if strings.HasPrefix(query.Cypher, "CREATE") {
+					for key, value := range query.Params {
+						props[key] = value
+					}
+					if tt.stored != nil {
+						props["createdAt"] = tt.stored
+					}
+				}
+				node := neotest.Node("4:db:1", []string{"World"}, props)
+				return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", node, "relatedNodes", []any{})}}
+			})
+
+			world := &World{Name: "Atlantis", CreatedAt: tt.createdAt}
+			if err := world.Create(context.Background(), world, CreateOptions{}); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			create := driver.Queries()[0]
+			if _, written := create.Params["createdAt"]; written == tt.createdAt.IsZero() {
+				t.Errorf("Create() ran %q with %v", create.Cypher, create.Params)
+			}
+
+			var found World
+			if err := found.Find(context.Background(), &found, "elementID", "4:db:1").Populate(PopulateOptions{}); err != nil {
+				t.Fatalf("Find() error = %v", err)
+			}
+			if !found.CreatedAt.Equal(tt.want) {
+				t.Errorf("Find() createdAt = %v, want %v", found.CreatedAt, tt.want)
+			}
+		})
+	}
+}
+
+func TestSliceProperties(t *testing.T) {
+	type Town struct {
+		NeoBaseModel[Town]
+		ID      string   `node:"id" json:"id"`
+		Name    string   `node:"name" json:"name"`
+		Aliases []string `node:"aliases" json:"aliases"`
+		Founded []int64  `node:"founded" json:"founded"`
+		Ratings []int    `node:"ratings" json:"ratings"`
+	}
+
+	tests := []struct {
+		name string
+		town Town
+		want Town
+	}{
+		{
+			name: "string slice",
+			town: Town{Name: "Port Royal", Aliases: []string{"Jamaica Port", "Wickedest City"}},
+			want: Town{ID: "4:db:1", Name: "Port Royal", Aliases: []string{"Jamaica Port", "Wickedest City"}},
+		},
+		{
+			name: "integer slices",
+			town: Town{Name: "Tortuga", Founded: []int64{1625, 1640}, Ratings: []int{4, 5}},
+			want: Town{ID: "4:db:1", Name: "Tortuga", Founded: []int64{1625, 1640}, Ratings: []int{4, 5}},
+		},
+		{
+			name: "empty slice",
+			town: Town{Name: "Nassau", Aliases: []string{}},
+			want: Town{ID: "4:db:1", Name: "Nassau", Aliases: []string{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The fake stores the written properties, returning lists as []interface{} of int64 as the driver does.
+			props := map[string]any{}
+			useFakeDriver(t, func(query neotest.Query) neotest.Response {
+				if strings.HasPrefix(query.Cypher, "CREATE") {
+					for key, value := range query.Params {
+						props[key] = driverValue(value)
+					}
+				}
+				node := neotest.Node("4:db:1", []string{"Town"}, props)
+				return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", node, "relatedNodes", []any{})}}
+			})
+
+			town := tt.town
+			if err := town.Create(context.Background(), &town, CreateOptions{}); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			var found Town
+			if err := found.Find(context.Background(), &found, "elementID", "4:db:1").Populate(PopulateOptions{}); err != nil {
+				t.Fatalf("Find() error = %v", err)
+			}
+			if found.ID != tt.want.ID || found.Name != tt.want.Name || !slices.Equal(found.Aliases, tt.want.Aliases) ||
+				!slices.Equal(found.Founded, tt.want.Founded) || !slices.Equal(found.Ratings, tt.want.Ratings) {
+				t.Errorf("Find() = %+v, want %+v", found, tt.want)
+			}
+		})
+	}
+}
+
+// driverValue converts a query parameter into the value the driver reads back: lists as []interface{}, integers as int64.
+func driverValue(value any) any {
+	v := reflect.ValueOf(value)
+	switch {
+	case !v.IsValid():
+		return nil
+	case v.Kind() == reflect.Slice:
+		list := make([]any, v.Len())
+		for i := range list {
+			list[i] = driverValue(v.Index(i).Interface())
+		}
+		return list
+	case v.CanInt():
+		return v.Int()
+	}
+	return value
+}
+
+func TestMapRecords(t *testing.T) {
+	zone := func(id string, related any) neo4j.Record {
+		node := neotest.Node(id, []string{"Zone"}, map[string]any{"name": id, "biome": "forest"})
+		return *neotest.Record("n", node, "relatedNodes", related)
+	}
+	aliased := func(binding string, id string) neo4j.Record {
+		return *neotest.Record(binding, neotest.Node(id, []string{"Zone"}, map[string]any{"name": id}))
+	}
+	city := map[string]any{
+		"node":     neotest.Node("4:db:4", []string{"City"}, map[string]any{"name": "Port Royal"}),
+		"rel":      map[string]any{},
+		"field":    "cities",
+		"children": []any{},
+	}
+
+	tests := []struct {
+		name       string
+		records    []neo4j.Record
+		binding    string
+		out        func() any
+		wantIDs    []string
+		wantCities []int
+		wantErr    string
+	}{
+		{
+			name:       "slice of models",
+			records:    []neo4j.Record{zone("4:db:2", []any{city}), zone("4:db:3", []any{})},
+			out:        func() any { return &[]Zone{} },
+			wantIDs:    []string{"4:db:2", "4:db:3"},
+			wantCities: []int{1, 0},
+		},
+		{
+			name:       "slice of model pointers",
+			records:    []neo4j.Record{zone("4:db:2", []any{city})},
+			out:        func() any { return &[]*Zone{} },
+			wantIDs:    []string{"4:db:2"},
+			wantCities: []int{1},
+		},
+		{
+			name:       "no relatedNodes column",
+			records:    []neo4j.Record{*neotest.Record("n", neotest.Node("4:db:2", []string{"Zone"}, nil))},
+			out:        func() any { return &[]Zone{} },
+			wantIDs:    []string{"4:db:2"},
+			wantCities: []int{0},
+		},
+		{
+			name:    "no records",
+			out:     func() any { return &[]Zone{} },
+			wantIDs: []string{},
+		},
+		{
+			name:    "malformed relatedNodes",
+			records: []neo4j.Record{zone("4:db:2", "not a list")},
+			out:     func() any { return &[]Zone{} },
+			wantErr: "unexpected relatedNodes type",
+		},
+		{
+			name:    "missing binding",
+			records: []neo4j.Record{aliased("m", "4:db:2")},
+			out:     func() any { return &[]Zone{} },
+			wantErr: `missing the expected "n" binding`,
+		},
+		{
+			name:       "other binding",
+			records:    []neo4j.Record{aliased("z", "4:db:2"), aliased("z", "4:db:3")},
+			binding:    "z",
+			out:        func() any { return &[]Zone{} },
+			wantIDs:    []string{"4:db:2", "4:db:3"},
+			wantCities: []int{0, 0},
+		},
+		{
+			name:    "other binding missing",
+			records: []neo4j.Record{zone("4:db:2", []any{})},
+			binding: "z",
+			out:     func() any { return &[]Zone{} },
+			wantErr: `missing the expected "z" binding`,
+		},
+		{
+			name:    "slice instead of a pointer",
+			out:     func() any { return []Zone{} },
+			wantErr: "must be a pointer to a slice",
+		},
+		{
+			name:    "pointer to a model",
+			out:     func() any { return &Zone{} },
+			wantErr: "must be a pointer to a slice",
+		},
+		{
+			name:    "slice of non-structs",
+			out:     func() any { return &[]string{} },
+			wantErr: "must be a slice of structs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := tt.out()
+			var err error
+			if tt.binding == "" {
+				err = MapRecords(tt.records, out)
+			} else {
+				err = MapRecordsAs(tt.records, tt.binding, out)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("MapRecords() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MapRecords() error = %v", err)
+			}
+
+			ids := []string{}
+			cities := []int{}
+			switch zones := out.(type) {
+			case *[]Zone:
+				for _, zone := range *zones {
+					ids = append(ids, zone.ID)
+					cities = append(cities, len(zone.Cities))
+				}
+			case *[]*Zone:
+				for _, zone := range *zones {
+					ids = append(ids, zone.ID)
+					cities = append(cities, len(zone.Cities))
+				}
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("MapRecords() ids = %v, want %v", ids, tt.wantIDs)
+			}
+			if tt.wantCities != nil && !slices.Equal(cities, tt.wantCities) {
+				t.Errorf("MapRecords() cities = %v, want %v", cities, tt.wantCities)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package neo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+)
+
+// ErrInvalidIdentifier is returned when a label, relationship type or field name cannot be safely
+// written into a query. Cypher does not accept them as parameters, so they are validated instead.
+var ErrInvalidIdentifier = errors.New("invalid identifier")
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validateIdentifiers ensures every name is a plain Cypher identifier: a letter or underscore
+// followed by letters, digits or underscores.
+func validateIdentifiers(names ...string) error {
+	for _, name := range names {
+		if !identifierPattern.MatchString(name) {
+			return fmt.Errorf("%w: %q", ErrInvalidIdentifier, name)
+		}
+	}
+	return nil
+}
+
+// validate ensures the identifiers of the relationship described by the options are safe to write into a query.
+func (o CreateOptions) validate() error {
+	if !o.describesRelated() {
+		return nil
+	}
+	if err := validateIdentifiers(o.Label, o.Field); err != nil {
+		return err
+	}
+	if o.RelDirection != "" {
+		return validateIdentifiers(o.Rel)
+	}
+	return nil
+}
+
+// validateModelIdentifiers ensures the model name, its node tags and the labels of its rel tags are
+// safe to write into a query, and that no node tag uses a property reserved for soft deletes.
+func validateModelIdentifiers(modelName string, modelType reflect.Type) error {
+	if err := validateIdentifiers(modelName); err != nil {
+		return fmt.Errorf("model %s: %w", modelName, err)
+	}
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		if name := nodeTagName(field); name != "" {
+			if err := validateIdentifiers(name); err != nil {
+				return fmt.Errorf("model %s field %s: %w", modelName, field.Name, err)
+			}
+			if name == deletedProperty || name == deletedAtProperty {
+				return fmt.Errorf("model %s field %s: node tag %q is reserved for soft deletes", modelName, field.Name, name)
+			}
+		}
+		if tag, ok := parseRelTag(field); ok {
+			if err := validateIdentifiers(tag.label); err != nil {
+				return fmt.Errorf("model %s field %s: %w", modelName, field.Name, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package neo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+/*
+IndexSpec describes an index, or a uniqueness constraint, the application expects on a label.
+  - @property Label: The node label the index is on.
+  - @property Properties: The indexed properties, in order.
+  - @property Unique: Whether a uniqueness constraint (or node key) is expected rather than a plain index.
+*/
+type IndexSpec struct {
+	Label      string
+	Properties []string
+	Unique     bool
+}
+
+// String describes the spec the way VerifySchema reports it missing, ie: UNIQUE :User(userID).
+func (s IndexSpec) String() string {
+	description := fmt.Sprintf(":%s(%s)", s.Label, strings.Join(s.Properties, ", "))
+	if s.Unique {
+		return "UNIQUE " + description
+	}
+	return description
+}
+
+/*
+@method VerifySchema
+
+@description Checks that the expected indexes and constraints exist in the database, using SHOW INDEXES and SHOW CONSTRAINTS.
+Indexes backing a constraint also satisfy a non-unique spec.
+
+@params specs []IndexSpec - The indexes and constraints the application expects.
+
+@returns ([]string, error) - The description of each missing index or constraint, and an error if the schema could not be read.
+
+@example
+
+	missing, err := neo.VerifySchema(ctx, []neo.IndexSpec{
+		{Label: "User", Properties: []string{"userID"}, Unique: true},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, spec := range missing {
+		log.Printf("missing index %s", spec)
+	}
+*/
+func VerifySchema(ctx context.Context, specs []IndexSpec) ([]string, error) {
+	driver, err := getDriver()
+	if err != nil {
+		return nil, err
+	}
+
+	session := driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+	defer session.Close(ctx)
+
+	indexes, err := showSchema(ctx, session, showIndexesQuery)
+	if err != nil {
+		return nil, err
+	}
+	constraints, err := showSchema(ctx, session, showConstraintsQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	return missingIndexes(specs, indexes, constraints), nil
+}
+
+// The SHOW queries read by VerifySchema. Their WHERE clause may only reference yielded columns.
+const (
+	showIndexesQuery     = "SHOW INDEXES YIELD labelsOrTypes, properties, entityType WHERE entityType = 'NODE' RETURN labelsOrTypes, properties"
+	showConstraintsQuery = "SHOW CONSTRAINTS YIELD labelsOrTypes, properties, type WHERE type IN ['UNIQUENESS', 'NODE_KEY'] RETURN labelsOrTypes, properties"
+)
+
+// showSchema runs a SHOW query and returns the key, as built by IndexSpec.String, of each returned index or constraint.
+func showSchema(ctx context.Context, session neo4j.SessionWithContext, query string) (map[string]bool, error) {
+	result, err := runRead(ctx, session, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		res, err := tx.Run(ctx, query, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		keys := make(map[string]bool)
+		for res.Next(ctx) {
+			record := res.Record()
+			labels, _ := record.Get("labelsOrTypes")
+			properties, _ := record.Get("properties")
+			for _, label := range toStrings(labels) {
+				keys[IndexSpec{Label: label, Properties: toStrings(properties)}.String()] = true
+			}
+		}
+		return keys, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(map[string]bool), nil
+}
+
+// missingIndexes returns the description of each spec found neither in the indexes nor in the constraints.
+func missingIndexes(specs []IndexSpec, indexes map[string]bool, constraints map[string]bool) []string {
+	var missing []string
+	for _, spec := range specs {
+		key := IndexSpec{Label: spec.Label, Properties: spec.Properties}.String()
+		if constraints[key] || (!spec.Unique && indexes[key]) {
+			continue
+		}
+		missing = append(missing, spec.String())
+	}
+	return missing
+}
+
+// toStrings converts a list returned by Neo4j into a slice of strings, skipping non-string values.
+func toStrings(value interface{}) []string {
+	list, _ := value.([]interface{})
+	values := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
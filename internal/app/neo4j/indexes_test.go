@@ -0,0 +1,127 @@
+package neo
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"api/internal/app/neo4j/neotest"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+func TestVerifySchema(t *testing.T) {
+	specs := []IndexSpec{
+		{Label: "User", Properties: []string{"userID"}, Unique: true},
+		{Label: "User", Properties: []string{"username"}},
+		{Label: "World", Properties: []string{"name"}},
+		{Label: "City", Properties: []string{"name", "type"}},
+	}
+	index := func(label string, properties ...any) *neo4j.Record {
+		return neotest.Record("labelsOrTypes", []any{label}, "properties", properties)
+	}
+	unavailable := errors.New("database unavailable")
+
+	tests := []struct {
+		name        string
+		indexes     []*neo4j.Record
+		constraints []*neo4j.Record
+		err         error
+		want        []string
+	}{
+		{
+			name:        "partial index set",
+			indexes:     []*neo4j.Record{index("World", "name")},
+			constraints: []*neo4j.Record{index("User", "userID")},
+			want:        []string{":User(username)", ":City(name, type)"},
+		},
+		{
+			name:    "index instead of constraint",
+			indexes: []*neo4j.Record{index("User", "userID"), index("User", "username"), index("World", "name"), index("City", "name", "type")},
+			want:    []string{"UNIQUE :User(userID)"},
+		},
+		{
+			name:        "constraint satisfies index",
+			indexes:     []*neo4j.Record{index("World", "name"), index("City", "name", "type")},
+			constraints: []*neo4j.Record{index("User", "userID"), index("User", "username")},
+		},
+		{
+			name:    "properties out of order",
+			indexes: []*neo4j.Record{index("User", "username"), index("World", "name"), index("City", "type", "name")},
+			want:    []string{"UNIQUE :User(userID)", ":City(name, type)"},
+		},
+		{
+			name: "schema unreadable",
+			err:  unavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useFakeDriver(t, func(query neotest.Query) neotest.Response {
+				if tt.err != nil {
+					return neotest.Response{Err: tt.err}
+				}
+				if query.AccessMode != neo4j.AccessModeRead {
+					t.Errorf("%q ran in a write session", query.Cypher)
+				}
+				return respondTo(map[string]neotest.Response{
+					"SHOW INDEXES":     {Records: tt.indexes},
+					"SHOW CONSTRAINTS": {Records: tt.constraints},
+				})(query)
+			})
+
+			missing, err := VerifySchema(context.Background(), specs)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("VerifySchema() error = %v, want %v", err, tt.err)
+			}
+			if !reflect.DeepEqual(missing, tt.want) {
+				t.Errorf("VerifySchema() = %q, want %q", missing, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifySchemaYield(t *testing.T) {
+	driver := useFakeDriver(t, func(neotest.Query) neotest.Response { return neotest.Response{} })
+	if _, err := VerifySchema(context.Background(), nil); err != nil {
+		t.Fatalf("VerifySchema() error = %v", err)
+	}
+
+	// Neo4j rejects a WHERE clause filtering on a column the YIELD does not list.
+	clause := regexp.MustCompile(`YIELD (.+) WHERE (.+) RETURN`)
+	column := regexp.MustCompile(`(\w+)\s*(?:=|<>|IN\b)`)
+
+	tests := []struct {
+		name   string
+		prefix string
+	}{
+		{name: "indexes", prefix: "SHOW INDEXES"},
+		{name: "constraints", prefix: "SHOW CONSTRAINTS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := slices.IndexFunc(driver.Queries(), func(q neotest.Query) bool { return strings.HasPrefix(q.Cypher, tt.prefix) })
+			if i < 0 {
+				t.Fatalf("VerifySchema() ran no %s query", tt.prefix)
+			}
+			query := driver.Queries()[i].Cypher
+
+			parts := clause.FindStringSubmatch(query)
+			if parts == nil {
+				t.Fatalf("VerifySchema() ran %q, want a YIELD ... WHERE ... RETURN query", query)
+			}
+			yielded := strings.Split(parts[1], ", ")
+			for _, match := range column.FindAllStringSubmatch(parts[2], -1) {
+				if !slices.Contains(yielded, match[1]) {
+					t.Errorf("VerifySchema() ran %q, WHERE references %q which is not yielded", query, match[1])
+				}
+			}
+		})
+	}
+}
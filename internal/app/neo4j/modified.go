@@ -0,0 +1,50 @@
+package neo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// updatedAtProperty is the internal property holding the time a node was last created or updated.
+const updatedAtProperty = "_updatedAt"
+
+// touchUpdatedAt is the SET item refreshing the updatedAtProperty of n, added to every write.
+const touchUpdatedAt = "n." + updatedAtProperty + " = datetime()"
+
+/*
+@method FindModifiedSince
+
+@description Find the nodes created or updated at or after a given time, for incremental sync.
+Every OGM write (Create, CreateMany, CreateTree, Update, UpdateAll, Upsert, Copy, soft Delete, Restore) records the time in the _updatedAt property.
+Nodes written before the property was introduced have none and are never returned.
+
+@params since time.Time - The cutoff time.
+
+@params options PopulateOptions - Options for populating the related nodes of each returned node.
+
+@returns ([]T, error) - The nodes modified since the cutoff, an empty slice when there are none.
+
+@example
+
+	worlds, err := dbWorld.FindModifiedSince(ctx, lastSync, PopulateOptions{Depth: 1})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(len(worlds), "worlds changed")
+*/
+func (b *NeoBaseModel[T]) FindModifiedSince(ctx context.Context, since time.Time, options PopulateOptions) ([]T, error) {
+	var models []T
+	query := b.FindAll(ctx, &models, "", nil)
+	// The internal property is not a node tag, so the condition is added directly rather than through Where.
+	query.conditions = append(query.conditions, condition{field: updatedAtProperty, operator: ">=", value: since})
+	err := query.Populate(options)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("failed to find nodes modified since %s: %w", since, err)
+	}
+	if models == nil {
+		models = []T{}
+	}
+	return models, nil
+}
@@ -0,0 +1,104 @@
+package neo
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"api/internal/app/neo4j/neotest"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+func TestFindModifiedSince(t *testing.T) {
+	cutoff := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	stored := []struct {
+		id        string
+		updatedAt time.Time
+	}{
+		{id: "4:db:1", updatedAt: cutoff.Add(-time.Hour)},
+		{id: "4:db:2", updatedAt: cutoff},
+		{id: "4:db:3", updatedAt: cutoff.Add(time.Hour)},
+	}
+
+	tests := []struct {
+		name  string
+		since time.Time
+		want  []string
+	}{
+		{name: "updated before and after the cutoff", since: cutoff, want: []string{"4:db:2", "4:db:3"}},
+		{name: "every node", since: cutoff.Add(-24 * time.Hour), want: []string{"4:db:1", "4:db:2", "4:db:3"}},
+		{name: "no node", since: cutoff.Add(24 * time.Hour), want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The fake filters the stored nodes on the _updatedAt condition as Neo4j would.
+			driver := useFakeDriver(t, func(query neotest.Query) neotest.Response {
+				since := query.Params["where0"].(time.Time)
+				var records []*neo4j.Record
+				for _, node := range stored {
+					if !node.updatedAt.Before(since) {
+						world := neotest.Node(node.id, []string{"World"}, map[string]any{"name": node.id, updatedAtProperty: node.updatedAt})
+						records = append(records, neotest.Record("n", world, "relatedNodes", []any{}))
+					}
+				}
+				return neotest.Response{Records: records}
+			})
+
+			worlds, err := new(World).FindModifiedSince(context.Background(), tt.since, PopulateOptions{})
+			if err != nil {
+				t.Fatalf("FindModifiedSince() error = %v", err)
+			}
+			ids := make([]string, len(worlds))
+			for i, world := range worlds {
+				ids[i] = world.ID
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("FindModifiedSince() = %v, want %v", ids, tt.want)
+			}
+
+			if query := driver.Queries()[0].Cypher; !strings.Contains(query, "n._updatedAt >= $where0") {
+				t.Errorf("FindModifiedSince() ran %q", query)
+			}
+		})
+	}
+}
+
+func TestWritesTouchUpdatedAt(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(ctx context.Context, world *World) error
+	}{
+		{name: "create", write: func(ctx context.Context, world *World) error { return world.Create(ctx, world, CreateOptions{}) }},
+		{name: "update", write: func(ctx context.Context, world *World) error { return world.Update(ctx, world, CreateOptions{}) }},
+		{name: "upsert", write: func(ctx context.Context, world *World) error {
+			return world.Upsert(ctx, world, "name", CreateOptions{})
+		}},
+		{name: "soft delete", write: func(ctx context.Context, world *World) error {
+			return world.Delete(ctx, world, "elementID", world.ID, DeleteOptions{SoftDelete: true})
+		}},
+		{name: "restore", write: func(ctx context.Context, world *World) error { return world.Restore(ctx, "elementID", world.ID) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, func(neotest.Query) neotest.Response {
+				node := neotest.Node("4:db:1", []string{"World"}, map[string]any{"name": "Atlantis"})
+				return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", node)}, NodesCreated: 1, PropertiesSet: 1}
+			})
+
+			world := &World{ID: "4:db:1", Name: "Atlantis"}
+			if err := tt.write(context.Background(), world); err != nil {
+				t.Fatalf("%s error = %v", tt.name, err)
+			}
+			// A write may first read the node; the write itself comes last.
+			queries := driver.Queries()
+			if query := queries[len(queries)-1].Cypher; !strings.Contains(query, touchUpdatedAt) {
+				t.Errorf("%s ran %q, want it to set %s", tt.name, query, updatedAtProperty)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package neo
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"api/internal/app/neo4j/neotest"
+)
+
+// The test models mirror the world hierarchy of the application models.
+
+type User struct {
+	NeoBaseModel[User]
+	ID       string   `node:"id" json:"id"`
+	Username string   `node:"username,trim" json:"username"`
+	UserID   int64    `node:"userID" json:"userID"`
+	Worlds   []*World `rel:"OWNS,->" json:"worlds"`
+}
+
+type World struct {
+	NeoBaseModel[World]
+	ID          string       `node:"id" json:"id"`
+	Name        string       `node:"name,trim" json:"name"`
+	Type        string       `node:"type" json:"type"`
+	Description string       `node:"description" json:"description"`
+	CreatedAt   time.Time    `node:"createdAt" json:"createdAt"`
+	Continents  []*Continent `rel:"HAS,->" json:"continents"`
+	Oceans      []*Ocean     `rel:"HAS,->" json:"oceans"`
+
+	Ownership map[string]interface{} `rel:"props" json:"ownership,omitempty"`
+}
+
+type Continent struct {
+	NeoBaseModel[Continent]
+	ID    string  `node:"id" json:"id"`
+	Name  string  `node:"name" json:"name"`
+	Zones []*Zone `rel:"HAS,->" json:"zones"`
+}
+
+type Ocean struct {
+	NeoBaseModel[Ocean]
+	ID   string `node:"id" json:"id"`
+	Name string `node:"name" json:"name"`
+}
+
+type Zone struct {
+	NeoBaseModel[Zone]
+	ID     string  `node:"id" json:"id"`
+	Name   string  `node:"name" json:"name"`
+	Biome  string  `node:"biome" json:"biome"`
+	Cities []*City `rel:"HAS,->" json:"cities"`
+}
+
+type City struct {
+	NeoBaseModel[City]
+	ID         string `node:"id" json:"id"`
+	Name       string `node:"name" json:"name"`
+	Type       string `node:"type" json:"type"`
+	Population int64  `node:"population" json:"population"`
+	Capital    bool   `node:"capital" json:"capital"`
+}
+
+func init() {
+	RegisterModel("User", &User{})
+	RegisterModel("World", &World{})
+	RegisterModel("Continent", &Continent{})
+	RegisterModel("Ocean", &Ocean{})
+	RegisterModel("Zone", &Zone{})
+	RegisterModel("City", &City{})
+}
+
+// useFakeDriver makes the models of the test run their queries against a fake driver answering with respond.
+func useFakeDriver(t *testing.T, respond neotest.Responder) *neotest.Driver {
+	t.Helper()
+	driver := neotest.NewDriver(respond)
+	SetDriver(driver)
+	t.Cleanup(func() { SetDriver(nil) })
+	return driver
+}
+
+// respondTo answers the queries containing a fragment with its response, and other queries with no records.
+func respondTo(responses map[string]neotest.Response) neotest.Responder {
+	return func(query neotest.Query) neotest.Response {
+		for fragment, response := range responses {
+			if strings.Contains(query.Cypher, fragment) {
+				return response
+			}
+		}
+		return neotest.Response{}
+	}
+}
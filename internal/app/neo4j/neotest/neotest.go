@@ -0,0 +1,270 @@
+// Package neotest provides an in-memory fake of the Neo4j driver, so code going through the neo package
+// can be tested without a database. Inject it with neo.SetDriver.
+//
+// The fake records every query it runs and answers it with the Response returned by its Responder.
+// Only the driver, session, transaction and result methods used by the neo package are implemented,
+// the others panic.
+package neotest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j/dbtype"
+)
+
+/*
+Query is a query run through the fake driver.
+  - @property Cypher: The query text.
+  - @property Params: The query parameters.
+  - @property AccessMode: The access mode of the session the query ran in.
+  - @property Session: The number of the session the query ran in, starting at 1.
+  - @property Context: The context the query was run with.
+*/
+type Query struct {
+	Cypher     string
+	Params     map[string]any
+	AccessMode neo4j.AccessMode
+	Session    int
+	Context    context.Context
+}
+
+/*
+Response is the answer of the fake driver to a query.
+  - @property Records: The records returned by the query.
+  - @property Err: The error returned by the query, ie: a transient error.
+  - @property NodesCreated, PropertiesSet: The counters of the query summary.
+*/
+type Response struct {
+	Records       []*neo4j.Record
+	Err           error
+	NodesCreated  int
+	PropertiesSet int
+}
+
+// Responder returns the Response of a query.
+type Responder func(query Query) Response
+
+/*
+Driver is a fake neo4j.DriverWithContext. The zero value answers every query with no records.
+*/
+type Driver struct {
+	neo4j.DriverWithContext
+	Respond Responder
+
+	mu           sync.Mutex
+	sessions     int
+	transactions int
+	queries      []Query
+	closed       bool
+}
+
+/*
+NewDriver creates a fake driver answering queries with respond.
+*/
+func NewDriver(respond Responder) *Driver {
+	return &Driver{Respond: respond}
+}
+
+// NewSession opens a fake session, counted by Sessions.
+func (d *Driver) NewSession(ctx context.Context, config neo4j.SessionConfig) neo4j.SessionWithContext {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sessions++
+	return &session{driver: d, number: d.sessions, accessMode: config.AccessMode}
+}
+
+// Close marks the driver as closed, see Closed.
+func (d *Driver) Close(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.closed = true
+	return nil
+}
+
+// Sessions returns the number of sessions opened so far.
+func (d *Driver) Sessions() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.sessions
+}
+
+// Transactions returns the number of managed transactions run so far, retries excluded.
+func (d *Driver) Transactions() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.transactions
+}
+
+// Queries returns the queries run so far, in order.
+func (d *Driver) Queries() []Query {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return slices.Clone(d.queries)
+}
+
+// Closed reports whether Close was called.
+func (d *Driver) Closed() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.closed
+}
+
+func (d *Driver) run(query Query) (neo4j.ResultWithContext, error) {
+	d.mu.Lock()
+	d.queries = append(d.queries, query)
+	respond := d.Respond
+	d.mu.Unlock()
+
+	var response Response
+	if respond != nil {
+		response = respond(query)
+	}
+	if response.Err != nil {
+		return nil, response.Err
+	}
+	return &result{records: response.Records, summary: &summary{counters: &counters{
+		nodesCreated:  response.NodesCreated,
+		propertiesSet: response.PropertiesSet,
+	}}}, nil
+}
+
+type session struct {
+	neo4j.SessionWithContext
+	driver     *Driver
+	number     int
+	accessMode neo4j.AccessMode
+}
+
+// ExecuteRead runs work once: the fake driver does not retry.
+func (s *session) ExecuteRead(ctx context.Context, work neo4j.ManagedTransactionWork, configurers ...func(*neo4j.TransactionConfig)) (any, error) {
+	return s.execute(ctx, work)
+}
+
+// ExecuteWrite runs work once: the fake driver does not retry.
+func (s *session) ExecuteWrite(ctx context.Context, work neo4j.ManagedTransactionWork, configurers ...func(*neo4j.TransactionConfig)) (any, error) {
+	return s.execute(ctx, work)
+}
+
+// execute runs work in a fake transaction, unless ctx is already done, as the driver does.
+func (s *session) execute(ctx context.Context, work neo4j.ManagedTransactionWork) (any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.driver.mu.Lock()
+	s.driver.transactions++
+	s.driver.mu.Unlock()
+	return work(&transaction{session: s})
+}
+
+func (s *session) Run(ctx context.Context, cypher string, params map[string]any, configurers ...func(*neo4j.TransactionConfig)) (neo4j.ResultWithContext, error) {
+	return s.driver.run(Query{Cypher: cypher, Params: params, AccessMode: s.accessMode, Session: s.number, Context: ctx})
+}
+
+func (s *session) Close(ctx context.Context) error {
+	return nil
+}
+
+type transaction struct {
+	neo4j.ManagedTransaction
+	session *session
+}
+
+func (tx *transaction) Run(ctx context.Context, cypher string, params map[string]any) (neo4j.ResultWithContext, error) {
+	return tx.session.Run(ctx, cypher, params)
+}
+
+type result struct {
+	neo4j.ResultWithContext
+	records []*neo4j.Record
+	current *neo4j.Record
+	summary *summary
+}
+
+func (r *result) Next(ctx context.Context) bool {
+	if len(r.records) == 0 {
+		r.current = nil
+		return false
+	}
+	r.current, r.records = r.records[0], r.records[1:]
+	return true
+}
+
+func (r *result) Record() *neo4j.Record {
+	return r.current
+}
+
+func (r *result) Err() error {
+	return nil
+}
+
+func (r *result) Collect(ctx context.Context) ([]*neo4j.Record, error) {
+	records := r.records
+	r.records = nil
+	return records, nil
+}
+
+func (r *result) Single(ctx context.Context) (*neo4j.Record, error) {
+	records, _ := r.Collect(ctx)
+	if len(records) != 1 {
+		return nil, fmt.Errorf("result contains %d records, expected exactly one", len(records))
+	}
+	return records[0], nil
+}
+
+func (r *result) Consume(ctx context.Context) (neo4j.ResultSummary, error) {
+	r.records = nil
+	return r.summary, nil
+}
+
+type summary struct {
+	neo4j.ResultSummary
+	counters *counters
+}
+
+func (s *summary) Counters() neo4j.Counters {
+	return s.counters
+}
+
+type counters struct {
+	neo4j.Counters
+	nodesCreated  int
+	propertiesSet int
+}
+
+func (c *counters) NodesCreated() int           { return c.nodesCreated }
+func (c *counters) NodesDeleted() int           { return 0 }
+func (c *counters) RelationshipsCreated() int   { return 0 }
+func (c *counters) RelationshipsDeleted() int   { return 0 }
+func (c *counters) PropertiesSet() int          { return c.propertiesSet }
+func (c *counters) ContainsUpdates() bool       { return c.nodesCreated > 0 || c.propertiesSet > 0 }
+func (c *counters) ContainsSystemUpdates() bool { return false }
+
+/*
+Record builds a record from alternating keys and values, ie: Record("n", node, "count", int64(2)).
+*/
+func Record(keysAndValues ...any) *neo4j.Record {
+	record := &neo4j.Record{}
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		record.Keys = append(record.Keys, keysAndValues[i].(string))
+		record.Values = append(record.Values, keysAndValues[i+1])
+	}
+	return record
+}
+
+/*
+Node builds a node with the given elementId, labels and properties.
+*/
+func Node(elementID string, labels []string, props map[string]any) neo4j.Node {
+	return dbtype.Node{ElementId: elementID, Labels: labels, Props: props}
+}
+
+/*
+Relationship builds a relationship of the given type from startID to endID, with the given elementId and properties.
+*/
+func Relationship(elementID, startID, endID, relType string, props map[string]any) neo4j.Relationship {
+	return dbtype.Relationship{ElementId: elementID, StartElementId: startID, EndElementId: endID, Type: relType, Props: props}
+}
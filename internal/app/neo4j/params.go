@@ -0,0 +1,32 @@
+package neo
+
+import "encoding/json"
+
+// queryParam converts the json.Number values of a query parameter, as decoded with json.Decoder.UseNumber,
+// into int64, or float64 when they are not integers, so an id decoded from JSON matches an integer property.
+// Maps and slices are converted recursively; other values are returned unchanged.
+func queryParam(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case map[string]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted[key] = queryParam(item)
+		}
+		return converted
+	case []interface{}:
+		converted := make([]interface{}, len(v))
+		for i, item := range v {
+			converted[i] = queryParam(item)
+		}
+		return converted
+	}
+	return value
+}
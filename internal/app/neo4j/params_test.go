@@ -0,0 +1,78 @@
+package neo
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"api/internal/app/neo4j/neotest"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+func TestQueryParam(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  any
+	}{
+		{name: "integer", value: json.Number("42"), want: int64(42)},
+		{name: "float", value: json.Number("4.5"), want: 4.5},
+		{name: "beyond int64", value: json.Number("1e30"), want: 1e30},
+		{name: "nested", value: map[string]any{"ids": []any{json.Number("1"), json.Number("2")}, "name": "x"}, want: map[string]any{"ids": []any{int64(1), int64(2)}, "name": "x"}},
+		{name: "other values unchanged", value: "42", want: "42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := queryParam(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("queryParam() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindByJSONInteger(t *testing.T) {
+	tests := []struct {
+		name      string
+		useNumber bool
+		wantFound bool
+	}{
+		{name: "decoded as a number", useNumber: true, wantFound: true},
+		// Without UseNumber, 42 decodes as a float64 and reaches the query as one.
+		{name: "decoded as a float", wantFound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The fake stores a user whose userID is the integer 42, and only matches an int64 parameter.
+			useFakeDriver(t, func(query neotest.Query) neotest.Response {
+				if query.Params["userID"] != int64(42) {
+					return neotest.Response{}
+				}
+				user := neotest.Node("4:db:10", []string{"User"}, map[string]any{"username": "alice", "userID": int64(42)})
+				return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", user, "relatedNodes", []any{})}}
+			})
+
+			var body map[string]any
+			decoder := json.NewDecoder(strings.NewReader(`{"userID": 42}`))
+			if tt.useNumber {
+				decoder.UseNumber()
+			}
+			if err := decoder.Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+
+			var user User
+			err := user.Find(context.Background(), &user, "userID", body["userID"]).Populate(PopulateOptions{})
+			if found := err == nil; found != tt.wantFound {
+				t.Fatalf("Find() error = %v, want found %v", err, tt.wantFound)
+			}
+			if tt.wantFound && user.UserID != 42 {
+				t.Errorf("Find() userID = %d, want 42", user.UserID)
+			}
+		})
+	}
+}
@@ -2,25 +2,179 @@ package neo
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"reflect"
+	"slices"
 	"strings"
 
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 )
 
 type PopulateOptions struct {
-	Depth int
-	Limit int
+	Depth  int // levels of relationships to populate: 0 for the node only, Unbounded for as deep as MaxPopulateDepth allows
+	Skip   int // number of root nodes to skip, for paging through FindAll results
+	Limit  int
+	Fields []string // node properties to return; all properties are returned when empty
+
+	IncludeLabels bool // fill the model's Labels field with the node's Neo4j labels
+
+	IncludeDeleted bool // also return nodes soft-deleted with DeleteOptions.SoftDelete, which are excluded by default
+
+	Pages map[string]Page // pages of relationship collections, keyed by the JSON name of the relationship field
+}
+
+// Unbounded is the PopulateOptions.Depth populating every relationship, as deep as MaxPopulateDepth allows.
+const Unbounded = -1
+
+// MaxPopulateDepth is the deepest populate allowed. Populate clamps deeper requests to it, logging them,
+// and Unbounded ones silently, so controllers can pass client-provided depths as is.
+// A value of 0 disables the limit; Unbounded populates then stop at relationships leading back to a model
+// type already populated on the way down, so cyclic model graphs still yield a finite query.
+var MaxPopulateDepth = 5
+
+// queryLogger receives the populate queries before they run, see SetQueryLogger.
+var queryLogger = func(query string, params map[string]interface{}) {}
+
+/*
+SetQueryLogger sets the function called with each populate query and its parameters before it runs.
+Queries are not logged by default. Set it once at startup, before serving requests; nil disables logging again.
+
+Example usage:
+
+	neo.SetQueryLogger(func(query string, params map[string]interface{}) {
+		slog.Debug("neo4j query", "query", query, "params", params)
+	})
+*/
+func SetQueryLogger(logger func(query string, params map[string]interface{})) {
+	if logger == nil {
+		logger = func(query string, params map[string]interface{}) {}
+	}
+	queryLogger = logger
+}
+
+// Page selects a window of a relationship collection. Page is 1-based.
+type Page struct {
+	Page     int
+	PageSize int
 }
 
+// ErrInvalidPage is returned by Populate when PopulateOptions.Pages names an unknown
+// relationship field or holds a non-positive page or page size.
+var ErrInvalidPage = errors.New("invalid page")
+
 type PopulateQuery[T any] struct {
-	baseModel *NeoBaseModel[T]
-	model     *T
-	models    *[]T
-	field     string
-	value     interface{}
-	options   PopulateOptions
+	ctx        context.Context
+	baseModel  *NeoBaseModel[T]
+	model      *T
+	models     *[]T
+	field      string
+	value      interface{}
+	options    PopulateOptions
+	orderBy    []string
+	conditions []condition
+	err        error
+	executed   bool
+}
+
+// ErrQueryExecuted is returned when Populate or PopulateIDs is called again on the same query.
+// A PopulateQuery runs once; start a new one with Find or FindAll instead.
+var ErrQueryExecuted = errors.New("query already executed")
+
+// condition is a comparison of a node property added to the match by Where.
+type condition struct {
+	field    string
+	operator string
+	value    interface{}
+}
+
+// operators are the comparison operators accepted by Where.
+var operators = map[string]bool{
+	"=": true, "<>": true, ">": true, ">=": true, "<": true, "<=": true,
+	"CONTAINS": true, "STARTS WITH": true, "ENDS WITH": true, "IN": true,
+}
+
+// @method Where
+//
+// @description Adds a condition on a node property to the match. Conditions are combined with AND.
+// The field must be a node tag of the model, or id (alias elementID) to compare the elementId.
+// An unknown field or operator makes Populate return an error.
+//
+// @param field string - The node tag to compare.
+//
+// @param operator string - One of =, <>, >, >=, <, <=, CONTAINS, STARTS WITH, ENDS WITH, IN.
+//
+// @param value interface{} - The value to compare to, a slice for IN.
+//
+// @return *PopulateQuery[T]
+//
+// @example
+//
+//	var cities []City
+//	err := dbCity.FindAll(ctx, &cities, "capital", true).
+//		Where("name", "STARTS WITH", "Port").
+//		Populate(PopulateOptions{})
+func (q *PopulateQuery[T]) Where(field string, operator string, value interface{}) *PopulateQuery[T] {
+	if q.err != nil {
+		return q
+	}
+	operator = strings.ToUpper(operator)
+	if !operators[operator] {
+		q.err = fmt.Errorf("invalid operator %q", operator)
+		return q
+	}
+	if field != "elementID" {
+		if err := validateFields(reflect.TypeOf(*new(T)), []string{field}); err != nil {
+			q.err = err
+			return q
+		}
+	}
+
+	q.conditions = append(q.conditions, condition{field: field, operator: operator, value: value})
+	return q
+}
+
+// Direction is the sort direction of an OrderBy clause.
+type Direction string
+
+const (
+	Ascending  Direction = "ASC"
+	Descending Direction = "DESC"
+)
+
+// @method OrderBy
+//
+// @description Sorts the returned nodes by a node property. Calls chain, the first one being the primary sort.
+// The field must be a node tag of the model, otherwise Populate returns an error.
+//
+// @param field string - The node tag to sort by, or id to sort by elementId.
+//
+// @param direction Direction - Ascending or Descending.
+//
+// @return *PopulateQuery[T]
+//
+// @example
+//
+//	var cities []City
+//	err := dbCity.FindAll(ctx, &cities, "type", "port").
+//		OrderBy("name", neo.Ascending).
+//		Populate(PopulateOptions{})
+func (q *PopulateQuery[T]) OrderBy(field string, direction Direction) *PopulateQuery[T] {
+	if q.err != nil {
+		return q
+	}
+	if direction != Ascending && direction != Descending {
+		q.err = fmt.Errorf("invalid sort direction %q", direction)
+		return q
+	}
+	if err := validateFields(reflect.TypeOf(*new(T)), []string{field}); err != nil {
+		q.err = err
+		return q
+	}
+
+	q.orderBy = append(q.orderBy, fmt.Sprintf("%s %s", propertyExpression(field), direction))
+	return q
 }
 
 // @method Populate
@@ -36,13 +190,28 @@ type PopulateQuery[T any] struct {
 //	// Populate a single model
 //	var user User
 //	user := User{}
-//	err := user.Find(&user, "userID", 123).Populate(PopulateOptions{Depth: 2})
+//	err := user.Find(ctx, &user, "userID", 123).Populate(PopulateOptions{Depth: 2})
 //	if err != nil {
 //		log.Fatal(err)
 //	}
 //	fmt.Println(user)
 func (q *PopulateQuery[T]) Populate(options PopulateOptions) error {
+	if q.executed {
+		return ErrQueryExecuted
+	}
+	q.executed = true
+
+	if q.err != nil {
+		return q.err
+	}
 	q.options = options
+	q.clampDepth()
+	if err := validateFields(reflect.TypeOf(*new(T)), options.Fields); err != nil {
+		return err
+	}
+	if err := q.validatePages(); err != nil {
+		return err
+	}
 	if q.model != nil {
 		return q.executeSingle()
 	}
@@ -52,18 +221,28 @@ func (q *PopulateQuery[T]) Populate(options PopulateOptions) error {
 	return fmt.Errorf("no model or models provided")
 }
 
+// clampDepth limits the requested depth to MaxPopulateDepth.
+func (q *PopulateQuery[T]) clampDepth() {
+	if q.options.Depth == 0 || MaxPopulateDepth <= 0 || (q.options.Depth > 0 && q.options.Depth <= MaxPopulateDepth) {
+		return
+	}
+	if q.options.Depth > 0 {
+		log.Printf("populate depth %d of %s clamped to %d", q.options.Depth, reflect.TypeOf(*new(T)).Name(), MaxPopulateDepth)
+	}
+	q.options.Depth = MaxPopulateDepth
+}
+
 func (q *PopulateQuery[T]) executeSingle() error {
 	if err := q.baseModel.initDriver(); err != nil {
 		return err
 	}
 
-	ctx := context.Background()
+	ctx := q.ctx
 	session := q.baseModel.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
 	defer session.Close(ctx)
-	defer q.baseModel.driver.Close(ctx)
 
 	query, params := q.buildQuery()
-	records, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+	records, err := runRead(ctx, session, func(tx neo4j.ManagedTransaction) (interface{}, error) {
 		res, err := tx.Run(ctx, query, params)
 		if err != nil {
 			return nil, err
@@ -89,13 +268,13 @@ func (q *PopulateQuery[T]) executeSingle() error {
 		return fmt.Errorf("failed to convert result to []neo4j.Record")
 	}
 
-	mappedNodes, err := buildNodeTree[T](recordList)
+	mappedNodes, err := buildNodeTree[T](recordList, defaultBinding, q.options.IncludeLabels)
 	if err != nil {
 		return err
 	}
 
 	if len(mappedNodes) == 0 {
-		return fmt.Errorf("no nodes found")
+		return ErrNotFound
 	}
 
 	*q.model = *mappedNodes[0]
@@ -107,13 +286,12 @@ func (q *PopulateQuery[T]) executeMultiple() error {
 		return err
 	}
 
-	ctx := context.Background()
+	ctx := q.ctx
 	session := q.baseModel.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
 	defer session.Close(ctx)
-	defer q.baseModel.driver.Close(ctx)
 
 	query, params := q.buildQuery()
-	records, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+	records, err := runRead(ctx, session, func(tx neo4j.ManagedTransaction) (interface{}, error) {
 		res, err := tx.Run(ctx, query, params)
 		if err != nil {
 			return nil, err
@@ -139,13 +317,13 @@ func (q *PopulateQuery[T]) executeMultiple() error {
 		return fmt.Errorf("failed to convert result to []neo4j.Record")
 	}
 
-	mappedNodes, err := buildNodeTree[T](recordList)
+	mappedNodes, err := buildNodeTree[T](recordList, defaultBinding, q.options.IncludeLabels)
 	if err != nil {
 		return err
 	}
 
 	if len(mappedNodes) == 0 {
-		return fmt.Errorf("no nodes found")
+		return ErrNotFound
 	}
 
 	*q.models = make([]T, len(mappedNodes))
@@ -160,73 +338,324 @@ func (q *PopulateQuery[T]) buildQuery() (string, map[string]interface{}) {
 		panic("baseModel.Label is not set. Ensure the model's Label field is initialized.")
 	}
 
-	query := fmt.Sprintf("MATCH (n:%s {%s: $%s})", q.baseModel.Label, q.field, q.field)
-	if q.field == "elementID" {
-		query = fmt.Sprintf("MATCH (n:%s) WHERE elementId(n) = $%s", q.baseModel.Label, q.field)
-	}
-	relationships := q.buildRelationships(reflect.TypeOf(*q.model), q.options.Depth)
-	for _, rel := range relationships {
-		query += fmt.Sprintf(" OPTIONAL MATCH %s", rel)
+	query := q.buildMatch()
+
+	// Sort before projecting, so fields left out of PopulateOptions.Fields can still be sorted on.
+	if len(q.orderBy) > 0 {
+		query += fmt.Sprintf(" WITH n ORDER BY %s", strings.Join(q.orderBy, ", "))
 	}
 
+	modelType := reflect.TypeOf(*new(T))
+	relatedNodes := q.buildChildren(modelType, "n", q.options.Depth, 1, []reflect.Type{modelType})
+	query += fmt.Sprintf(" RETURN %s, %s AS relatedNodes", q.buildProjection(), relatedNodes)
+
+	params := q.matchParams()
+
+	// Paging applies to the returned root nodes, once their related nodes are collected.
+	if q.options.Skip > 0 {
+		query += " SKIP $skip"
+		params["skip"] = q.options.Skip
+	}
 	if q.options.Limit > 0 {
-		query += fmt.Sprintf(" LIMIT %d", q.options.Limit)
+		query += " LIMIT $limit"
+		params["limit"] = q.options.Limit
 	}
 
-	query += " RETURN n, collect(r) as relatedNodes"
+	queryLogger(query, params)
+
+	return query, params
+}
 
-	params := map[string]interface{}{
-		q.field: q.value,
+// buildMatch returns the clause matching the root node by the query's field and value.
+func (q *PopulateQuery[T]) buildMatch() string {
+	var conditions []string
+	if q.field != "" {
+		conditions = append(conditions, fmt.Sprintf("%s = $%s", propertyExpression(q.field), q.field))
+	}
+	for i, condition := range q.conditions {
+		conditions = append(conditions, fmt.Sprintf("%s %s $where%d", propertyExpression(condition.field), condition.operator, i))
+	}
+	if !q.options.IncludeDeleted {
+		conditions = append(conditions, notDeleted("n"))
 	}
 
-	fmt.Printf("Query -> Neo4j: %s\n", query)
+	query := fmt.Sprintf("MATCH (n:%s)", q.baseModel.Label)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	return query
+}
 
-	return query, params
+// matchParams returns the parameters of the clause built by buildMatch.
+func (q *PopulateQuery[T]) matchParams() map[string]interface{} {
+	params := make(map[string]interface{})
+	if q.field != "" {
+		params[q.field] = queryParam(q.value)
+	}
+	for i, condition := range q.conditions {
+		params[fmt.Sprintf("where%d", i)] = queryParam(condition.value)
+	}
+	return params
+}
+
+// propertyExpression returns the expression reading a field of n, the elementId for id fields.
+func propertyExpression(field string) string {
+	if isElementIDField(field) {
+		return "elementId(n)"
+	}
+	return "n." + field
+}
+
+// buildChildren returns the expression listing, for each relationship field of modelType, the nodes related to
+// the node bound to from, each as a {node, rel, field, children} map: the node, the properties of its relationship,
+// the JSON name of the field it fills and, while depth allows, its own children, so the whole tree is returned
+// nested under the root. Each field is listed by its own COLLECT subquery (Neo4j 5.6+) so it can be paged on
+// its own; a paged field is ordered by elementId first so its pages are stable.
+// A depth of 0 lists no related nodes, a negative depth lists them all, see expands; path holds the model types
+// from the root down to modelType.
+func (q *PopulateQuery[T]) buildChildren(modelType reflect.Type, from string, depth int, level int, path []reflect.Type) string {
+	if depth == 0 {
+		return "[]"
+	}
+
+	var collections []string
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		tag, ok := parseRelTag(field)
+		if !ok {
+			continue
+		}
+
+		name := jsonName(field)
+		edge := fmt.Sprintf("e%d", level)
+		to := fmt.Sprintf("r%d", level)
+
+		children := "[]"
+		if related := relatedType(field.Type); expands(depth, related, path) {
+			children = q.buildChildren(related, to, depth-1, level+1, append(slices.Clip(path), related))
+		}
+
+		subquery := "MATCH " + tag.between(from, edge, to)
+		if !q.options.IncludeDeleted {
+			subquery += " WHERE " + notDeleted(to)
+		}
+		if page, ok := q.options.Pages[name]; ok {
+			subquery += fmt.Sprintf(" WITH %s, %s ORDER BY elementId(%s) SKIP %d LIMIT %d",
+				to, edge, to, (page.Page-1)*page.PageSize, page.PageSize)
+		}
+		subquery += fmt.Sprintf(" RETURN {node: %s, rel: properties(%s), field: %q, children: %s}", to, edge, name, children)
+		collections = append(collections, "COLLECT { "+subquery+" }")
+	}
+
+	if len(collections) == 0 {
+		return "[]"
+	}
+	return strings.Join(collections, " + ")
 }
 
-func (q *PopulateQuery[T]) buildRelationships(modelType reflect.Type, depth int) []string {
+// validatePages ensures every page targets a relationship field the query populates.
+func (q *PopulateQuery[T]) validatePages() error {
+	if len(q.options.Pages) == 0 {
+		return nil
+	}
+
+	fields := make(map[string]bool)
+	modelType := reflect.TypeOf(*new(T))
+	for _, field := range relationshipFields(modelType, q.options.Depth, []reflect.Type{modelType}) {
+		fields[field] = true
+	}
+
+	for field, page := range q.options.Pages {
+		if !fields[field] {
+			return fmt.Errorf("%w: unknown relationship %q", ErrInvalidPage, field)
+		}
+		if page.Page < 1 || page.PageSize < 1 {
+			return fmt.Errorf("%w: page and page size of %q must be positive", ErrInvalidPage, field)
+		}
+	}
+	return nil
+}
+
+// relationshipFields returns the JSON names of the relationship fields populated down to depth,
+// following the same rules as buildChildren.
+func relationshipFields(modelType reflect.Type, depth int, path []reflect.Type) []string {
 	if depth == 0 {
-		depth = -1
+		return nil
 	}
 
-	var paths []string
+	var fields []string
 	for i := 0; i < modelType.NumField(); i++ {
 		field := modelType.Field(i)
-		relTag := field.Tag.Get("rel")
-		if relTag == "" {
+		if _, ok := parseRelTag(field); !ok {
 			continue
 		}
 
-		tagParts := strings.Split(relTag, ",")
-		if len(tagParts) != 2 {
+		fields = append(fields, jsonName(field))
+		if related := relatedType(field.Type); expands(depth, related, path) {
+			fields = append(fields, relationshipFields(related, depth-1, append(slices.Clip(path), related))...)
+		}
+	}
+	return fields
+}
+
+// expands reports whether the related nodes of a relationship, reached at the given depth, get their own children.
+// A positive depth counts down to 1, the last level. A negative depth is Unbounded: it never counts down to a stop,
+// so it stops at a related type already on the path from the root instead, which would otherwise recurse forever.
+func expands(depth int, related reflect.Type, path []reflect.Type) bool {
+	if depth < 0 {
+		return !slices.Contains(path, related)
+	}
+	return depth > 1
+}
+
+// buildProjection returns the expression used to return the root node.
+// When PopulateOptions.Fields is set, only the requested properties are returned
+// alongside the node's elementId, which is always needed to fill the ID field.
+func (q *PopulateQuery[T]) buildProjection() string {
+	if len(q.options.Fields) == 0 {
+		return "n"
+	}
+
+	var properties []string
+	for _, field := range q.options.Fields {
+		if field == "id" {
 			continue
 		}
-		relType := tagParts[0]
-		relDirection := tagParts[1]
+		properties = append(properties, "."+field)
+	}
+	properties = append(properties, projectionElementID+": elementId(n)", projectionLabels+": labels(n)")
+
+	return fmt.Sprintf("n {%s} AS n", strings.Join(properties, ", "))
+}
+
+// validateFields ensures every requested field is a node tag of the model type.
+func validateFields(modelType reflect.Type, fields []string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	tags := make(map[string]bool)
+	for i := 0; i < modelType.NumField(); i++ {
+		if nodeTag := nodeTagName(modelType.Field(i)); nodeTag != "" {
+			tags[nodeTag] = true
+		}
+	}
 
-		relatedNodeLabel := ""
-		if field.Type.Kind() == reflect.Ptr {
-			relatedNodeLabel = field.Type.Elem().Name()
-		} else if field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Ptr {
-			relatedNodeLabel = field.Type.Elem().Elem().Name()
-		} else {
-			relatedNodeLabel = field.Type.Name()
+	for _, field := range fields {
+		if !tags[field] {
+			return fmt.Errorf("unknown field %q for %s", field, modelType.Name())
 		}
+	}
+	return nil
+}
 
-		path := fmt.Sprintf("(n)-[:%s]%s(r:%s)", relType, relDirection, relatedNodeLabel)
-		paths = append(paths, path)
+// @method PopulateIDs
+//
+// @description Populates a single model without its related nodes, and returns the elementIds of
+// the directly related nodes instead, keyed by the JSON name of each relationship field.
+// This is meant for lazy loading, where clients fetch related nodes on demand.
+//
+// @return (map[string][]string, error)
+//
+// @example
+//
+//	var world World
+//	ids, err := world.Find(ctx, &world, "elementID", id).PopulateIDs()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Println(ids["continents"])
+func (q *PopulateQuery[T]) PopulateIDs() (map[string][]string, error) {
+	if q.executed {
+		return nil, ErrQueryExecuted
+	}
+	q.executed = true
+
+	// The field, conditions and sort are interpolated into the query, so they must have passed validation.
+	if q.err != nil {
+		return nil, q.err
+	}
+	if q.model == nil {
+		return nil, fmt.Errorf("no model provided")
+	}
+	if err := q.baseModel.initDriver(); err != nil {
+		return nil, err
+	}
+
+	ctx := q.ctx
+	session := q.baseModel.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+	defer session.Close(ctx)
+
+	query, fields := q.buildIDsQuery()
+	params := q.matchParams()
+
+	result, err := runRead(ctx, session, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		res, err := tx.Run(ctx, query, params)
+		if err != nil {
+			return nil, err
+		}
+		if res.Next(ctx) {
+			return *res.Record(), nil
+		}
+		if err := res.Err(); err != nil {
+			return nil, err
+		}
+		return nil, ErrNotFound
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	record, ok := result.(neo4j.Record)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type: %T", result)
+	}
+
+	node, _ := record.Get(defaultBinding)
+	if err := mapNodeToModel(toNode(node), q.model); err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string][]string, len(fields))
+	for i, field := range fields {
+		values, _ := record.Get(fmt.Sprintf("ids%d", i))
+		list, _ := values.([]interface{})
+		ids[field] = make([]string, 0, len(list))
+		for _, value := range list {
+			if id, ok := value.(string); ok {
+				ids[field] = append(ids[field], id)
+			}
+		}
+	}
+	return ids, nil
+}
+
+// buildIDsQuery builds a query collecting the elementIds of each relationship field separately.
+// It returns the query and the JSON names of the relationship fields, in the order of the idsN columns.
+func (q *PopulateQuery[T]) buildIDsQuery() (string, []string) {
+	modelType := reflect.TypeOf(*new(T))
+	query := q.buildMatch()
+	carried := []string{"n"}
+	var fields []string
+
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		tag, ok := parseRelTag(field)
+		if !ok {
+			continue
+		}
 
-		if depth != 1 && field.Type.Kind() == reflect.Struct {
-			nestedPaths := q.buildRelationships(field.Type, depth-1)
-			paths = append(paths, nestedPaths...)
-		} else if depth != 1 && field.Type.Kind() == reflect.Ptr && field.Type.Elem().Kind() == reflect.Struct {
-			nestedPaths := q.buildRelationships(field.Type.Elem(), depth-1)
-			paths = append(paths, nestedPaths...)
-		} else if depth != 1 && field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Ptr && field.Type.Elem().Elem().Kind() == reflect.Struct {
-			nestedPaths := q.buildRelationships(field.Type.Elem().Elem(), depth-1)
-			paths = append(paths, nestedPaths...)
+		pattern := tag.between("n", "e", "r")
+		if !q.options.IncludeDeleted {
+			pattern += " WHERE " + notDeleted("r")
 		}
+		column := fmt.Sprintf("ids%d", len(fields))
+		query += fmt.Sprintf(" OPTIONAL MATCH %s WITH %s, collect(DISTINCT elementId(r)) AS %s",
+			pattern, strings.Join(carried, ", "), column)
+		carried = append(carried, column)
+		fields = append(fields, jsonName(field))
 	}
 
-	return paths
+	query += " RETURN " + strings.Join(carried, ", ")
+	return query, fields
 }
@@ -0,0 +1,585 @@
+package neo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"api/internal/app/neo4j/neotest"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+func TestPopulateProjection(t *testing.T) {
+	tests := []struct {
+		name        string
+		fields      []string
+		node        any
+		wantReturn  string
+		wantMissing string
+		want        World
+	}{
+		{
+			name: "whole node",
+			node: neotest.Node("4:db:1", []string{"World"}, map[string]any{
+				"name": "Atlantis", "description": "A sunken world",
+			}),
+			wantReturn: "RETURN n, ",
+			want:       World{ID: "4:db:1", Name: "Atlantis", Description: "A sunken world"},
+		},
+		{
+			name:   "projected name",
+			fields: []string{"id", "name"},
+			node: map[string]any{
+				"name": "Atlantis", projectionElementID: "4:db:1", projectionLabels: []any{"World"},
+			},
+			wantReturn:  "RETURN n {.name, __elementId: elementId(n), __labels: labels(n)} AS n, ",
+			wantMissing: ".description",
+			want:        World{ID: "4:db:1", Name: "Atlantis"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, func(neotest.Query) neotest.Response {
+				return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", tt.node, "relatedNodes", []any{})}}
+			})
+
+			var world World
+			if err := (&World{}).Find(context.Background(), &world, "elementID", "4:db:1").Populate(PopulateOptions{Fields: tt.fields}); err != nil {
+				t.Fatalf("Populate() error = %v", err)
+			}
+
+			query := driver.Queries()[0].Cypher
+			if !strings.Contains(query, tt.wantReturn) {
+				t.Errorf("Populate() ran %q, want it to contain %q", query, tt.wantReturn)
+			}
+			if tt.wantMissing != "" && strings.Contains(query, tt.wantMissing) {
+				t.Errorf("Populate() ran %q, want it not to fetch %q", query, tt.wantMissing)
+			}
+			if world.ID != tt.want.ID || world.Name != tt.want.Name || world.Description != tt.want.Description {
+				t.Errorf("Populate() = %+v, want %+v", world, tt.want)
+			}
+		})
+	}
+}
+
+func TestPopulateUnknownField(t *testing.T) {
+	driver := useFakeDriver(t, nil)
+
+	var world World
+	err := (&World{}).Find(context.Background(), &world, "elementID", "4:db:1").Populate(PopulateOptions{Fields: []string{"secret"}})
+	if err == nil {
+		t.Fatal("Populate() error = nil, want an unknown field error")
+	}
+	if len(driver.Queries()) != 0 {
+		t.Errorf("Populate() ran %d queries, want none", len(driver.Queries()))
+	}
+}
+
+func TestPopulateIncludeLabels(t *testing.T) {
+	node := neotest.Node("4:db:1", []string{"World", "Legacy"}, map[string]any{"name": "Atlantis"})
+	projected := map[string]any{"name": "Atlantis", projectionElementID: "4:db:1", projectionLabels: []any{"World", "Legacy"}}
+
+	tests := []struct {
+		name    string
+		node    any
+		options PopulateOptions
+		want    []string
+	}{
+		{name: "labels requested", node: node, options: PopulateOptions{IncludeLabels: true}, want: []string{"World", "Legacy"}},
+		{name: "labels not requested", node: node},
+		{name: "labels of a projection", node: projected, options: PopulateOptions{IncludeLabels: true, Fields: []string{"name"}}, want: []string{"World", "Legacy"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useFakeDriver(t, func(neotest.Query) neotest.Response {
+				return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", tt.node, "relatedNodes", []any{})}}
+			})
+
+			var world World
+			if err := (&World{}).Find(context.Background(), &world, "elementID", "4:db:1").Populate(tt.options); err != nil {
+				t.Fatalf("Populate() error = %v", err)
+			}
+			if !slices.Equal(world.Labels, tt.want) {
+				t.Errorf("Populate() labels = %v, want %v", world.Labels, tt.want)
+			}
+		})
+	}
+}
+
+func TestSequentialFinds(t *testing.T) {
+	driver := useFakeDriver(t, func(query neotest.Query) neotest.Response {
+		node := neotest.Node(query.Params["elementID"].(string), []string{"World"}, map[string]any{"name": "Atlantis"})
+		return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", node, "relatedNodes", []any{})}}
+	})
+
+	var world World
+	for _, id := range []string{"4:db:1", "4:db:2"} {
+		if err := world.Find(context.Background(), &world, "elementID", id).Populate(PopulateOptions{}); err != nil {
+			t.Fatalf("Find(%s) error = %v", id, err)
+		}
+		if world.ID != id {
+			t.Errorf("Find(%s) = %q", id, world.ID)
+		}
+	}
+	if driver.Closed() {
+		t.Error("Find closed the shared driver")
+	}
+	if driver.Sessions() != 2 {
+		t.Errorf("Find opened %d sessions, want one per call", driver.Sessions())
+	}
+}
+
+func TestFindAllPaging(t *testing.T) {
+	tests := []struct {
+		name       string
+		options    PopulateOptions
+		wantSuffix string
+		wantParams map[string]any
+	}{
+		{
+			name:       "page 3 of 20",
+			options:    PopulateOptions{Skip: 40, Limit: 20, Depth: 1},
+			wantSuffix: " AS relatedNodes SKIP $skip LIMIT $limit",
+			wantParams: map[string]any{"skip": 40, "limit": 20},
+		},
+		{
+			name:       "limit only",
+			options:    PopulateOptions{Limit: 20, Depth: 1},
+			wantSuffix: " AS relatedNodes LIMIT $limit",
+			wantParams: map[string]any{"limit": 20},
+		},
+		{
+			name:       "skip only",
+			options:    PopulateOptions{Skip: 40},
+			wantSuffix: "[] AS relatedNodes SKIP $skip",
+			wantParams: map[string]any{"skip": 40},
+		},
+		{
+			name:       "unpaged",
+			options:    PopulateOptions{Depth: 1},
+			wantSuffix: " AS relatedNodes",
+			wantParams: map[string]any{},
+		},
+	}
+
+	world := neotest.Node("4:db:1", []string{"World"}, map[string]any{"name": "Atlantis"})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, func(neotest.Query) neotest.Response {
+				return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", world, "relatedNodes", []any{})}}
+			})
+
+			var worlds []World
+			if err := new(World).FindAll(context.Background(), &worlds, "", nil).Populate(tt.options); err != nil {
+				t.Fatalf("Populate() error = %v", err)
+			}
+
+			// Paging applies to the returned worlds, so it follows the RETURN rather than the related matches.
+			query := driver.Queries()[0]
+			if !strings.HasSuffix(query.Cypher, tt.wantSuffix) {
+				t.Errorf("Populate() ran %q, want it to end with %q", query.Cypher, tt.wantSuffix)
+			}
+			for _, param := range []string{"skip", "limit"} {
+				if query.Params[param] != tt.wantParams[param] {
+					t.Errorf("Populate() %s = %v, want %v", param, query.Params[param], tt.wantParams[param])
+				}
+			}
+		})
+	}
+}
+
+func TestOrderBy(t *testing.T) {
+	tests := []struct {
+		name      string
+		order     func(q *PopulateQuery[City]) *PopulateQuery[City]
+		wantOrder string
+		wantErr   string
+	}{
+		{
+			name:      "ascending",
+			order:     func(q *PopulateQuery[City]) *PopulateQuery[City] { return q.OrderBy("name", Ascending) },
+			wantOrder: "WITH n ORDER BY n.name ASC RETURN",
+		},
+		{
+			name: "chained",
+			order: func(q *PopulateQuery[City]) *PopulateQuery[City] {
+				return q.OrderBy("population", Descending).OrderBy("name", Ascending)
+			},
+			wantOrder: "WITH n ORDER BY n.population DESC, n.name ASC RETURN",
+		},
+		{
+			name:      "element id",
+			order:     func(q *PopulateQuery[City]) *PopulateQuery[City] { return q.OrderBy("id", Descending) },
+			wantOrder: "WITH n ORDER BY elementId(n) DESC RETURN",
+		},
+		{
+			name: "unknown field",
+			order: func(q *PopulateQuery[City]) *PopulateQuery[City] {
+				return q.OrderBy("n.name; MATCH (x) DETACH DELETE x //", Ascending)
+			},
+			wantErr: "unknown field",
+		},
+		{
+			name:    "invalid direction",
+			order:   func(q *PopulateQuery[City]) *PopulateQuery[City] { return q.OrderBy("name", "SIDEWAYS") },
+			wantErr: `invalid sort direction "SIDEWAYS"`,
+		},
+	}
+
+	city := neotest.Node("4:db:4", []string{"City"}, map[string]any{"name": "Port Royal"})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, func(neotest.Query) neotest.Response {
+				return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", city, "relatedNodes", []any{})}}
+			})
+
+			var cities []City
+			err := tt.order(new(City).FindAll(context.Background(), &cities, "capital", true)).Populate(PopulateOptions{})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Populate() error = %v, want %q", err, tt.wantErr)
+				}
+				if len(driver.Queries()) != 0 {
+					t.Error("Populate() ran a query with an invalid order")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Populate() error = %v", err)
+			}
+
+			if query := driver.Queries()[0].Cypher; !strings.Contains(query, tt.wantOrder) {
+				t.Errorf("Populate() ran %q, want it to contain %q", query, tt.wantOrder)
+			}
+		})
+	}
+}
+
+func TestWhere(t *testing.T) {
+	tests := []struct {
+		name       string
+		where      func(q *PopulateQuery[City]) *PopulateQuery[City]
+		wantMatch  string
+		wantParams map[string]any
+		wantErr    string
+	}{
+		{
+			name:       "equality",
+			where:      func(q *PopulateQuery[City]) *PopulateQuery[City] { return q.Where("type", "=", "port") },
+			wantMatch:  "MATCH (n:City) WHERE n.capital = $capital AND n.type = $where0 AND ",
+			wantParams: map[string]any{"capital": true, "where0": "port"},
+		},
+		{
+			name: "combined comparisons",
+			where: func(q *PopulateQuery[City]) *PopulateQuery[City] {
+				return q.Where("population", ">", int64(1000)).Where("name", "contains", "Port")
+			},
+			wantMatch:  "MATCH (n:City) WHERE n.capital = $capital AND n.population > $where0 AND n.name CONTAINS $where1 AND ",
+			wantParams: map[string]any{"capital": true, "where0": int64(1000), "where1": "Port"},
+		},
+		{
+			name: "in list",
+			where: func(q *PopulateQuery[City]) *PopulateQuery[City] {
+				return q.Where("type", "IN", []string{"port", "fort"})
+			},
+			wantMatch:  "MATCH (n:City) WHERE n.capital = $capital AND n.type IN $where0 AND ",
+			wantParams: map[string]any{"capital": true, "where0": []string{"port", "fort"}},
+		},
+		{
+			name:       "element id",
+			where:      func(q *PopulateQuery[City]) *PopulateQuery[City] { return q.Where("elementID", "STARTS WITH", "4:db") },
+			wantMatch:  "MATCH (n:City) WHERE n.capital = $capital AND elementId(n) STARTS WITH $where0 AND ",
+			wantParams: map[string]any{"capital": true, "where0": "4:db"},
+		},
+		{
+			name:    "unknown operator",
+			where:   func(q *PopulateQuery[City]) *PopulateQuery[City] { return q.Where("name", "=~", ".*") },
+			wantErr: `invalid operator "=~"`,
+		},
+		{
+			name:    "unknown field",
+			where:   func(q *PopulateQuery[City]) *PopulateQuery[City] { return q.Where("mayor", "=", "Morgan") },
+			wantErr: `unknown field "mayor"`,
+		},
+	}
+
+	city := neotest.Node("4:db:4", []string{"City"}, map[string]any{"name": "Port Royal"})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, func(neotest.Query) neotest.Response {
+				return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", city, "relatedNodes", []any{})}}
+			})
+
+			var cities []City
+			err := tt.where(new(City).FindAll(context.Background(), &cities, "capital", true)).Populate(PopulateOptions{})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Populate() error = %v, want %q", err, tt.wantErr)
+				}
+				if len(driver.Queries()) != 0 {
+					t.Error("Populate() ran a query with an invalid condition")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Populate() error = %v", err)
+			}
+			if len(cities) != 1 || cities[0].Name != "Port Royal" {
+				t.Errorf("Populate() = %+v, want Port Royal", cities)
+			}
+
+			query := driver.Queries()[0]
+			if !strings.HasPrefix(query.Cypher, tt.wantMatch) {
+				t.Errorf("Populate() ran %q, want it to start with %q", query.Cypher, tt.wantMatch)
+			}
+			if !reflect.DeepEqual(query.Params, tt.wantParams) {
+				t.Errorf("Populate() params = %v, want %v", query.Params, tt.wantParams)
+			}
+		})
+	}
+}
+
+func TestPopulateTwice(t *testing.T) {
+	populate := func(q *PopulateQuery[World]) error { return q.Populate(PopulateOptions{}) }
+	populateIDs := func(q *PopulateQuery[World]) error {
+		_, err := q.PopulateIDs()
+		return err
+	}
+
+	tests := []struct {
+		name   string
+		first  func(q *PopulateQuery[World]) error
+		second func(q *PopulateQuery[World]) error
+	}{
+		{name: "populate twice", first: populate, second: populate},
+		{name: "ids twice", first: populateIDs, second: populateIDs},
+		{name: "populate then ids", first: populate, second: populateIDs},
+		{name: "ids then populate", first: populateIDs, second: populate},
+	}
+
+	world := neotest.Node("4:db:1", []string{"World"}, map[string]any{"name": "Atlantis"})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, func(neotest.Query) neotest.Response {
+				return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", world, "relatedNodes", []any{}, "ids0", []any{}, "ids1", []any{})}}
+			})
+
+			var found World
+			query := found.Find(context.Background(), &found, "elementID", "4:db:1")
+			if err := tt.first(query); err != nil {
+				t.Fatalf("first call error = %v", err)
+			}
+			found.Name = "edited"
+
+			if err := tt.second(query); !errors.Is(err, ErrQueryExecuted) {
+				t.Fatalf("second call error = %v, want %v", err, ErrQueryExecuted)
+			}
+			if len(driver.Queries()) != 1 {
+				t.Errorf("ran %d queries, want the first call's only", len(driver.Queries()))
+			}
+			if found.Name != "edited" {
+				t.Errorf("second call overwrote the model with %q", found.Name)
+			}
+		})
+	}
+}
+
+func TestPopulateDepthClamp(t *testing.T) {
+	// Each populated level of a World adds COLLECT subqueries: continents and oceans, then zones, then cities.
+	tests := []struct {
+		name         string
+		max          int
+		depth        int
+		wantCollects int
+		wantLogged   string
+	}{
+		{name: "within the max", max: 2, depth: 1, wantCollects: 2},
+		{name: "clamped", max: 2, depth: 50, wantCollects: 3, wantLogged: "populate depth 50 of World clamped to 2"},
+		{name: "unbounded clamped silently", max: 2, depth: Unbounded, wantCollects: 3},
+		{name: "no max", max: 0, depth: 50, wantCollects: 4},
+		{name: "unbounded without max", max: 0, depth: Unbounded, wantCollects: 4},
+		{name: "root only", max: 2, depth: 0, wantCollects: 0},
+	}
+
+	world := neotest.Node("4:db:1", []string{"World"}, map[string]any{"name": "Atlantis"})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(max int) { MaxPopulateDepth = max }(MaxPopulateDepth)
+			MaxPopulateDepth = tt.max
+
+			var logged bytes.Buffer
+			log.SetOutput(&logged)
+			defer log.SetOutput(os.Stderr)
+
+			driver := useFakeDriver(t, func(neotest.Query) neotest.Response {
+				return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", world, "relatedNodes", []any{})}}
+			})
+
+			var found World
+			if err := found.Find(context.Background(), &found, "elementID", "4:db:1").Populate(PopulateOptions{Depth: tt.depth}); err != nil {
+				t.Fatalf("Populate() error = %v", err)
+			}
+			if found.Name != "Atlantis" {
+				t.Errorf("Populate() = %+v, want Atlantis", found)
+			}
+
+			query := driver.Queries()[0].Cypher
+			if collects := strings.Count(query, "COLLECT {"); collects != tt.wantCollects {
+				t.Errorf("Populate() ran %d COLLECT subqueries, want %d: %q", collects, tt.wantCollects, query)
+			}
+			if tt.wantCollects == 0 && !strings.HasSuffix(query, "[] AS relatedNodes") {
+				t.Errorf("Populate() ran %q, want no related nodes", query)
+			}
+			if !strings.Contains(logged.String(), tt.wantLogged) || (tt.wantLogged == "" && logged.Len() != 0) {
+				t.Errorf("Populate() logged %q, want %q", logged.String(), tt.wantLogged)
+			}
+		})
+	}
+}
+
+func TestPopulateNested(t *testing.T) {
+	related := func(id string, label string, field string, children ...any) map[string]any {
+		node := neotest.Node(id, []string{label}, map[string]any{"name": id})
+		return map[string]any{"node": node, "rel": map[string]any{}, "field": field, "children": append([]any{}, children...)}
+	}
+	// The tree as returned for depth 3; shallower queries return the same tree cut at their depth.
+	tree := []any{
+		related("4:db:2", "Continent", "continents",
+			related("4:db:3", "Zone", "zones", related("4:db:4", "City", "cities")),
+			related("4:db:5", "Zone", "zones"),
+		),
+		related("4:db:6", "Continent", "continents"),
+		related("4:db:7", "Ocean", "oceans"),
+	}
+	var cut func(entries []any, depth int) []any
+	cut = func(entries []any, depth int) []any {
+		if depth == 0 {
+			return []any{}
+		}
+		out := make([]any, len(entries))
+		for i, e := range entries {
+			entry := maps.Clone(e.(map[string]any))
+			entry["children"] = cut(entry["children"].([]any), depth-1)
+			out[i] = entry
+		}
+		return out
+	}
+
+	tests := []struct {
+		name       string
+		depth      int
+		wantFields []string // relationship fields collected by the query
+		wantZones  int      // zones of the first continent
+		wantCities int      // cities of its first zone
+	}{
+		{name: "depth 1", depth: 1, wantFields: []string{"continents", "oceans"}},
+		{name: "depth 2", depth: 2, wantFields: []string{"continents", "zones", "oceans"}, wantZones: 2},
+		{name: "depth 3", depth: 3, wantFields: []string{"continents", "zones", "cities", "oceans"}, wantZones: 2, wantCities: 1},
+	}
+
+	world := neotest.Node("4:db:1", []string{"World"}, map[string]any{"name": "Atlantis"})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, func(neotest.Query) neotest.Response {
+				return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", world, "relatedNodes", cut(tree, tt.depth))}}
+			})
+
+			var found World
+			if err := found.Find(context.Background(), &found, "elementID", "4:db:1").Populate(PopulateOptions{Depth: tt.depth}); err != nil {
+				t.Fatalf("Populate() error = %v", err)
+			}
+
+			// One query returns the whole tree.
+			queries := driver.Queries()
+			if len(queries) != 1 {
+				t.Fatalf("Populate() ran %d queries, want one", len(queries))
+			}
+			var fields []string
+			for _, field := range []string{"continents", "zones", "cities", "oceans"} {
+				if strings.Contains(queries[0].Cypher, fmt.Sprintf("field: %q", field)) {
+					fields = append(fields, field)
+				}
+			}
+			if !slices.Equal(fields, tt.wantFields) {
+				t.Errorf("Populate() collected %v, want %v", fields, tt.wantFields)
+			}
+
+			if len(found.Continents) != 2 || len(found.Oceans) != 1 {
+				t.Fatalf("Populate() = %d continents and %d oceans, want 2 and 1", len(found.Continents), len(found.Oceans))
+			}
+			zones := found.Continents[0].Zones
+			if len(zones) != tt.wantZones {
+				t.Fatalf("Continents[0].Zones = %d, want %d", len(zones), tt.wantZones)
+			}
+			if tt.wantZones > 0 && len(zones[0].Cities) != tt.wantCities {
+				t.Errorf("Continents[0].Zones[0].Cities = %d, want %d", len(zones[0].Cities), tt.wantCities)
+			}
+		})
+	}
+}
+
+func TestSetQueryLogger(t *testing.T) {
+	tests := []struct {
+		name      string
+		reset     bool
+		options   PopulateOptions
+		wantCalls int
+	}{
+		{name: "logs the query", wantCalls: 1},
+		{name: "logs the paging params", options: PopulateOptions{Skip: 10, Limit: 5}, wantCalls: 1},
+		{name: "nil restores the no-op", reset: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(func() { SetQueryLogger(nil) })
+			driver := useFakeDriver(t, func(neotest.Query) neotest.Response {
+				world := neotest.Node("4:db:1", []string{"World"}, map[string]any{"name": "Atlantis"})
+				return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", world, "relatedNodes", []any{})}}
+			})
+
+			type logged struct {
+				query  string
+				params map[string]interface{}
+			}
+			var calls []logged
+			SetQueryLogger(func(query string, params map[string]interface{}) {
+				calls = append(calls, logged{query, params})
+			})
+			if tt.reset {
+				SetQueryLogger(nil)
+			}
+
+			var worlds []World
+			if err := new(World).FindAll(context.Background(), &worlds, "name", "Atlantis").Populate(tt.options); err != nil {
+				t.Fatalf("Populate() error = %v", err)
+			}
+
+			if len(calls) != tt.wantCalls {
+				t.Fatalf("logger called %d times, want %d", len(calls), tt.wantCalls)
+			}
+			if tt.wantCalls == 0 {
+				return
+			}
+			// The logger sees exactly what the driver runs.
+			ran := driver.Queries()[0]
+			if calls[0].query != ran.Cypher {
+				t.Errorf("logged query %q, want %q", calls[0].query, ran.Cypher)
+			}
+			if !reflect.DeepEqual(calls[0].params, ran.Params) {
+				t.Errorf("logged params %v, want %v", calls[0].params, ran.Params)
+			}
+		})
+	}
+}
@@ -0,0 +1,484 @@
+package neo
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// Relationship types used by the models' rel tags and by the controllers.
+// RegisterModel rejects rel tags using a type that is neither listed here nor registered with RegisterRelType,
+// so tags and controllers cannot drift apart.
+const (
+	RelOwns = "OWNS"
+	RelHas  = "HAS"
+)
+
+var relationshipTypes = map[string]bool{
+	RelOwns: true,
+	RelHas:  true,
+}
+
+/*
+RegisterRelType registers a relationship type beyond OWNS and HAS, so rel tags and queries may use it.
+Register it before the models whose rel tags use it. It panics if the type is not a valid identifier.
+
+Example usage:
+
+	neo.RegisterRelType("CAPITAL_OF")
+	neo.RegisterModel("Region", &Region{}) // Capital *City `rel:"CAPITAL_OF,<-,City"`
+*/
+func RegisterRelType(relType string) {
+	if err := validateIdentifiers(relType); err != nil {
+		panic(err.Error())
+	}
+	relationshipTypes[relType] = true
+}
+
+// relTag is a parsed rel:"TYPE,DIRECTION[,LABEL]" tag.
+type relTag struct {
+	relType   string
+	direction string
+	label     string
+}
+
+// parseRelTag parses the rel tag of a relationship field. The label of the related nodes is the third part
+// of the tag when present, or the name of the field's element type. ok is false for fields without a
+// relationship tag, including rel:"props" fields.
+func parseRelTag(field reflect.StructField) (relTag, bool) {
+	tagParts := strings.Split(field.Tag.Get("rel"), ",")
+	if len(tagParts) < 2 || len(tagParts) > 3 {
+		return relTag{}, false
+	}
+
+	tag := relTag{relType: tagParts[0], direction: tagParts[1]}
+	if len(tagParts) == 3 && tagParts[2] != "" {
+		tag.label = tagParts[2]
+	} else {
+		tag.label = relatedType(field.Type).Name()
+	}
+	return tag, true
+}
+
+// relatedType returns the struct type of a relationship field, unwrapping slices and pointers.
+func relatedType(fieldType reflect.Type) reflect.Type {
+	if fieldType.Kind() == reflect.Slice {
+		fieldType = fieldType.Elem()
+	}
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	return fieldType
+}
+
+// between returns the pattern matching the relationship, bound to edge, from the node bound to from to the related node bound to to.
+func (tag relTag) between(from string, edge string, to string) string {
+	if tag.direction == "<-" {
+		return fmt.Sprintf("(%s)<-[%s:%s]-(%s:%s)", from, edge, tag.relType, to, tag.label)
+	}
+	return fmt.Sprintf("(%s)-[%s:%s]->(%s:%s)", from, edge, tag.relType, to, tag.label)
+}
+
+// relPropsTag is the rel tag of the field receiving the properties of the relationship a related node was populated through.
+const relPropsTag = "props"
+
+// validateRelTags ensures every rel tag of the model type uses a known relationship type,
+// and that a rel:"props" field is a map[string]interface{}.
+func validateRelTags(modelName string, modelType reflect.Type) error {
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		relTag := field.Tag.Get("rel")
+		if relTag == "" {
+			continue
+		}
+
+		if relTag == relPropsTag {
+			if field.Type != reflect.TypeOf(map[string]interface{}{}) {
+				return fmt.Errorf("model %s field %s tagged rel:%q must be a map[string]interface{}", modelName, field.Name, relPropsTag)
+			}
+			continue
+		}
+
+		tag, ok := parseRelTag(field)
+		if !ok {
+			return fmt.Errorf("model %s field %s has an invalid rel tag %q, expected \"TYPE,DIRECTION[,LABEL]\"", modelName, field.Name, relTag)
+		}
+		if !relationshipTypes[tag.relType] {
+			return fmt.Errorf("model %s field %s uses unknown relationship type %q", modelName, field.Name, tag.relType)
+		}
+	}
+	return nil
+}
+
+/*
+TransferOptions is a struct that holds options for moving an inbound relationship of a node to a new source node.
+Example:
+
+	// Move the OWNS relationship of a world to the user with userID 42
+	options := TransferOptions{
+		Rel:   "OWNS",
+		Label: "User",
+		Field: "userID",
+		Value: int64(42),
+	}
+*/
+type TransferOptions struct {
+	Rel   string      // Relationship type to move ie: OWNS
+	Label string      // Label of the current and new source nodes ie: User
+	Field string      // Field name used to match the new source node ie: userID
+	Value interface{} // Value used to match the new source node ie: 42
+
+	// Authorize is called with the properties of the current source node (nil if there is none)
+	// before anything is written. Returning an error aborts the transfer.
+	Authorize func(current map[string]interface{}) error
+}
+
+/*
+@method Transfer
+
+@description Replace the inbound relationship of a node with one from a new source node, in a single transaction.
+Both the node and the new source node must exist, otherwise a "not found" error is returned.
+
+@params elementID string - The elementId of the node whose relationship is transferred.
+
+@params options TransferOptions - Options describing the relationship and the new source node.
+
+@example
+
+	// Give a world to another user
+	world := &World{}
+	err := world.Transfer(ctx, "4:abc:12", TransferOptions{
+		Rel:   "OWNS",
+		Label: "User",
+		Field: "userID",
+		Value: int64(42),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+*/
+func (b *NeoBaseModel[T]) Transfer(ctx context.Context, elementID string, options TransferOptions) error {
+	if err := validateIdentifiers(options.Rel, options.Label, options.Field); err != nil {
+		return err
+	}
+	if err := b.initDriver(); err != nil {
+		return err
+	}
+
+	session := b.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close(ctx)
+
+	queryCurrent := fmt.Sprintf("MATCH (n:%s) WHERE elementId(n) = $value AND %s OPTIONAL MATCH (o:%s)-[:%s]->(n) RETURN o",
+		b.Label, notDeleted("n"), options.Label, options.Rel)
+	queryTransfer := fmt.Sprintf("MATCH (n:%s) WHERE elementId(n) = $value AND %s MATCH (s:%s {%s: $sourceValue}) WHERE %s"+
+		" OPTIONAL MATCH (:%s)-[e:%s]->(n) DELETE e"+
+		" WITH DISTINCT n, s CREATE (s)-[:%s]->(n) RETURN n",
+		b.Label, notDeleted("n"), options.Label, options.Field, notDeleted("s"), options.Label, options.Rel, options.Rel)
+
+	params := map[string]interface{}{
+		"value":       elementID,
+		"sourceValue": queryParam(options.Value),
+	}
+
+	_, err := runWrite(ctx, session, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		res, err := tx.Run(ctx, queryCurrent, params)
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			if err := res.Err(); err != nil {
+				return nil, err
+			}
+			return nil, ErrNotFound
+		}
+
+		var current map[string]interface{}
+		if value, ok := res.Record().Get("o"); ok && value != nil {
+			if node, ok := value.(neo4j.Node); ok {
+				current = node.Props
+			}
+		}
+
+		if options.Authorize != nil {
+			if err := options.Authorize(current); err != nil {
+				return nil, err
+			}
+		}
+
+		res, err = tx.Run(ctx, queryTransfer, params)
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			if err := res.Err(); err != nil {
+				return nil, err
+			}
+			return nil, ErrNotFound
+		}
+		return res.Consume(ctx)
+	})
+
+	return err
+}
+
+/*
+Edge is a relationship returned by FindRelationships.
+*/
+type Edge struct {
+	ID         string                 `json:"id"`
+	StartID    string                 `json:"startId"`
+	EndID      string                 `json:"endId"`
+	Type       string                 `json:"type"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// relationshipPattern returns the pattern matching a typed relationship e from n to m in the given direction.
+// An empty direction matches both directions.
+func relationshipPattern(rel string, dir string) (string, error) {
+	if err := validateIdentifiers(rel); err != nil {
+		return "", err
+	}
+
+	switch dir {
+	case "->":
+		return fmt.Sprintf("(n)-[e:%s]->(m)", rel), nil
+	case "<-":
+		return fmt.Sprintf("(n)<-[e:%s]-(m)", rel), nil
+	case "":
+		return fmt.Sprintf("(n)-[e:%s]-(m)", rel), nil
+	}
+	return "", fmt.Errorf("invalid relationship direction %q", dir)
+}
+
+/*
+@method FindRelationships
+
+@description Find the relationships of a given type attached to a node, with their properties.
+
+@params nodeID string - The elementId of the node.
+
+@params rel string - The relationship type ie: OWNS
+
+@params dir string - The relationship direction from the node: "->", "<-", or "" for both.
+
+@returns ([]Edge, error) - The matching relationships.
+
+@example
+
+	// All worlds owned by a user, with the OWNS properties
+	user := &User{}
+	edges, err := user.FindRelationships(ctx, "4:abc:1", RelOwns, "->")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(edges[0].Properties["since"])
+*/
+func (b *NeoBaseModel[T]) FindRelationships(ctx context.Context, nodeID string, rel string, dir string) ([]Edge, error) {
+	pattern, err := relationshipPattern(rel, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.initDriver(); err != nil {
+		return nil, err
+	}
+
+	session := b.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+	defer session.Close(ctx)
+
+	query := fmt.Sprintf("MATCH (n:%s) WHERE elementId(n) = $value AND %s MATCH %s WHERE %s RETURN e",
+		b.Label, notDeleted("n"), pattern, notDeleted("m"))
+	params := map[string]interface{}{
+		"value": nodeID,
+	}
+
+	result, err := runRead(ctx, session, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		res, err := tx.Run(ctx, query, params)
+		if err != nil {
+			return nil, err
+		}
+
+		var edges []Edge
+		for res.Next(ctx) {
+			value, _ := res.Record().Get("e")
+			relationship, ok := value.(neo4j.Relationship)
+			if !ok {
+				return nil, fmt.Errorf("failed to cast result to neo4j.Relationship")
+			}
+			edges = append(edges, Edge{
+				ID:         relationship.ElementId,
+				StartID:    relationship.StartElementId,
+				EndID:      relationship.EndElementId,
+				Type:       relationship.Type,
+				Properties: relationship.Props,
+			})
+		}
+		return edges, res.Err()
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	edges, _ := result.([]Edge)
+	return edges, nil
+}
+
+/*
+@method CountRelated
+
+@description Count the relationships of a given type attached to a node, without materializing the related nodes.
+
+@params nodeID string - The elementId of the node.
+
+@params rel string - The relationship type ie: OWNS
+
+@params dir string - The relationship direction from the node: "->", "<-", or "" for both.
+
+@params targetLabel string - The label the related nodes must have, or "" for any label.
+
+@returns (int64, error) - The number of matching relationships.
+
+@example
+
+	// Number of worlds owned by a user
+	user := &User{}
+	count, err := user.CountRelated(ctx, "4:abc:1", RelOwns, "->", "World")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(count)
+*/
+func (b *NeoBaseModel[T]) CountRelated(ctx context.Context, nodeID string, rel string, dir string, targetLabel string) (int64, error) {
+	if !relationshipTypes[rel] {
+		return 0, fmt.Errorf("unknown relationship type: %s", rel)
+	}
+	pattern, err := relationshipPattern(rel, dir)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := b.initDriver(); err != nil {
+		return 0, err
+	}
+
+	session := b.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+	defer session.Close(ctx)
+
+	query := fmt.Sprintf("MATCH (n:%s) WHERE elementId(n) = $value AND %s MATCH %s"+
+		" WHERE ($targetLabel = '' OR $targetLabel IN labels(m)) AND %s RETURN count(e) AS count",
+		b.Label, notDeleted("n"), pattern, notDeleted("m"))
+	params := map[string]interface{}{
+		"value":       nodeID,
+		"targetLabel": targetLabel,
+	}
+
+	result, err := runRead(ctx, session, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		res, err := tx.Run(ctx, query, params)
+		if err != nil {
+			return nil, err
+		}
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		count, _ := record.Get("count")
+		return count, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	count, ok := result.(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected result type: %T", result)
+	}
+	return count, nil
+}
+
+// nodeMatch returns the condition matching the node bound to binding on field, against the parameter param.
+// "id" and "elementID" match on the node's elementId.
+func nodeMatch(binding string, field string, param string) string {
+	if isElementIDField(field) {
+		return fmt.Sprintf("elementId(%s) = $%s", binding, param)
+	}
+	return fmt.Sprintf("%s.%s = $%s", binding, field, param)
+}
+
+/*
+@method RemoveRelationship
+
+@description Delete the relationships of a given type between two nodes, leaving both nodes in place.
+A "not found" error is returned when no such relationship exists.
+
+@params fromField string - The field matching the node of the model's label ie: userID, or "id" for its elementId.
+
+@params fromValue interface{} - The value of fromField.
+
+@params rel string - The relationship type ie: HAS
+
+@params direction string - The relationship direction from the node: "->", "<-", or "" for both.
+
+@params toLabel string - The label of the other node ie: City
+
+@params toField string - The field matching the other node, or "id" for its elementId.
+
+@params toValue interface{} - The value of toField.
+
+@example
+
+	// Unassign a city from a zone without deleting the city
+	zone := &Zone{}
+	err := zone.RemoveRelationship(ctx, "id", zoneID, RelHas, "->", "City", "id", cityID)
+	if err != nil {
+		log.Fatal(err)
+	}
+*/
+func (b *NeoBaseModel[T]) RemoveRelationship(ctx context.Context, fromField string, fromValue interface{}, rel string, direction string, toLabel string, toField string, toValue interface{}) error {
+	if err := b.initDriver(); err != nil {
+		return err
+	}
+
+	session := b.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close(ctx)
+
+	_, err := runWrite(ctx, session, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		return nil, b.removeRelationshipTx(ctx, tx, fromField, fromValue, rel, direction, toLabel, toField, toValue)
+	})
+
+	return err
+}
+
+// removeRelationshipTx deletes the relationships described as in RemoveRelationship within tx.
+func (b *NeoBaseModel[T]) removeRelationshipTx(ctx context.Context, tx neo4j.ManagedTransaction, fromField string, fromValue interface{}, rel string, direction string, toLabel string, toField string, toValue interface{}) error {
+	pattern, err := relationshipPattern(rel, direction)
+	if err != nil {
+		return err
+	}
+	if err := validateIdentifiers(fromField, toLabel, toField); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("MATCH (n:%s) WHERE %s MATCH (m:%s) WHERE %s MATCH %s DELETE e RETURN count(e) AS deleted",
+		b.Label, nodeMatch("n", fromField, "fromValue"), toLabel, nodeMatch("m", toField, "toValue"), pattern)
+	params := map[string]interface{}{
+		"fromValue": queryParam(fromValue),
+		"toValue":   queryParam(toValue),
+	}
+
+	res, err := tx.Run(ctx, query, params)
+	if err != nil {
+		return err
+	}
+	record, err := res.Single(ctx)
+	if err != nil {
+		return err
+	}
+	if deleted, _ := record.Get("deleted"); deleted == int64(0) {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,402 @@
+package neo
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"api/internal/app/neo4j/neotest"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+func TestFindRelationships(t *testing.T) {
+	owns := neotest.Relationship("5:db:1", "4:db:10", "4:db:1", RelOwns, map[string]any{"since": int64(2020)})
+
+	tests := []struct {
+		name        string
+		dir         string
+		records     []*neo4j.Record
+		wantPattern string
+		want        []Edge
+		wantErr     bool
+	}{
+		{
+			name:        "outgoing edge with a property",
+			dir:         "->",
+			records:     []*neo4j.Record{neotest.Record("e", owns)},
+			wantPattern: "MATCH (n)-[e:OWNS]->(m)",
+			want: []Edge{{
+				ID: "5:db:1", StartID: "4:db:10", EndID: "4:db:1", Type: RelOwns,
+				Properties: map[string]any{"since": int64(2020)},
+			}},
+		},
+		{
+			name:        "incoming without edges",
+			dir:         "<-",
+			wantPattern: "MATCH (n)<-[e:OWNS]-(m)",
+		},
+		{
+			name:        "both directions",
+			dir:         "",
+			records:     []*neo4j.Record{neotest.Record("e", owns)},
+			wantPattern: "MATCH (n)-[e:OWNS]-(m)",
+			want: []Edge{{
+				ID: "5:db:1", StartID: "4:db:10", EndID: "4:db:1", Type: RelOwns,
+				Properties: map[string]any{"since": int64(2020)},
+			}},
+		},
+		{
+			name:    "invalid direction",
+			dir:     "=>",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, func(neotest.Query) neotest.Response { return neotest.Response{Records: tt.records} })
+
+			edges, err := (&User{}).FindRelationships(context.Background(), "4:db:10", RelOwns, tt.dir)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("FindRelationships() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FindRelationships() error = %v", err)
+			}
+			if !reflect.DeepEqual(edges, tt.want) {
+				t.Errorf("FindRelationships() = %+v, want %+v", edges, tt.want)
+			}
+			if query := driver.Queries()[0].Cypher; !strings.Contains(query, tt.wantPattern) {
+				t.Errorf("FindRelationships() ran %q, want it to contain %q", query, tt.wantPattern)
+			}
+		})
+	}
+}
+
+func TestCountRelated(t *testing.T) {
+	tests := []struct {
+		name        string
+		rel         string
+		dir         string
+		targetLabel string
+		wantPattern string
+		want        int64
+		wantErr     string
+	}{
+		{
+			name:        "owned worlds",
+			rel:         RelOwns,
+			dir:         "->",
+			targetLabel: "World",
+			wantPattern: "MATCH (n)-[e:OWNS]->(m) WHERE ($targetLabel = '' OR $targetLabel IN labels(m))",
+			want:        2,
+		},
+		{
+			name:        "owners",
+			rel:         RelOwns,
+			dir:         "<-",
+			wantPattern: "MATCH (n)<-[e:OWNS]-(m)",
+			want:        2,
+		},
+		{
+			name:        "either direction",
+			rel:         RelOwns,
+			wantPattern: "MATCH (n)-[e:OWNS]-(m)",
+			want:        2,
+		},
+		{name: "unknown relationship", rel: "LIKES", dir: "->", wantErr: "unknown relationship type: LIKES"},
+		{name: "invalid direction", rel: RelOwns, dir: "=>", wantErr: `invalid relationship direction "=>"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, func(neotest.Query) neotest.Response {
+				return neotest.Response{Records: []*neo4j.Record{neotest.Record("count", int64(2))}}
+			})
+
+			count, err := new(User).CountRelated(context.Background(), "4:db:10", tt.rel, tt.dir, tt.targetLabel)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("CountRelated() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CountRelated() error = %v", err)
+			}
+			if count != tt.want {
+				t.Errorf("CountRelated() = %d, want %d", count, tt.want)
+			}
+
+			// The OWNS edges are counted rather than the neighboring nodes.
+			query := driver.Queries()[0]
+			if !strings.Contains(query.Cypher, tt.wantPattern) || !strings.HasSuffix(query.Cypher, "RETURN count(e) AS count") {
+				t.Errorf("CountRelated() ran %q, want it to contain %q", query.Cypher, tt.wantPattern)
+			}
+			if query.Params["value"] != "4:db:10" || query.Params["targetLabel"] != tt.targetLabel {
+				t.Errorf("CountRelated() params = %v", query.Params)
+			}
+		})
+	}
+}
+
+func TestRelationshipProperties(t *testing.T) {
+	tests := []struct {
+		name          string
+		relProps      map[string]any
+		wantClause    string
+		wantOwnership map[string]any
+	}{
+		{
+			name:          "properties on the edge",
+			relProps:      map[string]any{"since": int64(2020), "role": "founder"},
+			wantClause:    "CREATE (n)<-[e:OWNS]-(r) SET e += $relProps",
+			wantOwnership: map[string]any{"since": int64(2020), "role": "founder"},
+		},
+		{
+			name:       "bare edge",
+			wantClause: "CREATE (n)<-[e:OWNS]-(r)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The fake stores the relationship properties of the create and returns them when the owner is populated.
+			var stored map[string]any
+			driver := useFakeDriver(t, func(query neotest.Query) neotest.Response {
+				world := neotest.Node("4:db:1", []string{"World"}, map[string]any{"name": "Atlantis"})
+				if strings.HasPrefix(query.Cypher, "CREATE") {
+					stored, _ = query.Params["relProps"].(map[string]any)
+					return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", world)}}
+				}
+				user := neotest.Node("4:db:10", []string{"User"}, map[string]any{"username": "alice"})
+				return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", user, "relatedNodes", []any{
+					map[string]any{"node": world, "rel": stored, "field": "worlds", "children": []any{}},
+				})}}
+			})
+
+			world := &World{Name: "Atlantis"}
+			err := world.Create(context.Background(), world, CreateOptions{
+				Field: "elementID", Value: "4:db:10", Label: "User", Rel: RelOwns, RelDirection: "<-", RelProps: tt.relProps,
+			})
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			if query := driver.Queries()[0].Cypher; !strings.Contains(query, tt.wantClause) || (tt.relProps == nil && strings.Contains(query, "relProps")) {
+				t.Errorf("Create() ran %q, want it to contain %q", query, tt.wantClause)
+			}
+
+			var user User
+			if err := user.Find(context.Background(), &user, "elementID", "4:db:10").Populate(PopulateOptions{Depth: 1}); err != nil {
+				t.Fatalf("Populate() error = %v", err)
+			}
+			if len(user.Worlds) != 1 || !reflect.DeepEqual(user.Worlds[0].Ownership, tt.wantOwnership) {
+				t.Errorf("Populate() worlds = %+v, want ownership %v", user.Worlds, tt.wantOwnership)
+			}
+		})
+	}
+}
+
+func TestRegisterModelRelProps(t *testing.T) {
+	type TypedProps struct {
+		NeoBaseModel[TypedProps]
+		ID    string            `node:"id" json:"id"`
+		Props map[string]string `rel:"props" json:"props"`
+	}
+
+	defer func() {
+		recovered := recover()
+		if message, _ := recovered.(string); !strings.Contains(message, `field Props tagged rel:"props" must be a map[string]interface{}`) {
+			t.Errorf("RegisterModel() panic = %v, want a rel:\"props\" type error", recovered)
+		}
+		delete(modelRegistry, "TypedProps")
+	}()
+	RegisterModel("TypedProps", &TypedProps{})
+}
+
+func TestParseRelTag(t *testing.T) {
+	type model struct {
+		Cities   []*City                `rel:"HAS,->"`
+		Capital  *City                  `rel:"CAPITAL_OF,<-,City"`
+		Port     *City                  `rel:"HAS,->,"`
+		Props    map[string]interface{} `rel:"props"`
+		Name     string                 `node:"name"`
+		Invalid  *City                  `rel:"HAS"`
+		TooLong  *City                  `rel:"HAS,->,City,extra"`
+		Untagged *City
+	}
+
+	tests := []struct {
+		field  string
+		want   relTag
+		wantOK bool
+	}{
+		{field: "Cities", want: relTag{relType: "HAS", direction: "->", label: "City"}, wantOK: true},
+		{field: "Capital", want: relTag{relType: "CAPITAL_OF", direction: "<-", label: "City"}, wantOK: true},
+		{field: "Port", want: relTag{relType: "HAS", direction: "->", label: "City"}, wantOK: true},
+		{field: "Props"},
+		{field: "Name"},
+		{field: "Invalid"},
+		{field: "TooLong"},
+		{field: "Untagged"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			field, _ := reflect.TypeOf(model{}).FieldByName(tt.field)
+			tag, ok := parseRelTag(field)
+			if ok != tt.wantOK || tag != tt.want {
+				t.Errorf("parseRelTag() = %+v, %v, want %+v, %v", tag, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRegisterRelType(t *testing.T) {
+	type Country struct {
+		NeoBaseModel[Country]
+		ID      string  `node:"id" json:"id"`
+		Capital *City   `rel:"CAPITAL_OF_COUNTRY,<-,City" json:"capital"`
+		Cities  []*City `rel:"HAS,->" json:"cities"`
+	}
+	t.Cleanup(func() { delete(modelRegistry, "Country") })
+
+	tests := []struct {
+		name      string
+		register  []string
+		wantPanic string
+	}{
+		{name: "unregistered type", wantPanic: `unknown relationship type "CAPITAL_OF_COUNTRY"`},
+		{name: "registered type", register: []string{"CAPITAL_OF_COUNTRY"}},
+		{name: "invalid type", register: []string{"CAPITAL]->(m) DETACH DELETE (m"}, wantPanic: "invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				msg, _ := r.(string)
+				switch {
+				case tt.wantPanic == "" && r != nil:
+					t.Errorf("RegisterModel() panicked: %v", r)
+				case tt.wantPanic != "" && !strings.Contains(msg, tt.wantPanic):
+					t.Errorf("registration panic = %v, want %q", r, tt.wantPanic)
+				}
+			}()
+			for _, relType := range tt.register {
+				RegisterRelType(relType)
+			}
+			RegisterModel("Country", &Country{})
+		})
+	}
+}
+
+func TestExplicitTargetLabel(t *testing.T) {
+	type Region struct {
+		NeoBaseModel[Region]
+		ID      string  `node:"id" json:"id"`
+		Name    string  `node:"name" json:"name"`
+		Cities  []*City `rel:"HAS,->" json:"cities"`
+		Capital *City   `rel:"CAPITAL_OF,<-,City" json:"capital"`
+	}
+
+	RegisterRelType("CAPITAL_OF")
+	RegisterModel("Region", &Region{})
+	t.Cleanup(func() { delete(modelRegistry, "Region") })
+
+	city := func(id string, name string, field string) map[string]any {
+		node := neotest.Node(id, []string{"City"}, map[string]any{"name": name})
+		return map[string]any{"node": node, "rel": map[string]any{}, "field": field, "children": []any{}}
+	}
+	region := neotest.Node("4:db:20", []string{"Region"}, map[string]any{"name": "Caribbean"})
+	driver := useFakeDriver(t, func(neotest.Query) neotest.Response {
+		return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", region, "relatedNodes", []any{
+			city("4:db:4", "Port Royal", "cities"),
+			city("4:db:5", "Tortuga", "cities"),
+			city("4:db:4", "Port Royal", "capital"),
+		})}}
+	})
+
+	var found Region
+	if err := found.Find(context.Background(), &found, "elementID", "4:db:20").Populate(PopulateOptions{Depth: 1}); err != nil {
+		t.Fatalf("Populate() error = %v", err)
+	}
+
+	// Both fields target City nodes, each through its own relationship type.
+	query := driver.Queries()[0].Cypher
+	for _, pattern := range []string{"(n)-[e1:HAS]->(r1:City)", "(n)<-[e1:CAPITAL_OF]-(r1:City)"} {
+		if !strings.Contains(query, pattern) {
+			t.Errorf("Populate() ran %q, want it to contain %q", query, pattern)
+		}
+	}
+	if len(found.Cities) != 2 {
+		t.Errorf("Populate() cities = %+v, want 2", found.Cities)
+	}
+	if found.Capital == nil || found.Capital.ID != "4:db:4" {
+		t.Errorf("Populate() capital = %+v, want Port Royal", found.Capital)
+	}
+}
+
+func TestRemoveRelationship(t *testing.T) {
+	tests := []struct {
+		name        string
+		direction   string
+		toField     string
+		deleted     int64
+		wantPattern string
+		wantErr     error
+		wantErrText string
+	}{
+		{name: "outgoing", direction: "->", toField: "id", deleted: 1, wantPattern: "MATCH (n)-[e:HAS]->(m) DELETE e"},
+		{name: "incoming", direction: "<-", toField: "id", deleted: 1, wantPattern: "MATCH (n)<-[e:HAS]-(m) DELETE e"},
+		{name: "either direction", toField: "id", deleted: 2, wantPattern: "MATCH (n)-[e:HAS]-(m) DELETE e"},
+		{name: "matched on a property", direction: "->", toField: "name", deleted: 1, wantPattern: "MATCH (m:City) WHERE m.name = $toValue"},
+		{name: "no such relationship", direction: "->", toField: "id", wantErr: ErrNotFound},
+		{name: "invalid direction", direction: "=>", toField: "id", wantErrText: "invalid relationship direction"},
+		{name: "invalid field", direction: "->", toField: "name) DETACH DELETE (m", wantErrText: "invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, func(neotest.Query) neotest.Response {
+				return neotest.Response{Records: []*neo4j.Record{neotest.Record("deleted", tt.deleted)}}
+			})
+
+			zone := &Zone{}
+			err := zone.RemoveRelationship(context.Background(), "id", "4:db:3", RelHas, tt.direction, "City", tt.toField, "4:db:4")
+			switch {
+			case tt.wantErrText != "":
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrText) {
+					t.Fatalf("RemoveRelationship() error = %v, want %q", err, tt.wantErrText)
+				}
+				if len(driver.Queries()) != 0 {
+					t.Errorf("RemoveRelationship() ran %d queries, want none", len(driver.Queries()))
+				}
+				return
+			case !errors.Is(err, tt.wantErr):
+				t.Fatalf("RemoveRelationship() error = %v, want %v", err, tt.wantErr)
+			}
+
+			query := driver.Queries()[0]
+			if query.AccessMode != neo4j.AccessModeWrite {
+				t.Errorf("RemoveRelationship() ran in access mode %v, want write", query.AccessMode)
+			}
+			// Only the edge is deleted, never the nodes at either end.
+			if strings.Contains(query.Cypher, "DELETE n") || strings.Contains(query.Cypher, "DELETE m") {
+				t.Errorf("RemoveRelationship() ran %q, want it to keep both nodes", query.Cypher)
+			}
+			if tt.wantPattern != "" && !strings.Contains(query.Cypher, tt.wantPattern) {
+				t.Errorf("RemoveRelationship() ran %q, want %q", query.Cypher, tt.wantPattern)
+			}
+			if query.Params["fromValue"] != "4:db:3" || query.Params["toValue"] != "4:db:4" {
+				t.Errorf("RemoveRelationship() params = %v", query.Params)
+			}
+		})
+	}
+}
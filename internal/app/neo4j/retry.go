@@ -0,0 +1,55 @@
+package neo
+
+import (
+	"context"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// MaxRetryTime is how long runRead and runWrite keep retrying a transaction that failed with a transient
+// error, ie: a deadlock, once the driver's own retries (DriverConfig.MaxTransactionRetryTime) are exhausted.
+// Retries back off exponentially from retryInitialDelay, up to retryMaxDelay.
+// It is 0 by default, leaving MaxTransactionRetryTime as the single retry bound; set it at startup to retry longer
+// than the driver does, ie: for batch imports contending with each other. A transaction is then retried for up to
+// MaxTransactionRetryTime + MaxRetryTime.
+var MaxRetryTime time.Duration
+
+const (
+	retryInitialDelay = 100 * time.Millisecond
+	retryMaxDelay     = 2 * time.Second
+)
+
+// runRead runs work in a managed read transaction of the session, see MaxRetryTime.
+func runRead(ctx context.Context, session neo4j.SessionWithContext, work neo4j.ManagedTransactionWork) (interface{}, error) {
+	return retry(ctx, func() (interface{}, error) {
+		return session.ExecuteRead(ctx, work)
+	})
+}
+
+// runWrite runs work in a managed write transaction of the session, see MaxRetryTime.
+func runWrite(ctx context.Context, session neo4j.SessionWithContext, work neo4j.ManagedTransactionWork) (interface{}, error) {
+	return retry(ctx, func() (interface{}, error) {
+		return session.ExecuteWrite(ctx, work)
+	})
+}
+
+// retry calls execute again while it fails with a retryable error and MaxRetryTime has not elapsed.
+func retry(ctx context.Context, execute func() (interface{}, error)) (interface{}, error) {
+	deadline := time.Now().Add(MaxRetryTime)
+	delay := retryInitialDelay
+
+	for {
+		result, err := execute()
+		if err == nil || !neo4j.IsRetryable(err) || time.Now().Add(delay).After(deadline) {
+			return result, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, retryMaxDelay)
+	}
+}
@@ -0,0 +1,126 @@
+package neo
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"api/internal/app/neo4j/neotest"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	neo4jconfig "github.com/neo4j/neo4j-go-driver/v5/neo4j/config"
+)
+
+func TestRunRetries(t *testing.T) {
+	deadlock := &neo4j.Neo4jError{Code: "Neo.TransientError.Transaction.DeadlockDetected", Msg: "deadlock"}
+	syntax := &neo4j.Neo4jError{Code: "Neo.ClientError.Statement.SyntaxError", Msg: "syntax"}
+
+	tests := []struct {
+		name         string
+		run          func(ctx context.Context, session neo4j.SessionWithContext, work neo4j.ManagedTransactionWork) (interface{}, error)
+		maxRetryTime time.Duration
+		failures     int
+		err          error
+		wantErr      error
+		wantCalls    int
+	}{
+		{name: "read succeeds", run: runRead, wantCalls: 1},
+		{name: "write succeeds", run: runWrite, wantCalls: 1},
+		// By default the driver's own retries, bounded by MaxTransactionRetryTime, are the only ones:
+		// the fake does not retry, so any extra call would come from a retry loop of ours.
+		{name: "read transient error left to the driver", run: runRead, failures: -1, err: deadlock, wantErr: deadlock, wantCalls: 1},
+		{name: "write transient error left to the driver", run: runWrite, failures: -1, err: deadlock, wantErr: deadlock, wantCalls: 1},
+		{name: "transient error retried", run: runWrite, maxRetryTime: time.Second, failures: 1, err: deadlock, wantCalls: 2},
+		{name: "retries bounded by MaxRetryTime", run: runRead, maxRetryTime: 150 * time.Millisecond, failures: -1, err: deadlock, wantErr: deadlock, wantCalls: 2},
+		{name: "client error not retried", run: runWrite, maxRetryTime: time.Second, failures: -1, err: syntax, wantErr: syntax, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			MaxRetryTime = tt.maxRetryTime
+			t.Cleanup(func() { MaxRetryTime = 0 })
+
+			calls := 0
+			driver := neotest.NewDriver(func(neotest.Query) neotest.Response {
+				calls++
+				if tt.failures < 0 || calls <= tt.failures {
+					return neotest.Response{Err: tt.err}
+				}
+				return neotest.Response{}
+			})
+			ctx := context.Background()
+			session := driver.NewSession(ctx, neo4j.SessionConfig{})
+
+			_, err := tt.run(ctx, session, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+				_, err := tx.Run(ctx, "RETURN 1", nil)
+				return nil, err
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls || driver.Transactions() != tt.wantCalls {
+				t.Errorf("work ran %d times in %d transactions, want %d", calls, driver.Transactions(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestDriverConfigApply(t *testing.T) {
+	tests := []struct {
+		name   string
+		config DriverConfig
+		want   time.Duration
+	}{
+		{name: "driver default kept", want: 30 * time.Second},
+		{name: "retry time set", config: DriverConfig{MaxTransactionRetryTime: 5 * time.Second}, want: 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := neo4jconfig.Config{MaxTransactionRetryTime: 30 * time.Second}
+			tt.config.apply(&config)
+			if config.MaxTransactionRetryTime != tt.want {
+				t.Errorf("MaxTransactionRetryTime = %v, want %v", config.MaxTransactionRetryTime, tt.want)
+			}
+		})
+	}
+}
+
+func TestDriverConfigFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "unset"},
+		{name: "duration", value: "5s", want: 5 * time.Second},
+		{name: "invalid", value: "five seconds", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NEO4J_MAX_TRANSACTION_RETRY_TIME", tt.value)
+
+			config, err := driverConfigFromEnv()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("driverConfigFromEnv() error = %v, want error %v", err, tt.wantErr)
+			}
+			if config.MaxTransactionRetryTime != tt.want {
+				t.Errorf("MaxTransactionRetryTime = %v, want %v", config.MaxTransactionRetryTime, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewDriverWithoutEnvFile(t *testing.T) {
+	// The package directory has no .env file; the settings are read from the environment alone.
+	t.Setenv("NEO4J_MAX_CONNECTION_POOL_SIZE", "many")
+
+	_, err := NewDriver()
+	if err == nil || !strings.Contains(err.Error(), "invalid NEO4J_MAX_CONNECTION_POOL_SIZE") {
+		t.Errorf("NewDriver() error = %v, want the invalid NEO4J_MAX_CONNECTION_POOL_SIZE error", err)
+	}
+}
@@ -0,0 +1,126 @@
+package neo
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+/*
+LabelSchema describes a registered model: its label, its properties and its relationships.
+*/
+type LabelSchema struct {
+	Label         string               `json:"label"`
+	Properties    []PropertySchema     `json:"properties"`
+	Relationships []RelationshipSchema `json:"relationships"`
+}
+
+/*
+PropertySchema describes a node property, with the JSON type of its values.
+*/
+type PropertySchema struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+/*
+RelationshipSchema describes a relationship declared by a rel tag.
+*/
+type RelationshipSchema struct {
+	Field     string `json:"field"`
+	Type      string `json:"type"`
+	Direction string `json:"direction"`
+	Target    string `json:"target"`
+	Many      bool   `json:"many"`
+}
+
+/*
+Schema returns the schema of every registered model, sorted by label.
+Properties are read from the node tags and relationships from the rel tags.
+
+Example usage:
+
+	for _, label := range Schema() {
+		fmt.Println(label.Label, label.Properties)
+	}
+*/
+func Schema() []LabelSchema {
+	labels := make([]LabelSchema, 0, len(modelRegistry))
+	for label, modelType := range modelRegistry {
+		labels = append(labels, describeModel(label, modelType))
+	}
+
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].Label < labels[j].Label
+	})
+	return labels
+}
+
+func describeModel(label string, modelType reflect.Type) LabelSchema {
+	schema := LabelSchema{
+		Label:         label,
+		Properties:    make([]PropertySchema, 0),
+		Relationships: make([]RelationshipSchema, 0),
+	}
+
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+
+		if name := nodeTagName(field); name != "" {
+			schema.Properties = append(schema.Properties, PropertySchema{
+				Name: name,
+				Type: jsonType(field.Type),
+			})
+			continue
+		}
+
+		tag, ok := parseRelTag(field)
+		if !ok {
+			continue
+		}
+
+		schema.Relationships = append(schema.Relationships, RelationshipSchema{
+			Field:     jsonName(field),
+			Type:      tag.relType,
+			Direction: tag.direction,
+			Target:    tag.label,
+			Many:      field.Type.Kind() == reflect.Slice,
+		})
+	}
+
+	return schema
+}
+
+// jsonName returns the name of the field in JSON output.
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// jsonType returns the JSON type of values of the given Go type.
+func jsonType(t reflect.Type) string {
+	if t == reflect.TypeOf(time.Time{}) {
+		return "string"
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Ptr:
+		return jsonType(t.Elem())
+	}
+	return "object"
+}
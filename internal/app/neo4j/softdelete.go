@@ -0,0 +1,76 @@
+package neo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// deletedProperty and deletedAtProperty are the internal properties marking a soft-deleted node, see DeleteOptions.SoftDelete.
+const (
+	deletedProperty   = "deleted"
+	deletedAtProperty = "deletedAt"
+)
+
+// notDeleted returns the condition excluding a soft-deleted node bound to binding.
+func notDeleted(binding string) string {
+	return fmt.Sprintf("coalesce(%s.%s, false) = false", binding, deletedProperty)
+}
+
+// softDeleteQuery returns the query flagging the node matched by the delete query as deleted, instead of deleting it.
+func softDeleteQuery(match string) string {
+	return fmt.Sprintf("%s SET n.%s = true, n.%s = datetime(), %s", match, deletedProperty, deletedAtProperty, touchUpdatedAt)
+}
+
+/*
+@method Restore
+
+@description Restore a node soft-deleted with DeleteOptions.SoftDelete, so queries return it again.
+ErrNotFound is returned when no node matches.
+
+@params field string - The field name to search for in the database, or "id" for the elementId.
+
+@params value interface{} - The value to search for in the database.
+
+@example
+
+	err := dbWorld.Restore(ctx, "elementID", worldID)
+	if err != nil {
+		log.Fatal(err)
+	}
+*/
+func (b *NeoBaseModel[T]) Restore(ctx context.Context, field string, value interface{}) error {
+	if err := validateIdentifiers(field); err != nil {
+		return err
+	}
+	if err := b.initDriver(); err != nil {
+		return err
+	}
+
+	session := b.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close(ctx)
+
+	query := fmt.Sprintf("MATCH (n:%s) WHERE %s SET n.%s = false, %s REMOVE n.%s RETURN count(n) AS restored",
+		b.Label, nodeMatch("n", field, "value"), deletedProperty, touchUpdatedAt, deletedAtProperty)
+	params := map[string]interface{}{
+		"value": queryParam(value),
+	}
+
+	_, err := runWrite(ctx, session, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		res, err := tx.Run(ctx, query, params)
+		if err != nil {
+			return nil, err
+		}
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if restored, _ := record.Get("restored"); restored == int64(0) {
+			return nil, ErrNotFound
+		}
+		return nil, nil
+	})
+
+	return err
+}
@@ -0,0 +1,146 @@
+package neo
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+/*
+nodeTagName returns the property name of a field's node tag, without its options.
+
+The node tag has the form `node:"name[,option...]"`. Supported options:
+  - trim: leading and trailing whitespace is removed from string values on create and update.
+  - collapse: runs of internal whitespace are collapsed into a single space; implies trim.
+*/
+func nodeTagName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("node"), ",")
+	return name
+}
+
+// nodeTagHasOption reports whether the field's node tag carries the given option.
+func nodeTagHasOption(field reflect.StructField, option string) bool {
+	_, options, found := strings.Cut(field.Tag.Get("node"), ",")
+	if !found {
+		return false
+	}
+	for _, o := range strings.Split(options, ",") {
+		if strings.TrimSpace(o) == option {
+			return true
+		}
+	}
+	return false
+}
+
+// isZeroTime reports whether a field value is the zero time.Time. Zero times are not written,
+// so an unset timestamp is left absent on the node rather than stored as year 1.
+func isZeroTime(value interface{}) bool {
+	t, ok := value.(time.Time)
+	return ok && t.IsZero()
+}
+
+// normalizeProperties applies the node tag normalization options of modelType to properties keyed by node tag.
+func normalizeProperties(modelType reflect.Type, properties map[string]interface{}) map[string]interface{} {
+	normalized := make(map[string]interface{}, len(properties))
+	for key, value := range properties {
+		normalized[key] = value
+	}
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		if value, ok := normalized[nodeTagName(field)]; ok {
+			normalized[nodeTagName(field)] = normalizeValue(field, value)
+		}
+	}
+	return normalized
+}
+
+// normalizeValue applies the node tag's normalization options to a string field value.
+// Values of other types are returned unchanged.
+func normalizeValue(field reflect.StructField, value interface{}) interface{} {
+	str, ok := value.(string)
+	if !ok {
+		return value
+	}
+
+	if nodeTagHasOption(field, "collapse") {
+		return strings.Join(strings.Fields(str), " ")
+	}
+	if nodeTagHasOption(field, "trim") {
+		return strings.TrimSpace(str)
+	}
+	return value
+}
+
+/*
+TagStrategy is the naming rule RegisterModel enforces between the node and json tags of a model.
+*/
+type TagStrategy int
+
+const (
+	// TagStrategyNone disables tag validation.
+	TagStrategyNone TagStrategy = iota
+	// TagStrategyMatchJSON requires a field's node tag name to equal its json tag name.
+	TagStrategyMatchJSON
+	// TagStrategyCamelCase requires TagStrategyMatchJSON and lowerCamelCase names, ie: userID, not user_id or UserID.
+	TagStrategyCamelCase
+)
+
+var tagStrategy = TagStrategyMatchJSON
+
+/*
+SetTagStrategy sets the naming rule enforced by RegisterModel. It defaults to TagStrategyMatchJSON.
+It must be called before the models are registered.
+*/
+func SetTagStrategy(strategy TagStrategy) {
+	tagStrategy = strategy
+}
+
+/*
+ValidateModelTags checks the node and json tags of a model against the configured TagStrategy.
+The model must be a struct or a pointer to a struct. It returns an error describing the first inconsistent field.
+*/
+func ValidateModelTags(model interface{}) error {
+	modelType := reflect.TypeOf(model)
+	if modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+	return validateTagStrategy(modelType, tagStrategy)
+}
+
+func validateTagStrategy(modelType reflect.Type, strategy TagStrategy) error {
+	if strategy == TagStrategyNone {
+		return nil
+	}
+
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		name := nodeTagName(field)
+		if name == "" {
+			continue
+		}
+
+		jsonTag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if jsonTag != "" && jsonTag != "-" && jsonTag != name {
+			return fmt.Errorf("%s.%s: node tag %q does not match json tag %q", modelType.Name(), field.Name, name, jsonTag)
+		}
+
+		if strategy == TagStrategyCamelCase && !isLowerCamelCase(name) {
+			return fmt.Errorf("%s.%s: node tag %q is not lowerCamelCase", modelType.Name(), field.Name, name)
+		}
+	}
+	return nil
+}
+
+func isLowerCamelCase(name string) bool {
+	for i, r := range name {
+		if i == 0 && !unicode.IsLower(r) {
+			return false
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return name != ""
+}
@@ -0,0 +1,144 @@
+package neo
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"api/internal/app/neo4j/neotest"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+func TestNormalizeValue(t *testing.T) {
+	type normalized struct {
+		Plain    string `node:"plain"`
+		Trimmed  string `node:"trimmed,trim"`
+		Collapse string `node:"collapse,collapse"`
+		Count    int    `node:"count,trim"`
+	}
+
+	tests := []struct {
+		field string
+		value any
+		want  any
+	}{
+		{field: "Plain", value: "  Atlantis  ", want: "  Atlantis  "},
+		{field: "Trimmed", value: "  Atlantis  ", want: "Atlantis"},
+		{field: "Trimmed", value: " New   Atlantis\t", want: "New   Atlantis"},
+		{field: "Collapse", value: " New \t  Atlantis\n", want: "New Atlantis"},
+		{field: "Count", value: 3, want: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			field, _ := reflect.TypeOf(normalized{}).FieldByName(tt.field)
+			if got := normalizeValue(field, tt.value); got != tt.want {
+				t.Errorf("normalizeValue(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrimmedNameStored(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(world *World) error
+	}{
+		{
+			name:  "create",
+			write: func(world *World) error { return world.Create(context.Background(), world, CreateOptions{}) },
+		},
+		{
+			name: "update",
+			write: func(world *World) error {
+				world.ID = "4:db:1"
+				return world.Update(context.Background(), world, CreateOptions{})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, func(neotest.Query) neotest.Response {
+				node := neotest.Node("4:db:1", []string{"World"}, map[string]any{"name": "Atlantis"})
+				return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", node)}}
+			})
+
+			world := &World{Name: "  Atlantis \t", Description: "  kept as is  "}
+			if err := tt.write(world); err != nil {
+				t.Fatalf("%s error = %v", tt.name, err)
+			}
+
+			params := driver.Queries()[0].Params
+			if params["name"] != "Atlantis" {
+				t.Errorf("%s stored name %q, want %q", tt.name, params["name"], "Atlantis")
+			}
+			if params["description"] != "  kept as is  " {
+				t.Errorf("%s stored description %q, want it untouched", tt.name, params["description"])
+			}
+		})
+	}
+}
+
+func TestValidateTagStrategy(t *testing.T) {
+	type matching struct {
+		UserID int64  `node:"userID" json:"userID"`
+		Name   string `node:"name,trim" json:"name,omitempty"`
+		Secret string `node:"secret" json:"-"`
+	}
+	type mismatched struct {
+		UserID int64 `node:"userID" json:"userId"`
+	}
+	type snakeCase struct {
+		UserID int64 `node:"user_id" json:"user_id"`
+	}
+
+	tests := []struct {
+		name     string
+		model    any
+		strategy TagStrategy
+		wantErr  string
+	}{
+		{name: "matching tags", model: matching{}, strategy: TagStrategyMatchJSON},
+		{name: "matching camel case", model: matching{}, strategy: TagStrategyCamelCase},
+		{name: "mismatched tags", model: mismatched{}, strategy: TagStrategyMatchJSON, wantErr: `node tag "userID" does not match json tag "userId"`},
+		{name: "mismatched tags unchecked", model: mismatched{}, strategy: TagStrategyNone},
+		{name: "snake case matching", model: snakeCase{}, strategy: TagStrategyMatchJSON},
+		{name: "snake case not camel case", model: snakeCase{}, strategy: TagStrategyCamelCase, wantErr: `node tag "user_id" is not lowerCamelCase`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTagStrategy(reflect.TypeOf(tt.model), tt.strategy)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateTagStrategy() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateTagStrategy() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegisterModelRejectsMismatchedTags(t *testing.T) {
+	type Mismatched struct {
+		NeoBaseModel[Mismatched]
+		ID     string `node:"id" json:"id"`
+		UserID int64  `node:"userID" json:"userId"`
+	}
+
+	defer func() {
+		if recovered := recover(); recovered == nil {
+			t.Error("RegisterModel() did not panic on mismatched tags")
+		}
+		if _, ok := modelRegistry["Mismatched"]; ok {
+			t.Error("RegisterModel() registered a model with mismatched tags")
+		}
+	}()
+	RegisterModel("Mismatched", &Mismatched{})
+}
@@ -0,0 +1,172 @@
+package neo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+/*
+Tx runs model operations within the transaction of WithTransaction.
+Its methods take a pointer to a model embedding NeoBaseModel, ie: &World{}, and behave like the model's own methods.
+*/
+type Tx struct {
+	ctx context.Context
+	tx  neo4j.ManagedTransaction
+}
+
+// txModel is implemented by every model embedding NeoBaseModel, through the promoted methods below.
+// The model is passed again as an interface{} since the embedded NeoBaseModel cannot reach the model embedding it.
+type txModel interface {
+	txCreate(ctx context.Context, tx neo4j.ManagedTransaction, model interface{}, options CreateOptions) error
+	txUpdate(ctx context.Context, tx neo4j.ManagedTransaction, model interface{}, options CreateOptions) error
+	txDelete(ctx context.Context, tx neo4j.ManagedTransaction, model interface{}, field string, value interface{}, options DeleteOptions) error
+	txRemoveRelationship(ctx context.Context, tx neo4j.ManagedTransaction, fromField string, fromValue interface{}, rel string, direction string, toLabel string, toField string, toValue interface{}) error
+}
+
+/*
+@method WithTransaction
+
+@description Run fn in a single write transaction, committed when fn returns nil and rolled back otherwise.
+Like any managed transaction, fn is retried on transient errors, so it must not have side effects outside of tx.
+
+@params fn func(tx Tx) error - The operations to run atomically.
+
+@example
+
+	world := &World{Name: "Atlantis"}
+	err := WithTransaction(ctx, func(tx Tx) error {
+		if err := tx.Create(world, CreateOptions{}); err != nil {
+			return err
+		}
+		return tx.Update(world, CreateOptions{
+			Field:        "userID",
+			Value:        int64(1),
+			Label:        "User",
+			Rel:          RelOwns,
+			RelDirection: "<-",
+			MustExist:    true,
+		})
+	})
+*/
+func WithTransaction(ctx context.Context, fn func(tx Tx) error) error {
+	driver, err := getDriver()
+	if err != nil {
+		return err
+	}
+
+	session := driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close(ctx)
+
+	_, err = runWrite(ctx, session, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		return nil, fn(Tx{ctx: ctx, tx: tx})
+	})
+	return err
+}
+
+// Create creates the model, as NeoBaseModel.Create.
+func (t Tx) Create(model interface{}, options CreateOptions) error {
+	m, err := asTxModel(model)
+	if err != nil {
+		return err
+	}
+	return m.txCreate(t.ctx, t.tx, model, options)
+}
+
+// Update updates the model matched on its elementId, as NeoBaseModel.Update.
+func (t Tx) Update(model interface{}, options CreateOptions) error {
+	m, err := asTxModel(model)
+	if err != nil {
+		return err
+	}
+	return m.txUpdate(t.ctx, t.tx, model, options)
+}
+
+// Delete deletes the node of the model's label matched on field, as NeoBaseModel.Delete.
+func (t Tx) Delete(model interface{}, field string, value interface{}, options DeleteOptions) error {
+	m, err := asTxModel(model)
+	if err != nil {
+		return err
+	}
+	return m.txDelete(t.ctx, t.tx, model, field, value, options)
+}
+
+// RemoveRelationship deletes relationships from a node of the model's label, as NeoBaseModel.RemoveRelationship.
+func (t Tx) RemoveRelationship(model interface{}, fromField string, fromValue interface{}, rel string, direction string, toLabel string, toField string, toValue interface{}) error {
+	m, err := asTxModel(model)
+	if err != nil {
+		return err
+	}
+	return m.txRemoveRelationship(t.ctx, t.tx, fromField, fromValue, rel, direction, toLabel, toField, toValue)
+}
+
+func asTxModel(model interface{}) (txModel, error) {
+	m, ok := model.(txModel)
+	if !ok {
+		return nil, fmt.Errorf("%T does not embed NeoBaseModel", model)
+	}
+	return m, nil
+}
+
+// txModelOf asserts the model passed to a Tx method is a *T.
+func txModelOf[T any](model interface{}) (*T, error) {
+	typed, ok := model.(*T)
+	if !ok {
+		return nil, fmt.Errorf("expected %T, got %T", new(T), model)
+	}
+	return typed, nil
+}
+
+// txLabel sets and validates the label of the model before it is used in a transaction.
+func (b *NeoBaseModel[T]) txLabel() error {
+	b.initLabel()
+	return validateIdentifiers(b.Label)
+}
+
+func (b *NeoBaseModel[T]) txCreate(ctx context.Context, tx neo4j.ManagedTransaction, model interface{}, options CreateOptions) error {
+	typed, err := txModelOf[T](model)
+	if err != nil {
+		return err
+	}
+	if err := errors.Join(b.txLabel(), options.validate()); err != nil {
+		return err
+	}
+	_, err = b.createTx(ctx, tx, typed, options)
+	return err
+}
+
+func (b *NeoBaseModel[T]) txUpdate(ctx context.Context, tx neo4j.ManagedTransaction, model interface{}, options CreateOptions) error {
+	typed, err := txModelOf[T](model)
+	if err != nil {
+		return err
+	}
+	if err := errors.Join(b.txLabel(), options.validate()); err != nil {
+		return err
+	}
+	query, params, err := b.buildUpdateQuery(typed, "id", options)
+	if err != nil {
+		return err
+	}
+	_, err = runUpdate(ctx, tx, query, params)
+	return err
+}
+
+func (b *NeoBaseModel[T]) txDelete(ctx context.Context, tx neo4j.ManagedTransaction, model interface{}, field string, value interface{}, options DeleteOptions) error {
+	typed, err := txModelOf[T](model)
+	if err != nil {
+		return err
+	}
+	if err := errors.Join(b.txLabel(), validateIdentifiers(field)); err != nil {
+		return err
+	}
+	return b.deleteTx(ctx, tx, typed, field, value, options)
+}
+
+func (b *NeoBaseModel[T]) txRemoveRelationship(ctx context.Context, tx neo4j.ManagedTransaction, fromField string, fromValue interface{}, rel string, direction string, toLabel string, toField string, toValue interface{}) error {
+	if err := b.txLabel(); err != nil {
+		return err
+	}
+	return b.removeRelationshipTx(ctx, tx, fromField, fromValue, rel, direction, toLabel, toField, toValue)
+}
@@ -0,0 +1,204 @@
+package neo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+/*
+Node is a raw node returned by queries that are not bound to a single model type, such as Ancestry and FindOrphans.
+It carries the node's elementId, its labels and its raw properties.
+*/
+type Node struct {
+	ID         string                 `json:"id"`
+	Labels     []string               `json:"labels"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+/*
+@method Ancestry
+
+@description Walk inbound HAS relationships from a node up to its World and return the ordered chain of nodes, root first.
+If the node has no ancestry, the chain only contains the node itself.
+
+@params elementID string - The elementId of the node to start from.
+
+@returns ([]Node, error) - The ordered chain of nodes, or an error if the node was not found.
+
+@example
+
+	// World > Continent > Zone > City
+	city := &City{}
+	chain, err := city.Ancestry(ctx, "4:abc:12")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(chain)
+*/
+func (b *NeoBaseModel[T]) Ancestry(ctx context.Context, elementID string) ([]Node, error) {
+	if err := b.initDriver(); err != nil {
+		return nil, err
+	}
+
+	session := b.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+	defer session.Close(ctx)
+
+	query := fmt.Sprintf("MATCH (n:%s) WHERE elementId(n) = $value AND %s"+
+		" OPTIONAL MATCH p = (w:World)-[:%s*]->(n) WHERE all(a IN nodes(p) WHERE %s)"+
+		" WITH n, p ORDER BY length(p) DESC LIMIT 1"+
+		" RETURN CASE WHEN p IS NULL THEN [n] ELSE nodes(p) END AS chain", b.Label, notDeleted("n"), RelHas, notDeleted("a"))
+	params := map[string]interface{}{
+		"value": elementID,
+	}
+
+	result, err := runRead(ctx, session, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		res, err := tx.Run(ctx, query, params)
+		if err != nil {
+			return nil, err
+		}
+
+		if res.Next(ctx) {
+			chain, ok := res.Record().Get("chain")
+			if !ok {
+				return nil, fmt.Errorf("failed to retrieve 'chain' from record")
+			}
+			return chain, nil
+		}
+
+		if err := res.Err(); err != nil {
+			return nil, err
+		}
+		return nil, ErrNotFound
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, ok := result.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type: %T", result)
+	}
+
+	ancestry := make([]Node, 0, len(nodes))
+	for _, value := range nodes {
+		node, ok := value.(neo4j.Node)
+		if !ok {
+			return nil, fmt.Errorf("unexpected chain entry type: %T", value)
+		}
+		ancestry = append(ancestry, newNode(node))
+	}
+
+	return ancestry, nil
+}
+
+func newNode(node neo4j.Node) Node {
+	return Node{
+		ID:         node.ElementId,
+		Labels:     node.Labels,
+		Properties: node.Props,
+	}
+}
+
+// ErrUnknownType is returned by FindOrphans when the label is not a registered model or the relationship type
+// is not registered.
+var ErrUnknownType = errors.New("unknown type")
+
+/*
+FindOrphans returns the nodes of a registered label lacking an inbound relationship of the expected type.
+It is meant for cleanup tooling, ie: finding continents or zones left without a parent after a deletion.
+
+Example usage:
+
+	orphans, err := FindOrphans(ctx, "Zone", RelHas)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(len(orphans), "orphaned zones")
+*/
+func FindOrphans(ctx context.Context, label string, expectedParentRel string) ([]Node, error) {
+	if _, ok := modelRegistry[label]; !ok {
+		return nil, fmt.Errorf("%w: label %s", ErrUnknownType, label)
+	}
+	if !relationshipTypes[expectedParentRel] {
+		return nil, fmt.Errorf("%w: relationship type %s", ErrUnknownType, expectedParentRel)
+	}
+
+	driver, err := getDriver()
+	if err != nil {
+		return nil, err
+	}
+
+	session := driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+	defer session.Close(ctx)
+
+	query := fmt.Sprintf("MATCH (n:%s) WHERE NOT ()-[:%s]->(n) AND %s RETURN n", label, expectedParentRel, notDeleted("n"))
+
+	result, err := runRead(ctx, session, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		res, err := tx.Run(ctx, query, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		orphans := make([]Node, 0)
+		for res.Next(ctx) {
+			value, _ := res.Record().Get("n")
+			node, ok := value.(neo4j.Node)
+			if !ok {
+				return nil, fmt.Errorf("failed to cast result to neo4j.Node")
+			}
+			orphans = append(orphans, newNode(node))
+		}
+		return orphans, res.Err()
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	orphans, _ := result.([]Node)
+	return orphans, nil
+}
+
+// maxIDPrefixResults bounds the number of nodes returned by FindByIDPrefix.
+const maxIDPrefixResults = 100
+
+/*
+@method FindByIDPrefix
+
+@description Find the nodes whose elementId starts with a prefix, for admin and debugging tools holding a partial id.
+This scans every node of the label, so use it sparingly and never on request paths serving regular traffic.
+At most 100 nodes are returned, with their directly related nodes.
+
+@params prefix string - The start of the elementId, ie: "4:2f1c".
+
+@returns ([]T, error) - The matching nodes, an empty slice when there are none.
+
+@example
+
+	cities, err := dbCity.FindByIDPrefix(ctx, "4:2f1c")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(len(cities))
+*/
+func (b *NeoBaseModel[T]) FindByIDPrefix(ctx context.Context, prefix string) ([]T, error) {
+	if prefix == "" {
+		return nil, fmt.Errorf("an id prefix is required")
+	}
+
+	var models []T
+	err := b.FindAll(ctx, &models, "", nil).
+		Where("elementID", "STARTS WITH", prefix).
+		Populate(PopulateOptions{Depth: 1, Limit: maxIDPrefixResults})
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	if models == nil {
+		models = []T{}
+	}
+	return models, nil
+}
@@ -0,0 +1,187 @@
+package neo
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"api/internal/app/neo4j/neotest"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+func TestAncestry(t *testing.T) {
+	world := neotest.Node("4:db:1", []string{"World"}, map[string]any{"name": "Atlantis"})
+	continent := neotest.Node("4:db:2", []string{"Continent"}, map[string]any{"name": "North"})
+	zone := neotest.Node("4:db:3", []string{"Zone"}, map[string]any{"name": "Coast"})
+	city := neotest.Node("4:db:4", []string{"City"}, map[string]any{"name": "Port Royal"})
+
+	tests := []struct {
+		name    string
+		records []*neo4j.Record
+		want    []string
+		wantErr error
+	}{
+		{
+			name:    "seeded hierarchy root first",
+			records: []*neo4j.Record{neotest.Record("chain", []any{world, continent, zone, city})},
+			want:    []string{"4:db:1", "4:db:2", "4:db:3", "4:db:4"},
+		},
+		{
+			name:    "node without ancestry",
+			records: []*neo4j.Record{neotest.Record("chain", []any{city})},
+			want:    []string{"4:db:4"},
+		},
+		{
+			name:    "unknown node",
+			wantErr: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, func(neotest.Query) neotest.Response {
+				return neotest.Response{Records: tt.records}
+			})
+
+			chain, err := (&City{}).Ancestry(context.Background(), "4:db:4")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Ancestry() error = %v, want %v", err, tt.wantErr)
+			}
+
+			var ids []string
+			for _, node := range chain {
+				ids = append(ids, node.ID)
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("Ancestry() = %v, want %v", ids, tt.want)
+			}
+
+			query := driver.Queries()[0]
+			if !strings.Contains(query.Cypher, "(w:World)-[:HAS*]->(n)") || query.Params["value"] != "4:db:4" {
+				t.Errorf("Ancestry() ran %q with %v", query.Cypher, query.Params)
+			}
+		})
+	}
+}
+
+func TestFindOrphans(t *testing.T) {
+	orphan := neotest.Node("4:db:3", []string{"Zone"}, map[string]any{"name": "Drifting Isles"})
+
+	tests := []struct {
+		name      string
+		label     string
+		rel       string
+		records   []*neo4j.Record
+		want      []string
+		wantQuery string
+		wantErr   bool
+	}{
+		{
+			name:      "seeded orphan",
+			label:     "Zone",
+			rel:       RelHas,
+			records:   []*neo4j.Record{neotest.Record("n", orphan)},
+			want:      []string{"4:db:3"},
+			wantQuery: "MATCH (n:Zone) WHERE NOT ()-[:HAS]->(n)",
+		},
+		{
+			name:      "no orphans",
+			label:     "Zone",
+			rel:       RelHas,
+			want:      []string{},
+			wantQuery: "MATCH (n:Zone) WHERE NOT ()-[:HAS]->(n)",
+		},
+		{name: "unknown label", label: "Planet", rel: RelHas, wantErr: true},
+		{name: "unknown relationship", label: "Zone", rel: "CONTAINS", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, func(neotest.Query) neotest.Response { return neotest.Response{Records: tt.records} })
+
+			orphans, err := FindOrphans(context.Background(), tt.label, tt.rel)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnknownType) {
+					t.Fatalf("FindOrphans() error = %v, want ErrUnknownType", err)
+				}
+				if len(driver.Queries()) != 0 {
+					t.Errorf("FindOrphans() ran %d queries, want none", len(driver.Queries()))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FindOrphans() error = %v", err)
+			}
+
+			ids := []string{}
+			for _, orphan := range orphans {
+				ids = append(ids, orphan.ID)
+			}
+			if !slices.Equal(ids, tt.want) || orphans == nil {
+				t.Errorf("FindOrphans() = %v, want %v", orphans, tt.want)
+			}
+			if query := driver.Queries()[0].Cypher; !strings.Contains(query, tt.wantQuery) {
+				t.Errorf("FindOrphans() ran %q, want it to contain %q", query, tt.wantQuery)
+			}
+		})
+	}
+}
+
+func TestFindByIDPrefix(t *testing.T) {
+	stored := []string{"4:2f1c:1", "4:2f1c:2", "4:9a7e:3"}
+
+	tests := []struct {
+		name    string
+		prefix  string
+		want    []string
+		wantErr bool
+	}{
+		{name: "matching prefix", prefix: "4:2f1c", want: []string{"4:2f1c:1", "4:2f1c:2"}},
+		{name: "full id", prefix: "4:9a7e:3", want: []string{"4:9a7e:3"}},
+		{name: "no match", prefix: "4:0000", want: []string{}},
+		{name: "empty prefix", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The fake matches the stored ids on the STARTS WITH condition as Neo4j would.
+			driver := useFakeDriver(t, func(query neotest.Query) neotest.Response {
+				var records []*neo4j.Record
+				for _, id := range stored {
+					if strings.HasPrefix(id, query.Params["where0"].(string)) {
+						node := neotest.Node(id, []string{"City"}, map[string]any{"name": id})
+						records = append(records, neotest.Record("n", node, "relatedNodes", []any{}))
+					}
+				}
+				return neotest.Response{Records: records}
+			})
+
+			cities, err := new(City).FindByIDPrefix(context.Background(), tt.prefix)
+			if tt.wantErr {
+				if err == nil || len(driver.Queries()) != 0 {
+					t.Fatalf("FindByIDPrefix() error = %v after %d queries, want an error and none", err, len(driver.Queries()))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FindByIDPrefix() error = %v", err)
+			}
+			ids := make([]string, len(cities))
+			for i, city := range cities {
+				ids[i] = city.ID
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("FindByIDPrefix() = %v, want %v", ids, tt.want)
+			}
+
+			// The scan is bounded.
+			query := driver.Queries()[0]
+			if !strings.Contains(query.Cypher, "elementId(n) STARTS WITH $where0") || query.Params["limit"] != maxIDPrefixResults {
+				t.Errorf("FindByIDPrefix() ran %q with %v", query.Cypher, query.Params)
+			}
+		})
+	}
+}
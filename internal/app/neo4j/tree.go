@@ -0,0 +1,242 @@
+package neo
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+/*
+TreeLimits bounds the size of a model tree accepted by ValidateTree.
+  - @property MaxDepth: The maximum nesting depth, the root being at depth 1. Zero means unlimited.
+  - @property MaxNodes: The maximum number of nodes in the tree, including the root. Zero means unlimited.
+*/
+type TreeLimits struct {
+	MaxDepth int
+	MaxNodes int
+}
+
+/*
+TreeProblem is a problem found by ValidateTree, located by the JSON path of the offending node, ie: continents[0].zones[2].
+*/
+type TreeProblem struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+/*
+ValidateTree checks a model tree against the given limits, without touching the database.
+The model must be a pointer to a struct. It returns every problem found, or nil if the tree is valid.
+Besides the limits, every node is checked for:
+  - enum values: a string field tagged enum:"a|b" must be empty or one of the listed values.
+
+The ids of the tree, ie: of a world exported by GET /api/world/:id, are not checked: CreateTree ignores them
+and gives every node the elementId it is created with.
+
+Example usage:
+
+	problems := ValidateTree(&world, TreeLimits{MaxDepth: 5, MaxNodes: 10000})
+	if len(problems) > 0 {
+		fmt.Println(problems)
+	}
+*/
+func ValidateTree(model interface{}, limits TreeLimits) []TreeProblem {
+	var problems []TreeProblem
+	count := 0
+	validateTreeNode(reflect.ValueOf(model).Elem(), "$", 1, limits, &count, &problems)
+
+	if limits.MaxNodes > 0 && count > limits.MaxNodes {
+		problems = append(problems, TreeProblem{
+			Path:    "$",
+			Message: fmt.Sprintf("tree has %d nodes, the maximum is %d", count, limits.MaxNodes),
+		})
+	}
+	return problems
+}
+
+func validateTreeNode(value reflect.Value, path string, depth int, limits TreeLimits, count *int, problems *[]TreeProblem) {
+	*count++
+
+	if limits.MaxDepth > 0 && depth > limits.MaxDepth {
+		*problems = append(*problems, TreeProblem{
+			Path:    path,
+			Message: fmt.Sprintf("tree is deeper than the maximum depth of %d", limits.MaxDepth),
+		})
+		return
+	}
+	validateTreeFields(value, path, problems)
+
+	forEachChild(value, func(field reflect.StructField, index int, child reflect.Value) {
+		childPath := fmt.Sprintf("%s.%s", path, jsonName(field))
+		if index >= 0 {
+			childPath = fmt.Sprintf("%s[%d]", childPath, index)
+		}
+		validateTreeNode(child, childPath, depth+1, limits, count, problems)
+	})
+}
+
+// treeEnumTag is the struct tag listing the values a string field accepts, separated by |.
+const treeEnumTag = "enum"
+
+// validateTreeFields checks the enum values of a node of a tree, see ValidateTree.
+func validateTreeFields(value reflect.Value, path string, problems *[]TreeProblem) {
+	modelType := value.Type()
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		if field.Type.Kind() != reflect.String {
+			continue
+		}
+		fieldValue := value.Field(i).String()
+
+		if allowed := field.Tag.Get(treeEnumTag); allowed != "" && fieldValue != "" {
+			values := strings.Split(allowed, "|")
+			if !slices.Contains(values, fieldValue) {
+				*problems = append(*problems, TreeProblem{
+					Path:    fmt.Sprintf("%s.%s", path, jsonName(field)),
+					Message: fmt.Sprintf("%q is not one of %s", fieldValue, strings.Join(values, ", ")),
+				})
+			}
+		}
+	}
+}
+
+// forEachChild calls fn for every non-nil related model of a rel-tagged field of value.
+// index is the position of the child in a slice field, or -1 for a single pointer field.
+func forEachChild(value reflect.Value, fn func(field reflect.StructField, index int, child reflect.Value)) {
+	modelType := value.Type()
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		if field.Tag.Get("rel") == "" {
+			continue
+		}
+
+		fieldValue := value.Field(i)
+		switch {
+		case fieldValue.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Ptr:
+			for j := 0; j < fieldValue.Len(); j++ {
+				if !fieldValue.Index(j).IsNil() {
+					fn(field, j, fieldValue.Index(j).Elem())
+				}
+			}
+		case fieldValue.Kind() == reflect.Ptr && field.Type.Elem().Kind() == reflect.Struct:
+			if !fieldValue.IsNil() {
+				fn(field, -1, fieldValue.Elem())
+			}
+		}
+	}
+}
+
+/*
+@method CreateTree
+
+@description Create a model and every related model reachable through its rel-tagged fields, in a single transaction.
+Each related model is created under the label of its type and linked to its parent following the rel tag.
+The root is related to another node following the options, as with Create.
+The ID fields of the whole tree are ignored and populated with the created elementIds. If any node fails, nothing is created.
+
+@params model *T - The root of the tree to create.
+
+@params options CreateOptions - Options for relating the root to another node.
+
+@example
+
+	world := &World{
+		Name:       "Atlantis",
+		Continents: []*Continent{{Name: "North"}},
+	}
+	err := world.CreateTree(ctx, world, CreateOptions{
+		Field:        "userID",
+		Value:        int64(1),
+		Label:        "User",
+		Rel:          RelOwns,
+		RelDirection: "<-",
+	})
+*/
+func (b *NeoBaseModel[T]) CreateTree(ctx context.Context, model *T, options CreateOptions) error {
+	if err := options.validate(); err != nil {
+		return err
+	}
+	if err := b.initDriver(); err != nil {
+		return err
+	}
+
+	session := b.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close(ctx)
+
+	_, err := runWrite(ctx, session, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		params := make(map[string]interface{})
+		relatedClause := buildRelatedClause(options, "CREATE", params)
+		template := "CREATE (n:%s) SET n = $props, " + touchUpdatedAt + strings.ReplaceAll(relatedClause, "%", "%%")
+		return nil, createTreeNode(ctx, tx, reflect.ValueOf(model).Elem(), b.Label, template, params)
+	})
+
+	return err
+}
+
+// createTreeNode creates the node of value with the given query template, then its children.
+// The template receives the label and must bind the created node to n.
+func createTreeNode(ctx context.Context, tx neo4j.ManagedTransaction, value reflect.Value, label string, template string, params map[string]interface{}) error {
+	params["props"] = nodeProperties(value)
+
+	res, err := tx.Run(ctx, fmt.Sprintf(template, label)+" RETURN n", params)
+	if err != nil {
+		return err
+	}
+	if !res.Next(ctx) {
+		if err := res.Err(); err != nil {
+			return fmt.Errorf("failed to create %s node: %w", label, err)
+		}
+		// Only the root can miss the node it is related to: children are matched to their created parent.
+		return fmt.Errorf("%w: failed to create %s node", ErrRelatedNotFound, label)
+	}
+	created, _ := res.Record().Get("n")
+	node, ok := created.(neo4j.Node)
+	if !ok {
+		return fmt.Errorf("failed to cast result to neo4j.Node")
+	}
+	if err := mapNodeToModelReflect(node, value.Addr().Interface()); err != nil {
+		return err
+	}
+
+	var childErr error
+	forEachChild(value, func(field reflect.StructField, index int, child reflect.Value) {
+		if childErr != nil {
+			return
+		}
+
+		tag, _ := parseRelTag(field)
+		relPattern := "CREATE (p)-[:%s]->(n)"
+		if tag.direction == "<-" {
+			relPattern = "CREATE (p)<-[:%s]-(n)"
+		}
+
+		childTemplate := "MATCH (p) WHERE elementId(p) = $parent CREATE (n:%s) SET n = $props, " + touchUpdatedAt + " " +
+			strings.ReplaceAll(fmt.Sprintf(relPattern, tag.relType), "%", "%%")
+		childErr = createTreeNode(ctx, tx, child, tag.label, childTemplate, map[string]interface{}{
+			"parent": node.ElementId,
+		})
+	})
+
+	return childErr
+}
+
+// nodeProperties returns the node-tagged properties of a model value, excluding the id.
+func nodeProperties(value reflect.Value) map[string]interface{} {
+	props := make(map[string]interface{})
+	modelType := value.Type()
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		name := nodeTagName(field)
+		if name == "" || name == "id" {
+			continue
+		}
+		if fieldValue := normalizeValue(field, value.Field(i).Interface()); !isZeroTime(fieldValue) {
+			props[name] = fieldValue
+		}
+	}
+	return props
+}
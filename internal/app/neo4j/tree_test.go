@@ -0,0 +1,66 @@
+package neo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateTree(t *testing.T) {
+	type Settlement struct {
+		NeoBaseModel[Settlement]
+		ID   string `node:"id" json:"id"`
+		Name string `node:"name" json:"name"`
+		Kind string `node:"kind" json:"kind" enum:"city|town|village"`
+	}
+	type Province struct {
+		NeoBaseModel[Province]
+		ID          string        `node:"id" json:"id"`
+		Name        string        `node:"name" json:"name"`
+		Settlements []*Settlement `rel:"HAS,->" json:"settlements"`
+		Capital     *Settlement   `rel:"HAS,->" json:"capital"`
+	}
+
+	tests := []struct {
+		name     string
+		province Province
+		limits   TreeLimits
+		want     []TreeProblem
+	}{
+		{
+			name: "valid tree",
+			province: Province{Name: "North", Capital: &Settlement{Name: "Hold", Kind: "city"}, Settlements: []*Settlement{
+				{Name: "Riverwood", Kind: "village"}, {Name: "Dawnstar"},
+			}},
+			limits: TreeLimits{MaxDepth: 2, MaxNodes: 4},
+		},
+		{
+			name:     "enum value",
+			province: Province{Name: "North", Settlements: []*Settlement{{Name: "Riverwood"}, {Name: "Hold", Kind: "metropolis"}}},
+			want:     []TreeProblem{{Path: "$.settlements[1].kind", Message: `"metropolis" is not one of city, town, village`}},
+		},
+		{
+			name:     "ids ignored",
+			province: Province{ID: "4:db:1", Name: "North", Capital: &Settlement{ID: "4:db:2", Name: "Hold"}},
+		},
+		{
+			name:     "too deep",
+			province: Province{Name: "North", Capital: &Settlement{Name: "Hold", Kind: "castle"}},
+			limits:   TreeLimits{MaxDepth: 1},
+			want:     []TreeProblem{{Path: "$.capital", Message: "tree is deeper than the maximum depth of 1"}},
+		},
+		{
+			name:     "too many nodes",
+			province: Province{Name: "North", Settlements: []*Settlement{{Name: "Riverwood"}, {Name: "Dawnstar"}}},
+			limits:   TreeLimits{MaxNodes: 2},
+			want:     []TreeProblem{{Path: "$", Message: "tree has 3 nodes, the maximum is 2"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if problems := ValidateTree(&tt.province, tt.limits); !reflect.DeepEqual(problems, tt.want) {
+				t.Errorf("ValidateTree() = %+v, want %+v", problems, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package neo
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+/*
+@method Upsert
+
+@description Create a node, or update it if a node with the same value for the match field already exists,
+so re-running an import does not duplicate nodes. See UpsertBy for composite keys.
+
+@params model *T - The model to upsert. Its ID field is populated with the stored node's elementId.
+
+@params matchField string - The node tag identifying an existing node, ie: name.
+
+@params options CreateOptions - Options for relating the node to another node. The relationship is merged, not duplicated.
+
+@example
+
+	world := &World{Name: "Atlantis", Type: "fantasy"}
+	err := world.Upsert(ctx, world, "name", CreateOptions{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(world.ID)
+*/
+func (b *NeoBaseModel[T]) Upsert(ctx context.Context, model *T, matchField string, options CreateOptions) error {
+	return b.UpsertBy(ctx, model, []string{matchField}, options)
+}
+
+/*
+@method UpsertBy
+
+@description Create a node, or update it if a node with the same values for all match fields already exists.
+The match fields form a composite key in the MERGE pattern. On create every node-tagged field is set,
+on match the remaining fields are updated. The stored node is mapped back onto the model.
+Every match field must have a value: a zero time.Time is not stored, so it cannot identify a node.
+A soft-deleted node matching the key is restored, as the key constraint below leaves no room for a second node.
+
+For the MERGE to be safe under concurrent writes, back the key with a matching constraint, e.g.
+
+	CREATE CONSTRAINT city_key IF NOT EXISTS FOR (n:City) REQUIRE (n.name, n.type) IS NODE KEY
+
+@params model *T - The model to upsert.
+
+@params matchFields []string - The node tags forming the key used to find an existing node.
+
+@params options CreateOptions - Options for relating the node to another node. The relationship is merged, not duplicated.
+
+@example
+
+	city := &City{Name: "Port Royal", Type: "port"}
+	err := city.UpsertBy(ctx, city, []string{"name", "type"}, CreateOptions{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(city.ID)
+*/
+func (b *NeoBaseModel[T]) UpsertBy(ctx context.Context, model *T, matchFields []string, options CreateOptions) error {
+	if len(matchFields) == 0 {
+		return fmt.Errorf("at least one match field is required")
+	}
+	if err := validateFields(reflect.TypeOf(*model), matchFields); err != nil {
+		return err
+	}
+	for _, field := range matchFields {
+		if field == "id" {
+			return fmt.Errorf("the id field cannot be used as an upsert key")
+		}
+	}
+
+	if err := options.validate(); err != nil {
+		return err
+	}
+	if err := b.initDriver(); err != nil {
+		return err
+	}
+
+	session := b.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close(ctx)
+
+	query, params, err := b.buildUpsertQuery(model, matchFields, options)
+	if err != nil {
+		return err
+	}
+
+	result, err := runWrite(ctx, session, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		records, err := tx.Run(ctx, query+" RETURN n", params)
+		if err != nil {
+			return nil, err
+		}
+
+		if records.Next(ctx) {
+			value, _ := records.Record().Get("n")
+			node, ok := value.(neo4j.Node)
+			if !ok {
+				return nil, fmt.Errorf("failed to cast result to neo4j.Node")
+			}
+			return node, nil
+		}
+
+		return nil, fmt.Errorf("failed to upsert node")
+	})
+
+	if err != nil {
+		return err
+	}
+
+	node, ok := result.(neo4j.Node)
+	if !ok {
+		return fmt.Errorf("unexpected result type: %T", result)
+	}
+
+	return mapNodeToModel(node, model)
+}
+
+// buildUpsertQuery returns the MERGE query of UpsertBy, or an error when a match field has no value.
+func (b *NeoBaseModel[T]) buildUpsertQuery(model *T, matchFields []string, options CreateOptions) (string, map[string]interface{}, error) {
+	modelType := reflect.TypeOf(*model)
+	modelValue := reflect.ValueOf(*model)
+
+	isKey := make(map[string]bool, len(matchFields))
+	for _, field := range matchFields {
+		isKey[field] = true
+	}
+
+	keys := make([]string, 0, len(matchFields))
+	onCreate := make(map[string]interface{})
+	onMatch := make(map[string]interface{})
+	params := make(map[string]interface{})
+
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		nodeTag := nodeTagName(field)
+		if nodeTag == "" || nodeTag == "id" {
+			continue
+		}
+
+		fieldValue := normalizeValue(field, modelValue.Field(i).Interface())
+		if isZeroTime(fieldValue) {
+			if isKey[nodeTag] {
+				return "", nil, fmt.Errorf("upsert key field %s has no value", nodeTag)
+			}
+			continue
+		}
+		if isKey[nodeTag] {
+			keys = append(keys, fmt.Sprintf("%s: $key_%s", nodeTag, nodeTag))
+			params["key_"+nodeTag] = fieldValue
+			continue
+		}
+		onCreate[nodeTag] = fieldValue
+		onMatch[nodeTag] = fieldValue
+	}
+
+	params["onCreate"] = onCreate
+	params["onMatch"] = onMatch
+
+	var queryBuilder strings.Builder
+	queryBuilder.WriteString(fmt.Sprintf("MERGE (n:%s {%s})", b.Label, strings.Join(keys, ", ")))
+	queryBuilder.WriteString(fmt.Sprintf(" ON CREATE SET n += $onCreate, %s ON MATCH SET n += $onMatch, %s, n.%s = false, n.%s = null",
+		touchUpdatedAt, touchUpdatedAt, deletedProperty, deletedAtProperty))
+
+	queryBuilder.WriteString(buildRelatedClause(options, "MERGE", params))
+
+	return queryBuilder.String(), params, nil
+}
@@ -0,0 +1,171 @@
+package neo
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"testing"
+	"time"
+
+	"api/internal/app/neo4j/neotest"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+func TestUpsertBy(t *testing.T) {
+	tests := []struct {
+		name      string
+		keys      []string
+		wantMerge string
+		wantErr   bool
+	}{
+		{
+			name:      "two field key",
+			keys:      []string{"name", "type"},
+			wantMerge: "MERGE (n:City {name: $key_name, type: $key_type})",
+		},
+		{
+			name:      "single field key",
+			keys:      []string{"name"},
+			wantMerge: "MERGE (n:City {name: $key_name})",
+		},
+		{name: "no key", wantErr: true},
+		{name: "id key", keys: []string{"id"}, wantErr: true},
+		{name: "unknown key", keys: []string{"mayor"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, func(neotest.Query) neotest.Response {
+				node := neotest.Node("4:db:4", []string{"City"}, map[string]any{"name": "Port Royal", "type": "port"})
+				return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", node)}}
+			})
+
+			city := &City{Name: "Port Royal", Type: "port", Population: 5000}
+			err := city.UpsertBy(context.Background(), city, tt.keys, CreateOptions{})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("UpsertBy() error = nil, want an error")
+				}
+				if len(driver.Queries()) != 0 {
+					t.Errorf("UpsertBy() ran %d queries, want none", len(driver.Queries()))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpsertBy() error = %v", err)
+			}
+			if city.ID != "4:db:4" {
+				t.Errorf("UpsertBy() mapped ID %q, want 4:db:4", city.ID)
+			}
+
+			query := driver.Queries()[0]
+			if !strings.HasPrefix(query.Cypher, tt.wantMerge) {
+				t.Errorf("UpsertBy() ran %q, want it to start with %q", query.Cypher, tt.wantMerge)
+			}
+			// Key fields identify the node, so only the other fields are updated on match.
+			onMatch, _ := query.Params["onMatch"].(map[string]any)
+			for _, key := range tt.keys {
+				if _, ok := onMatch[key]; ok {
+					t.Errorf("UpsertBy() updates key field %q on match", key)
+				}
+			}
+			if onMatch["population"] != int64(5000) {
+				t.Errorf("UpsertBy() onMatch = %v, want population 5000", onMatch)
+			}
+			// A soft-deleted node holding the key is restored rather than updated while hidden.
+			if _, onMatchSet, _ := strings.Cut(query.Cypher, "ON MATCH SET"); !strings.Contains(onMatchSet, "n.deleted = false, n.deletedAt = null") {
+				t.Errorf("UpsertBy() ran %q, want the matched node restored", query.Cypher)
+			}
+		})
+	}
+}
+
+func TestUpsertByKeyWithoutValue(t *testing.T) {
+	createdAt := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		world   World
+		keys    []string
+		wantErr bool
+	}{
+		{name: "time key", world: World{Name: "Atlantis", CreatedAt: createdAt}, keys: []string{"name", "createdAt"}},
+		{name: "zero time key", world: World{Name: "Atlantis"}, keys: []string{"name", "createdAt"}, wantErr: true},
+		{name: "zero time outside the key", world: World{Name: "Atlantis"}, keys: []string{"name"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := useFakeDriver(t, func(neotest.Query) neotest.Response {
+				node := neotest.Node("4:db:1", []string{"World"}, map[string]any{"name": "Atlantis"})
+				return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", node)}}
+			})
+
+			world := tt.world
+			err := world.UpsertBy(context.Background(), &world, tt.keys, CreateOptions{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UpsertBy() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if len(driver.Queries()) != 0 {
+					t.Errorf("UpsertBy() ran %v, want no query", driver.Queries())
+				}
+				return
+			}
+			if query := driver.Queries()[0].Cypher; strings.Contains(query, "{}") {
+				t.Errorf("UpsertBy() ran %q, want every key in the MERGE pattern", query)
+			}
+		})
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	// The fake merges on the key_name parameter as Neo4j would: the first upsert of a name creates a node,
+	// later ones match it and set the onMatch properties.
+	stored := make(map[string]map[string]any)
+	ids := make(map[string]string)
+	driver := useFakeDriver(t, func(query neotest.Query) neotest.Response {
+		name := query.Params["key_name"].(string)
+		props, ok := stored[name]
+		if !ok {
+			props = maps.Clone(query.Params["onCreate"].(map[string]any))
+			props["name"] = name
+			stored[name] = props
+			ids[name] = fmt.Sprintf("4:db:%d", len(ids)+1)
+		} else {
+			maps.Copy(props, query.Params["onMatch"].(map[string]any))
+		}
+		return neotest.Response{Records: []*neo4j.Record{neotest.Record("n", neotest.Node(ids[name], []string{"City"}, props))}}
+	})
+
+	tests := []struct {
+		name           string
+		city           City
+		wantID         string
+		wantPopulation int64
+	}{
+		{name: "created", city: City{Name: "Port Royal", Population: 5000}, wantID: "4:db:1", wantPopulation: 5000},
+		{name: "other key created", city: City{Name: "Tortuga", Population: 800}, wantID: "4:db:2", wantPopulation: 800},
+		{name: "matched and updated", city: City{Name: "Port Royal", Population: 6500}, wantID: "4:db:1", wantPopulation: 6500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			city := tt.city
+			if err := city.Upsert(context.Background(), &city, "name", CreateOptions{}); err != nil {
+				t.Fatalf("Upsert() error = %v", err)
+			}
+			if city.ID != tt.wantID || city.Population != tt.wantPopulation {
+				t.Errorf("Upsert() = %s with population %d, want %s with population %d", city.ID, city.Population, tt.wantID, tt.wantPopulation)
+			}
+
+			queries := driver.Queries()
+			query := queries[len(queries)-1].Cypher
+			if !strings.HasPrefix(query, "MERGE (n:City {name: $key_name}) ON CREATE SET n += $onCreate") || !strings.Contains(query, "ON MATCH SET n += $onMatch") {
+				t.Errorf("Upsert() ran %q", query)
+			}
+		})
+	}
+}
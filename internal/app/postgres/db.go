@@ -2,31 +2,170 @@ package postgres
 
 import (
 	"api/internal/app/models"
+	"api/internal/app/utils"
+	"errors"
+	"fmt"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
+/*
+ConnectOptions holds the settings used to open a PostgreSQL connection.
+  - @property DSN: The connection string of the PostgreSQL database.
+  - @property Attempts: The number of connection attempts before giving up. Values below 1 are treated as 1.
+  - @property Timeout: The connect timeout applied to each attempt through the DSN's connect_timeout parameter.
+  - @property Backoff: The delay before the second attempt, doubled after every failed attempt.
+*/
+type ConnectOptions struct {
+	DSN      string
+	Attempts int
+	Timeout  time.Duration
+	Backoff  time.Duration
+}
+
+const (
+	defaultConnectAttempts = 3
+	defaultConnectTimeout  = 5 * time.Second
+	defaultConnectBackoff  = 500 * time.Millisecond
+)
+
 /*
 Connect initializes a new PostgreSQL database connection using environment variables.
 It loads the database connection details from a .env file and returns a gorm.DB instance or an error if the connection fails.
 The .env file should contain the following variable:
   - POSTGRES_URI: The URI of the PostgreSQL database.
+
+The following variables are optional:
+  - POSTGRES_PASSWORD or POSTGRES_PASSWORD_FILE: A password overriding the one in POSTGRES_URI, read from a file for the latter.
+  - POSTGRES_CONNECT_ATTEMPTS: The number of connection attempts (default 3).
+  - POSTGRES_CONNECT_TIMEOUT: The connect timeout of each attempt, as a Go duration (default 5s).
+  - POSTGRES_CONNECT_BACKOFF: The initial delay between attempts, as a Go duration (default 500ms).
 */
 func Connect() (*gorm.DB, error) {
+	// Without a .env file the settings come from the environment alone.
 	err := godotenv.Load()
-	if err != nil {
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
 		return nil, err
 	}
-	dbName := os.Getenv("POSTGRES_URI")
-	db, err := gorm.Open(postgres.Open(dbName), &gorm.Config{})
+
+	password, err := utils.Secret("POSTGRES_PASSWORD")
 	if err != nil {
 		return nil, err
 	}
-	db.AutoMigrate(&models.User{})
-	return db, nil
+
+	options := ConnectOptions{
+		DSN:      withPassword(os.Getenv("POSTGRES_URI"), password),
+		Attempts: defaultConnectAttempts,
+		Timeout:  defaultConnectTimeout,
+		Backoff:  defaultConnectBackoff,
+	}
+
+	if value := os.Getenv("POSTGRES_CONNECT_ATTEMPTS"); value != "" {
+		if options.Attempts, err = strconv.Atoi(value); err != nil {
+			return nil, fmt.Errorf("invalid POSTGRES_CONNECT_ATTEMPTS: %w", err)
+		}
+	}
+	if value := os.Getenv("POSTGRES_CONNECT_TIMEOUT"); value != "" {
+		if options.Timeout, err = time.ParseDuration(value); err != nil {
+			return nil, fmt.Errorf("invalid POSTGRES_CONNECT_TIMEOUT: %w", err)
+		}
+	}
+	if value := os.Getenv("POSTGRES_CONNECT_BACKOFF"); value != "" {
+		if options.Backoff, err = time.ParseDuration(value); err != nil {
+			return nil, fmt.Errorf("invalid POSTGRES_CONNECT_BACKOFF: %w", err)
+		}
+	}
+
+	return ConnectWithOptions(options)
+}
+
+/*
+ConnectWithOptions opens a PostgreSQL database connection using the provided options.
+Each attempt is bounded by options.Timeout, and failed attempts are retried with an exponential backoff.
+It returns the last error once all attempts have been exhausted.
+*/
+func ConnectWithOptions(options ConnectOptions) (*gorm.DB, error) {
+	attempts := options.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	dsn := withConnectTimeout(options.DSN, options.Timeout)
+	backoff := options.Backoff
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+		if err == nil {
+			if err := db.AutoMigrate(&models.User{}); err != nil {
+				Close(db)
+				return nil, fmt.Errorf("failed to migrate postgres: %w", err)
+			}
+			return db, nil
+		}
+		lastErr = err
+
+		if attempt < attempts {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+
+	return nil, fmt.Errorf("failed to connect to postgres after %d attempts: %w", attempts, lastErr)
+}
+
+// withPassword sets the password of the DSN. An empty password leaves the DSN untouched.
+// Both the URI form and the key=value form of the DSN are supported.
+func withPassword(dsn string, password string) string {
+	if password == "" {
+		return dsn
+	}
+
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		parsed, err := url.Parse(dsn)
+		if err != nil {
+			return dsn
+		}
+		parsed.User = url.UserPassword(parsed.User.Username(), password)
+		return parsed.String()
+	}
+
+	escaped := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(password)
+	return strings.TrimSpace(dsn + " password='" + escaped + "'")
+}
+
+// withConnectTimeout adds a connect_timeout parameter to the DSN unless one is already present.
+// Both the URI form and the key=value form of the DSN are supported.
+func withConnectTimeout(dsn string, timeout time.Duration) string {
+	if timeout <= 0 || strings.Contains(dsn, "connect_timeout") {
+		return dsn
+	}
+
+	seconds := int(timeout.Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		parsed, err := url.Parse(dsn)
+		if err != nil {
+			return dsn
+		}
+		query := parsed.Query()
+		query.Set("connect_timeout", strconv.Itoa(seconds))
+		parsed.RawQuery = query.Encode()
+		return parsed.String()
+	}
+
+	return strings.TrimSpace(dsn + " connect_timeout=" + strconv.Itoa(seconds))
 }
 
 /*
@@ -45,3 +184,58 @@ func Close(db *gorm.DB) error {
 	}
 	return nil
 }
+
+// shared is the connection, and so the connection pool, reused by every request.
+var (
+	sharedMu sync.Mutex
+	shared   *gorm.DB
+)
+
+// connectShared opens the connection returned by Shared, replaced in tests.
+var connectShared = Connect
+
+/*
+Shared returns the process-wide PostgreSQL connection, opening it with Connect on first use.
+Prefer it over Connect in request handlers, so requests reuse one connection pool instead of opening their own.
+Connect retries for a while when the database is down, so it runs without holding the lock: callers
+connecting at the same time keep the first connection opened and close their own.
+*/
+func Shared() (*gorm.DB, error) {
+	sharedMu.Lock()
+	db := shared
+	sharedMu.Unlock()
+	if db != nil {
+		return db, nil
+	}
+
+	db, err := connectShared()
+	if err != nil {
+		return nil, err
+	}
+
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+
+	if shared != nil {
+		Close(db)
+		return shared, nil
+	}
+	shared = db
+	return shared, nil
+}
+
+/*
+CloseShared closes the process-wide PostgreSQL connection, if it was opened.
+It should be called once on shutdown; a later call to Shared opens a new connection.
+*/
+func CloseShared() error {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+
+	if shared == nil {
+		return nil
+	}
+	err := Close(shared)
+	shared = nil
+	return err
+}
@@ -0,0 +1,194 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// JSONAPIMediaType is the media type clients send in the Accept header to get JSON:API documents.
+const JSONAPIMediaType = "application/vnd.api+json"
+
+/*
+Resource is a JSON:API resource object.
+*/
+type Resource struct {
+	Type          string                  `json:"type"`
+	ID            string                  `json:"id,omitempty"`
+	Attributes    map[string]interface{}  `json:"attributes,omitempty"`
+	Relationships map[string]Relationship `json:"relationships,omitempty"`
+}
+
+/*
+Relationship is a JSON:API relationship object, holding the identifiers of the related resources.
+*/
+type Relationship struct {
+	Data []ResourceIdentifier `json:"data"`
+}
+
+/*
+ResourceIdentifier identifies a related resource by its type and id.
+*/
+type ResourceIdentifier struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+/*
+WantsJSONAPI reports whether the request asks for a JSON:API document in its Accept header.
+*/
+func WantsJSONAPI(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), JSONAPIMediaType)
+}
+
+/*
+Respond writes a model, or a slice of models, with the given status.
+Plain JSON is written by default; a JSON:API document is written when the request asks for it (see WantsJSONAPI)
+and the body is a model. Other bodies, ie: maps or tokens, are always written as plain JSON.
+The response goes through the post-processors registered with AfterResponse before being written.
+
+Example usage:
+
+	rest.Respond(w, r, http.StatusOK, world)
+*/
+func Respond(w http.ResponseWriter, r *http.Request, status int, model interface{}) {
+	var body interface{} = model
+	if WantsJSONAPI(r) && isModel(model) {
+		document, err := ToJSONAPI(model)
+		if err != nil {
+			InternalError(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", JSONAPIMediaType)
+		body = document
+	}
+
+	body, err := postProcess(w, r, status, body)
+	if err != nil {
+		InternalError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// isModel reports whether the body is a model, or a slice of models, that ToJSONAPI can wrap in a document:
+// a struct with an ID field, since every resource has an id.
+func isModel(body interface{}) bool {
+	value := reflect.Indirect(reflect.ValueOf(body))
+	if !value.IsValid() {
+		return false
+	}
+	modelType := value.Type()
+	if modelType.Kind() == reflect.Slice {
+		modelType = modelType.Elem()
+		if modelType.Kind() == reflect.Ptr {
+			modelType = modelType.Elem()
+		}
+	}
+	if modelType.Kind() != reflect.Struct {
+		return false
+	}
+	_, hasID := modelType.FieldByName("ID")
+	return hasID
+}
+
+/*
+ToJSONAPI wraps a model, or a slice of models, in a JSON:API document: {"data": resource} or {"data": [resources]}.
+The resource type is the model's Label, or its type name when the Label is not set. The id is the ID field,
+the relationships are the rel-tagged fields and the attributes are the remaining JSON fields.
+*/
+func ToJSONAPI(model interface{}) (map[string]interface{}, error) {
+	value := reflect.Indirect(reflect.ValueOf(model))
+	if value.Kind() != reflect.Slice {
+		resource, err := toResource(value)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"data": resource}, nil
+	}
+
+	resources := make([]Resource, 0, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		resource, err := toResource(reflect.Indirect(value.Index(i)))
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, resource)
+	}
+	return map[string]interface{}{"data": resources}, nil
+}
+
+// toResource builds the resource object of a model struct.
+func toResource(value reflect.Value) (Resource, error) {
+	encoded, err := json.Marshal(value.Interface())
+	if err != nil {
+		return Resource{}, err
+	}
+
+	var attributes map[string]interface{}
+	if err := json.Unmarshal(encoded, &attributes); err != nil {
+		return Resource{}, err
+	}
+	delete(attributes, "id")
+	delete(attributes, "labels")
+
+	resource := Resource{
+		Type:       resourceType(value),
+		ID:         resourceID(value),
+		Attributes: attributes,
+	}
+
+	modelType := value.Type()
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		if relTag := field.Tag.Get("rel"); relTag == "" || relTag == "props" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" {
+			name = field.Name
+		}
+		delete(attributes, name)
+
+		relationship := Relationship{Data: []ResourceIdentifier{}}
+		related := value.Field(i)
+		if related.Kind() != reflect.Slice {
+			related = reflect.Append(reflect.MakeSlice(reflect.SliceOf(related.Type()), 0, 1), related)
+		}
+		for j := 0; j < related.Len(); j++ {
+			item := related.Index(j)
+			if item.Kind() == reflect.Ptr && item.IsNil() {
+				continue
+			}
+			item = reflect.Indirect(item)
+			relationship.Data = append(relationship.Data, ResourceIdentifier{Type: resourceType(item), ID: resourceID(item)})
+		}
+
+		if resource.Relationships == nil {
+			resource.Relationships = make(map[string]Relationship)
+		}
+		resource.Relationships[name] = relationship
+	}
+
+	return resource, nil
+}
+
+// resourceType returns the Label of a model, or its type name when the Label is not set.
+func resourceType(value reflect.Value) string {
+	if label := value.FieldByName("Label"); label.IsValid() && label.Kind() == reflect.String && label.String() != "" {
+		return label.String()
+	}
+	return value.Type().Name()
+}
+
+// resourceID returns the ID field of a model, if it is a string.
+func resourceID(value reflect.Value) string {
+	if id := value.FieldByName("ID"); id.IsValid() && id.Kind() == reflect.String {
+		return id.String()
+	}
+	return ""
+}
@@ -0,0 +1,89 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	neoModels "api/internal/app/models/neo"
+)
+
+func TestRespondJSONAPI(t *testing.T) {
+	world := &neoModels.World{
+		ID:         "4:db:1",
+		Name:       "Atlantis",
+		Type:       "ocean",
+		CreatedAt:  time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Continents: []*neoModels.Continent{{ID: "4:db:2", Name: "North"}, {ID: "4:db:3", Name: "South"}},
+	}
+
+	tests := []struct {
+		name            string
+		accept          string
+		wantContentType string
+		want            map[string]any
+	}{
+		{
+			name:            "json api",
+			accept:          JSONAPIMediaType,
+			wantContentType: JSONAPIMediaType,
+			want: map[string]any{"data": map[string]any{
+				"type": "World",
+				"id":   "4:db:1",
+				"attributes": map[string]any{
+					"name":      "Atlantis",
+					"type":      "ocean",
+					"createdAt": "2024-05-01T12:00:00Z",
+				},
+				"relationships": map[string]any{
+					"continents": map[string]any{"data": []any{
+						map[string]any{"type": "Continent", "id": "4:db:2"},
+						map[string]any{"type": "Continent", "id": "4:db:3"},
+					}},
+					"oceans": map[string]any{"data": []any{}},
+				},
+			}},
+		},
+		{
+			name:   "plain json by default",
+			accept: "application/json",
+			want: map[string]any{
+				"id":        "4:db:1",
+				"name":      "Atlantis",
+				"type":      "ocean",
+				"createdAt": "2024-05-01T12:00:00Z",
+				"continents": []any{
+					map[string]any{"id": "4:db:2", "name": "North"},
+					map[string]any{"id": "4:db:3", "name": "South"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/world/4:db:1", nil)
+			r.Header.Set("Accept", tt.accept)
+			w := httptest.NewRecorder()
+
+			Respond(w, r, http.StatusOK, world)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Respond() status = %d: %s", w.Code, w.Body)
+			}
+			if contentType := w.Header().Get("Content-Type"); contentType != tt.wantContentType {
+				t.Errorf("Respond() Content-Type = %q, want %q", contentType, tt.wantContentType)
+			}
+
+			var got map[string]any
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatalf("Respond() body: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Respond() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+/*
+ResponseMeta is the response passed to the post-processors registered with AfterResponse.
+Body holds the decoded JSON body (a map[string]interface{} for objects, a []interface{} for arrays),
+so a post-processor can change it, or replace it, before it is written.
+*/
+type ResponseMeta struct {
+	Request *http.Request
+	Header  http.Header
+	Status  int
+	Body    interface{}
+}
+
+var (
+	postProcessorsMu sync.RWMutex
+	postProcessors   []func(*ResponseMeta)
+)
+
+/*
+AfterResponse registers a post-processor run, in registration order, on every response written by Respond.
+Post-processors are optional: without any, Respond writes the model as is.
+
+Example usage:
+
+	rest.AfterResponse(func(meta *rest.ResponseMeta) {
+		if body, ok := meta.Body.(map[string]interface{}); ok {
+			body["generatedAt"] = time.Now().UTC()
+		}
+	})
+*/
+func AfterResponse(postProcessor func(*ResponseMeta)) {
+	postProcessorsMu.Lock()
+	defer postProcessorsMu.Unlock()
+	postProcessors = append(postProcessors, postProcessor)
+}
+
+// postProcess runs the registered post-processors on a response body and returns the body to write.
+func postProcess(w http.ResponseWriter, r *http.Request, status int, body interface{}) (interface{}, error) {
+	postProcessorsMu.RLock()
+	processors := postProcessors
+	postProcessorsMu.RUnlock()
+
+	if len(processors) == 0 {
+		return body, nil
+	}
+
+	// Round-trip through JSON so post-processors see the body as it will be written, whatever the model type.
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	meta := &ResponseMeta{Request: r, Header: w.Header(), Status: status}
+	if err := json.Unmarshal(encoded, &meta.Body); err != nil {
+		return nil, err
+	}
+
+	for _, process := range processors {
+		process(meta)
+	}
+	return meta.Body, nil
+}
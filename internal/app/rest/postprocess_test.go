@@ -0,0 +1,87 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	neoModels "api/internal/app/models/neo"
+)
+
+func TestAfterResponse(t *testing.T) {
+	t.Cleanup(func() { postProcessors = nil })
+	AfterResponse(func(meta *ResponseMeta) {
+		if body, ok := meta.Body.(map[string]interface{}); ok {
+			body["apiVersion"] = "1"
+		}
+	})
+	// Post-processors run in registration order, so this one sees the field added by the first.
+	AfterResponse(func(meta *ResponseMeta) {
+		if body, ok := meta.Body.(map[string]interface{}); ok && body["apiVersion"] != nil {
+			meta.Header.Set("X-API-Version", body["apiVersion"].(string))
+		}
+	})
+
+	tests := []struct {
+		name        string
+		accept      string
+		model       interface{}
+		want        interface{}
+		wantVersion string
+	}{
+		{
+			name:        "model",
+			model:       &neoModels.Ocean{ID: "4:db:5", Name: "Pacific"},
+			want:        map[string]interface{}{"id": "4:db:5", "name": "Pacific", "apiVersion": "1"},
+			wantVersion: "1",
+		},
+		{
+			name:        "map",
+			model:       map[string]int{"count": 2},
+			want:        map[string]interface{}{"count": float64(2), "apiVersion": "1"},
+			wantVersion: "1",
+		},
+		{
+			name:   "json api document",
+			accept: JSONAPIMediaType,
+			model:  &neoModels.Ocean{ID: "4:db:5", Name: "Pacific"},
+			want: map[string]interface{}{
+				"data": map[string]interface{}{
+					"type":       "Ocean",
+					"id":         "4:db:5",
+					"attributes": map[string]interface{}{"name": "Pacific"},
+				},
+				"apiVersion": "1",
+			},
+			wantVersion: "1",
+		},
+		{
+			name:  "array left as is",
+			model: []string{"a", "b"},
+			want:  []interface{}{"a", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/ocean/4:db:5", nil)
+			r.Header.Set("Accept", tt.accept)
+			w := httptest.NewRecorder()
+
+			Respond(w, r, http.StatusOK, tt.model)
+
+			var got interface{}
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatalf("Respond() body: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Respond() = %v, want %v", got, tt.want)
+			}
+			if version := w.Header().Get("X-API-Version"); version != tt.wantVersion {
+				t.Errorf("Respond() X-API-Version = %q, want %q", version, tt.wantVersion)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+// Package rest contains helpers shared by the controllers to write HTTP responses.
+package rest
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// RequestIDHeader is the header carrying the id used to correlate a request with its server-side logs.
+const RequestIDHeader = "X-Request-ID"
+
+/*
+ErrorResponse is the JSON body written for error responses.
+*/
+type ErrorResponse struct {
+	Error     string   `json:"error"`
+	Errors    []string `json:"errors,omitempty"`
+	RequestID string   `json:"requestId,omitempty"`
+}
+
+/*
+InternalError logs err along with the request id, and responds with a generic 500 error.
+The error detail (database errors, query fragments) is never sent to the client.
+
+Example usage:
+
+	if err != nil {
+		rest.InternalError(w, r, err)
+		return
+	}
+*/
+func InternalError(w http.ResponseWriter, r *http.Request, err error) {
+	log.Printf("internal error: request_id=%q method=%s path=%s err=%q", r.Header.Get(RequestIDHeader), r.Method, r.URL.Path, err)
+	RespondInternalError(w, r)
+}
+
+/*
+RespondInternalError responds with the generic 500 error of InternalError without logging anything,
+for callers logging the failure themselves, ie: with a stack trace.
+
+Example usage:
+
+	log.Printf("panic: request_id=%q err=%v\n%s", r.Header.Get(rest.RequestIDHeader), recovered, debug.Stack())
+	rest.RespondInternalError(w, r)
+*/
+func RespondInternalError(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusInternalServerError)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Error:     "internal server error",
+		RequestID: r.Header.Get(RequestIDHeader),
+	})
+}
+
+/*
+RespondUnprocessable responds with a 422 for a well-formed request violating business rules,
+ie: referencing a parent that does not exist. Malformed requests should still get a 400.
+
+Example usage:
+
+	if errors.Is(err, neo.ErrRelatedNotFound) {
+		rest.RespondUnprocessable(w, []string{"user not found"})
+		return
+	}
+*/
+func RespondUnprocessable(w http.ResponseWriter, errors []string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Error:  "unprocessable entity",
+		Errors: errors,
+	})
+}
+
+/*
+RespondError responds with the given status and an ErrorResponse carrying the message.
+
+Example usage:
+
+	rest.RespondError(w, http.StatusNotFound, "world not found")
+*/
+func RespondError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Error: message,
+	})
+}
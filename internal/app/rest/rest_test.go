@@ -0,0 +1,71 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInternalError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		requestID string
+	}{
+		{
+			name:      "database error with request id",
+			err:       errors.New("Neo.ClientError.Statement.SyntaxError: Invalid input 'X' (line 1, column 1 (offset: 0)) \"MATCH (n:World) RETURN n\""),
+			requestID: "req-42",
+		},
+		{
+			name: "wrapped error without request id",
+			err:  errors.New("failed to connect to postgres: password authentication failed for user \"api\""),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logged bytes.Buffer
+			log.SetOutput(&logged)
+			defer log.SetOutput(os.Stderr)
+
+			r := httptest.NewRequest("GET", "/api/world/1", nil)
+			if tt.requestID != "" {
+				r.Header.Set(RequestIDHeader, tt.requestID)
+			}
+			w := httptest.NewRecorder()
+			InternalError(w, r, tt.err)
+
+			if w.Code != http.StatusInternalServerError {
+				t.Errorf("InternalError() status = %d, want 500", w.Code)
+			}
+			var response ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("InternalError() body %q: %v", w.Body, err)
+			}
+			want := ErrorResponse{Error: "internal server error", RequestID: tt.requestID}
+			if !reflect.DeepEqual(response, want) {
+				t.Errorf("InternalError() body = %+v, want %+v", response, want)
+			}
+			if strings.Contains(w.Body.String(), "MATCH") || strings.Contains(w.Body.String(), "password") {
+				t.Errorf("InternalError() leaked the error detail: %s", w.Body)
+			}
+
+			// The detail is logged instead, along with the request id to correlate it with the response.
+			if !strings.Contains(logged.String(), fmt.Sprintf("err=%q", tt.err)) {
+				t.Errorf("InternalError() logged %q, want the error detail", logged.String())
+			}
+			if !strings.Contains(logged.String(), `request_id="`+tt.requestID+`"`) {
+				t.Errorf("InternalError() logged %q, want the request id %q", logged.String(), tt.requestID)
+			}
+		})
+	}
+}
@@ -2,7 +2,10 @@ package routing
 
 import (
 	"net/http"
+	"net/url"
+	"slices"
 	"strings"
+	"sync"
 )
 
 /*
@@ -26,78 +29,165 @@ Example:
 */
 type HTTPHandlerWithContext func(w http.ResponseWriter, r *http.Request, c Context)
 
+// Mux is safe for concurrent use: routes may be registered while the server is serving requests.
+// RouteMiddleware and the route wrappers are keyed by method and path, see routeKey, so they only run for the route
+// they were registered on.
 type Mux struct {
-	routes           map[string]map[string]HTTPHandlerWithContext
-	RouterMiddleware []Middleware
-	RouteMiddleware  map[string][]Middleware
+	mu                 sync.RWMutex
+	routes             map[string]map[string]HTTPHandlerWithContext
+	allowedQueryParams map[string]map[string]bool
+	RouterMiddleware   []Middleware
+	RouteMiddleware    map[string][]Middleware
+	routeWrappers      map[string][]func(http.Handler) http.Handler
+	staticRoutes       map[string]bool // routes registered by Router.Static, see allowedMethods
+	notFound           HTTPHandlerWithContext
+	methodNotAllowed   HTTPHandlerWithContext
+	autoOptions        bool
+	autoHead           bool
 }
 
 func newMux() *Mux {
 	return &Mux{
-		routes:           make(map[string]map[string]HTTPHandlerWithContext),
-		RouterMiddleware: make([]Middleware, 0),
-		RouteMiddleware:  make(map[string][]Middleware),
+		routes:             make(map[string]map[string]HTTPHandlerWithContext),
+		allowedQueryParams: make(map[string]map[string]bool),
+		RouterMiddleware:   make([]Middleware, 0),
+		RouteMiddleware:    make(map[string][]Middleware),
+		routeWrappers:      make(map[string][]func(http.Handler) http.Handler),
+		staticRoutes:       make(map[string]bool),
+		autoOptions:        true,
+		autoHead:           true,
 	}
 }
 
 func (m *Mux) handle(method string, path string, handler HTTPHandlerWithContext, middleware ...Middleware) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if _, ok := m.routes[method]; !ok {
 		m.routes[method] = make(map[string]HTTPHandlerWithContext)
 	}
 
-	if _, ok := m.RouteMiddleware[path]; !ok {
-		m.RouteMiddleware[path] = make([]Middleware, 0)
+	key := routeKey(method, path)
+	if _, ok := m.RouteMiddleware[key]; !ok {
+		m.RouteMiddleware[key] = make([]Middleware, 0)
 	}
 
 	if middleware != nil {
-		m.RouteMiddleware[path] = append(m.RouteMiddleware[path], middleware...)
+		m.RouteMiddleware[key] = append(m.RouteMiddleware[key], middleware...)
 	}
 	m.routes[method][path] = handler
 }
 
-func (m *Mux) getQueryParams(query string) map[string]string {
-	if query == "" {
-		return nil
+// routeKey identifies a route by its method and path, ie: "DELETE /api/world/:id".
+func routeKey(method string, path string) string {
+	return method + " " + path
+}
+
+func (m *Mux) wrapRoute(method string, path string, wrapper func(http.Handler) http.Handler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := routeKey(method, path)
+	m.routeWrappers[key] = append(m.routeWrappers[key], wrapper)
+}
+
+func (m *Mux) markStatic(method string, path string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.staticRoutes[routeKey(method, path)] = true
+}
+
+func (m *Mux) allowQueryParams(method string, path string, params []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	allowed := make(map[string]bool, len(params))
+	for _, param := range params {
+		allowed[param] = true
 	}
+	m.allowedQueryParams[routeKey(method, path)] = allowed
+}
 
-	queryParams := make(map[string]string)
-	queryParts := strings.Split(query, "&")
+func (m *Mux) unexpectedQueryParam(method string, r *http.Request, matchedRoute string) (string, bool) {
+	allowed, ok := m.allowedQueryParams[routeKey(method, matchedRoute)]
+	if !ok {
+		return "", false
+	}
+
+	for key := range r.URL.Query() {
+		if !allowed[key] {
+			return key, true
+		}
+	}
+	return "", false
+}
 
-	for _, part := range queryParts {
-		parts := strings.Split(part, "=")
-		queryParams[parts[0]] = parts[1]
+// getQueryParams decodes the raw query. Malformed pairs are skipped, and bare keys get an empty value.
+func (m *Mux) getQueryParams(query string) url.Values {
+	if query == "" {
+		return nil
 	}
-	return queryParams
+
+	// ParseQuery still returns the well-formed pairs along with its error.
+	values, _ := url.ParseQuery(query)
+	return values
 }
 
 func (m *Mux) matchRoute(r *http.Request, routes map[string]HTTPHandlerWithContext) (HTTPHandlerWithContext, *Context, string) {
 	if handler, ok := routes[r.URL.Path]; ok {
 		context := newContext()
+		context.setQueryParams(m.getQueryParams(r.URL.RawQuery))
 		return handler, &context, r.URL.Path
 	}
 
-	for routePath, handler := range routes {
-		if params, ok := m.matchPath(r.URL.Path, routePath); ok {
+	// Wildcard routes only match when no other route does, the one with the longest prefix first.
+	var wildcardPath string
+	var wildcardParams map[string]string
+	for routePath := range routes {
+		params, ok := m.matchPath(r.URL.Path, routePath)
+		if !ok {
+			continue
+		}
+		if !isWildcardRoute(routePath) {
 			context := newContext()
 			context.setPathParams(params)
 			context.setQueryParams(m.getQueryParams(r.URL.RawQuery))
-			return handler, &context, routePath
+			return routes[routePath], &context, routePath
+		}
+		if len(routePath) > len(wildcardPath) {
+			wildcardPath, wildcardParams = routePath, params
 		}
 	}
+
+	if wildcardPath != "" {
+		context := newContext()
+		context.setPathParams(wildcardParams)
+		context.setQueryParams(m.getQueryParams(r.URL.RawQuery))
+		return routes[wildcardPath], &context, wildcardPath
+	}
 	return nil, nil, ""
 }
 
+// isWildcardRoute reports whether the route path ends with a *name segment.
+func isWildcardRoute(routePath string) bool {
+	return strings.HasPrefix(routePath[strings.LastIndex(routePath, "/")+1:], "*")
+}
+
 func (m *Mux) matchPath(requestPath, routePath string) (map[string]string, bool) {
 	routeParts := strings.Split(routePath, "/")
 	requestParts := strings.Split(requestPath, "/")
 
-	if len(routeParts) != len(requestParts) {
+	wildcard := isWildcardRoute(routePath)
+	if len(routeParts) != len(requestParts) && !(wildcard && len(requestParts) > len(routeParts)) {
 		return nil, false
 	}
 
 	params := make(map[string]string)
 	for i, part := range routeParts {
-		if strings.HasPrefix(part, ":") {
+		if wildcard && i == len(routeParts)-1 {
+			// A trailing *name segment captures the rest of the path, ie: a/b/c.
+			params[part[1:]] = strings.Join(requestParts[i:], "/")
+		} else if strings.HasPrefix(part, ":") {
 			params[part[1:]] = requestParts[i]
 		} else if part != requestParts[i] {
 			return nil, false
@@ -107,27 +197,189 @@ func (m *Mux) matchPath(requestPath, routePath string) (map[string]string, bool)
 }
 
 func (m *Mux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	for _, middleware := range m.RouterMiddleware {
-		middleware(w, r)
+	m.mu.RLock()
+	routerMiddleware := m.RouterMiddleware
+	m.mu.RUnlock()
+
+	for _, middleware := range routerMiddleware {
+		if !middleware(w, r) {
+			return
+		}
 	}
 
-	routes, ok := m.routes[r.Method]
-	if !ok {
-		http.NotFound(w, r)
+	m.mu.RLock()
+	var handler HTTPHandlerWithContext
+	var context *Context
+	var matchedRoute string
+	method := r.Method
+	if routes, ok := m.routes[method]; ok {
+		handler, context, matchedRoute = m.matchRoute(r, routes)
+	}
+	if handler == nil && r.Method == http.MethodHead && m.autoHead {
+		if routes, ok := m.routes[http.MethodGet]; ok {
+			handler, context, matchedRoute = m.matchRoute(r, routes)
+			if handler != nil {
+				method = http.MethodGet
+				w = &headResponseWriter{ResponseWriter: w}
+			}
+		}
+	}
+	if handler == nil {
+		noMatch, routeMiddleware := m.noMatchHandler(r)
+		m.mu.RUnlock()
+
+		for _, mw := range routeMiddleware {
+			if !mw(w, r) {
+				return
+			}
+		}
+
+		context := newContext()
+		context.setQueryParams(m.getQueryParams(r.URL.RawQuery))
+		noMatch(w, r, context)
 		return
 	}
+	param, unexpected := m.unexpectedQueryParam(method, r, matchedRoute)
+	routeMiddleware := m.RouteMiddleware[routeKey(method, matchedRoute)]
+	wrappers := m.routeWrappers[routeKey(method, matchedRoute)]
+	m.mu.RUnlock()
 
-	handler, context, matchedRoute := m.matchRoute(r, routes)
-	if handler == nil {
-		http.NotFound(w, r)
+	if unexpected {
+		http.Error(w, "unexpected query parameter: "+param, http.StatusBadRequest)
 		return
 	}
 
-	if middleware, ok := m.RouteMiddleware[matchedRoute]; ok {
-		for _, mw := range middleware {
-			mw(w, r)
+	for _, mw := range routeMiddleware {
+		if !mw(w, r) {
+			return
+		}
+	}
+
+	// Route wrappers run last, closest to the handler, and may hand it a new request, ie: carrying claims.
+	var wrapped http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(w, r, *context)
+	})
+	for i := len(wrappers) - 1; i >= 0; i-- {
+		wrapped = wrappers[i](wrapped)
+	}
+	wrapped.ServeHTTP(w, r)
+}
+
+// allowedMethods returns the sorted methods the request path is registered under, HEAD included along GET unless disabled.
+// Static routes are left out, so their catch-all does not turn unknown paths into 405s and OPTIONS replies.
+func (m *Mux) allowedMethods(r *http.Request) []string {
+	var methods []string
+	for method, routes := range m.routes {
+		if method == r.Method {
+			continue
+		}
+		if handler, _, matchedRoute := m.matchRoute(r, routes); handler != nil && !m.staticRoutes[routeKey(method, matchedRoute)] {
+			methods = append(methods, method)
+		}
+	}
+	if m.autoHead && slices.Contains(methods, http.MethodGet) && !slices.Contains(methods, http.MethodHead) && r.Method != http.MethodHead {
+		methods = append(methods, http.MethodHead)
+	}
+	slices.Sort(methods)
+	return methods
+}
+
+// noMatchHandler returns the handler for a request matching no route, along with the route middleware to run
+// before it. The caller must hold the read lock.
+// A path registered under other methods is answered with a 405 Method Not Allowed listing them in the Allow header.
+// An automatic OPTIONS reply runs the middleware of the route it is a preflight for, see preflightMiddleware.
+func (m *Mux) noMatchHandler(r *http.Request) (HTTPHandlerWithContext, []Middleware) {
+	if methods := m.allowedMethods(r); len(methods) > 0 {
+		if r.Method == http.MethodOptions && m.autoOptions {
+			return optionsHandler(append(methods, http.MethodOptions)), m.preflightMiddleware(r, methods)
+		}
+
+		methodNotAllowed := m.methodNotAllowed
+		return func(w http.ResponseWriter, r *http.Request, c Context) {
+			w.Header().Set("Allow", strings.Join(methods, ", "))
+			if methodNotAllowed != nil {
+				methodNotAllowed(w, r, c)
+				return
+			}
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		}, nil
+	}
+
+	return m.notFoundHandler(), nil
+}
+
+// notFoundHandler returns the handler for unknown paths. The caller must hold the read lock.
+func (m *Mux) notFoundHandler() HTTPHandlerWithContext {
+	if m.notFound != nil {
+		return m.notFound
+	}
+	return func(w http.ResponseWriter, r *http.Request, c Context) {
+		http.NotFound(w, r)
+	}
+}
+
+// preflightMiddleware returns the route middleware of the route an OPTIONS request is a preflight for, ie: its
+// own CORS policy: the route of the Access-Control-Request-Method method, or else of the first of the allowed methods.
+// The caller must hold the read lock.
+func (m *Mux) preflightMiddleware(r *http.Request, methods []string) []Middleware {
+	method := r.Header.Get("Access-Control-Request-Method")
+	if !slices.Contains(methods, method) {
+		method = methods[0]
+	}
+
+	handler, _, matchedRoute := m.matchRoute(r, m.routes[method])
+	if handler == nil && method == http.MethodHead {
+		// HEAD is served by the GET route, see autoHead.
+		method = http.MethodGet
+		_, _, matchedRoute = m.matchRoute(r, m.routes[method])
+	}
+	return m.RouteMiddleware[routeKey(method, matchedRoute)]
+}
+
+// optionsHandler answers OPTIONS requests, ie: CORS preflights, for a path registered under the given methods.
+// Access-Control-Allow-Methods is left to a CORS middleware that already set it.
+func optionsHandler(methods []string) HTTPHandlerWithContext {
+	allow := strings.Join(methods, ", ")
+	return func(w http.ResponseWriter, r *http.Request, c Context) {
+		w.Header().Set("Allow", allow)
+		if w.Header().Get("Access-Control-Allow-Methods") == "" {
+			w.Header().Set("Access-Control-Allow-Methods", allow)
 		}
+		w.WriteHeader(http.StatusNoContent)
 	}
+}
+
+// configure applies the ServeOptions handled by the mux.
+func (m *Mux) configure(options ServeOptions) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.autoOptions = !options.DisableAutoOptions
+	m.autoHead = !options.DisableAutoHead
+}
+
+func (m *Mux) use(middleware []Middleware) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.RouterMiddleware = middleware
+}
+
+func (m *Mux) setNotFound(handler HTTPHandlerWithContext) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.notFound = handler
+}
+
+func (m *Mux) setMethodNotAllowed(handler HTTPHandlerWithContext) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.methodNotAllowed = handler
+}
+
+// headResponseWriter serves HEAD requests with a GET handler: headers and status are kept, the body is discarded.
+type headResponseWriter struct {
+	http.ResponseWriter
+}
 
-	handler(w, r, *context)
+func (w *headResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
 }
@@ -4,7 +4,7 @@
 //
 //   - @package routing
 //
-//   - @type Middleware - A function that takes an http.ResponseWriter and an http.Request and returns nothing.
+//   - @type Middleware - A function that takes an http.ResponseWriter and an http.Request and returns whether the request proceeds.
 //
 //   - @type Context - A struct that holds path and query parameters.
 //
@@ -16,29 +16,62 @@
 //
 //   - @type HTTPHandlerWithContext - A function that takes an http.ResponseWriter, an http.Request, and a Context and returns nothing.
 //
+//   - @func BuildPath - Builds a request path from a route pattern by substituting its path parameters.
+//
 //   - @func Use - Adds a middleware to the Router's middleware chain.
 //
+//   - @func Wrap - Wraps the whole router with a standard http.Handler middleware.
+//
 //   - @func Handle - Registers a route with the specified method, path, handler, and middleware.
 //
+//   - @func Route.Wrap - Wraps a single route's handler with a standard http.Handler middleware.
+//
+//   - @func Static - Serves the files of a directory under a URL prefix, with an index.html fallback.
+//
+//   - @func NotFoundHandler - Sets the handler invoked when no route matches the request.
+//
+//   - @func MethodNotAllowedHandler - Sets the handler invoked when the path exists under a different method.
+//
 //   - @func Serve - Starts the HTTP server on the specified port with the provided options.
+//
+//   - @func ServeTLS - Starts the HTTPS server with the provided certificate.
+//
+//   - @func ServeAutocert - Starts the HTTPS server with certificates obtained from Let's Encrypt.
+//
+//   - @func NewServer - Builds the HTTP server serving the router, for callers managing its lifecycle.
+//
+//   - @func Shutdown - Gracefully stops the server started by Serve.
 package routing
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	stdlog "log"
 	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	admissioncontrol "github.com/elithrar/admission-control"
 	"github.com/go-kit/log"
+	"golang.org/x/crypto/acme/autocert"
 )
 
 /*
-type Middleware: A function that takes an http.ResponseWriter and an http.Request and returns nothing.
+type Middleware: A function that takes an http.ResponseWriter and an http.Request and returns whether the request proceeds.
 
 This type is used to define middleware functions that can be applied to HTTP routes.
+A middleware returning false stops the request: neither the following middleware nor the handler run,
+so it must have written the response, ie: a 401 Unauthorized.
 */
-type Middleware func(http.ResponseWriter, *http.Request)
+type Middleware func(http.ResponseWriter, *http.Request) bool
 
 /*
 type Context: A struct that holds path and query parameters.
@@ -46,37 +79,61 @@ type Context: A struct that holds path and query parameters.
 This struct is used to manage the context of an HTTP request, including path parameters and query parameters.
 
   - @property PathParams: A map of path parameters, where the key is the parameter name and the value is the parameter value.
-  - @property QueryParams: A map of query parameters, where the key is the parameter name and the value is the parameter value.
+  - @property QueryParams: A map of query parameters, where the key is the parameter name and the value is its first value.
+  - @property QueryValues: The query parameters with all the values of repeated keys, ie: ?tag=a&tag=b.
   - @method @private setPathParams: Sets the path parameters for the context.
   - @method @private setQueryParams: Sets the query parameters for the context.
   - @method GetPathParam: Returns the value of a path parameter by its key.
   - @method GetQueryParam: Returns the value of a query parameter by its key.
+  - @method GetQueryParams: Returns all the values of a query parameter by its key.
   - @constructor @private newContext: Creates a new Context instance with empty path and query parameters.
 */
 type Context struct {
 	PathParams  map[string]string
 	QueryParams map[string]string
+	QueryValues url.Values
 }
 
 /*
 type ServeOptions: A struct that holds options for serving the router.
 This struct is used to configure the HTTP server when it is started.
   - @property Message: A message to be displayed when the server starts.
+  - @property Logging: Logs every request.
+  - @property ShutdownOnSignal: Gracefully shuts the server down when the process receives SIGINT or SIGTERM.
+  - @property ShutdownTimeout: How long the shutdown waits for in-flight requests, 10 seconds when unset.
+  - @property DisableAutoOptions: Stops answering OPTIONS requests for paths without an OPTIONS route
+    with a 204 listing the registered methods in the Allow and Access-Control-Allow-Methods headers.
+    The reply runs the route middleware of the route being preflighted, so a route-specific CORS policy applies,
+    and the Access-Control-Allow-Methods it sets is kept.
+  - @property DisableAutoHead: Stops serving HEAD requests for paths without a HEAD route with their GET handler,
+    whose headers and status are kept and body discarded.
 */
 type ServeOptions struct {
-	Message string
-	Logging bool
+	Message            string
+	Logging            bool
+	ShutdownOnSignal   bool
+	ShutdownTimeout    time.Duration
+	DisableAutoOptions bool
+	DisableAutoHead    bool
 }
 
+// defaultShutdownTimeout is the ServeOptions.ShutdownTimeout used when unset.
+const defaultShutdownTimeout = 10 * time.Second
+
 /*
 type Router: A struct that holds middleware and a Mux instance.
 This struct is used to manage the routing of HTTP requests and apply middleware to routes.
   - @property middleware: A slice of Middleware functions to be applied to the router.
+  - @property wrappers: A slice of handler wrappers applied around the mux, see Wrap.
   - @property mux: A Mux instance that handles the actual routing of HTTP requests.
+  - @property server: The server started by Serve, stopped by Shutdown.
 */
 type Router struct {
 	middleware []Middleware
+	wrappers   []func(http.Handler) http.Handler
 	mux        *Mux
+	serverMu   sync.Mutex
+	server     *http.Server
 }
 
 /*
@@ -86,12 +143,15 @@ This struct is used to define a route in the router.
   - @property Path: The path for the route (e.g., /api/v1/resource).
   - @property Handler: The handler function for the route, which takes an http.ResponseWriter, an http.Request, and a Context.
   - @property Middleware: A slice of middleware functions to be applied to the route.
+  - @method AllowedQueryParams: Restricts the query parameters accepted by the route.
+  - @method Wrap: Wraps the route's handler with a standard http.Handler middleware.
 */
 type Route struct {
 	Method     string
 	Path       string
 	Handler    HTTPHandlerWithContext
 	Middleware []Middleware
+	mux        *Mux
 }
 
 /*
@@ -103,6 +163,7 @@ func newContext() Context {
 	return Context{
 		PathParams:  make(map[string]string),
 		QueryParams: make(map[string]string),
+		QueryValues: make(url.Values),
 	}
 }
 
@@ -117,11 +178,15 @@ func (c *Context) setPathParams(paramMap map[string]string) {
 
 /*
 func (c *Context) setQueryParams: Sets the query parameters for the context.
-This method updates the QueryParams map in the Context struct with the provided parameter map.
-  - @param paramMap: A map of query parameters, where the key is the parameter name and the value is the parameter value.
+This method updates the QueryValues of the Context struct with the provided values, and QueryParams with the first value of each key.
+  - @param values: The decoded query parameters.
 */
-func (c *Context) setQueryParams(paramMap map[string]string) {
-	c.QueryParams = paramMap
+func (c *Context) setQueryParams(values url.Values) {
+	c.QueryValues = values
+	c.QueryParams = make(map[string]string, len(values))
+	for key, value := range values {
+		c.QueryParams[key] = value[0]
+	}
 }
 
 /*
@@ -158,6 +223,49 @@ func (c Context) GetQueryParam(key string) string {
 	return c.QueryParams[key]
 }
 
+/*
+func (c Context) GetQueryParams: Returns all the values of a query parameter by its key.
+This method retrieves the values of a repeated query parameter from the QueryValues of the Context struct.
+  - @param key: The key of the query parameter to retrieve.
+  - @return: The values of the specified query parameter, in request order, or nil when absent.
+
+Example usage:
+
+	func myHandler(w http.ResponseWriter, r *http.Request, ctx Context) {
+		tags := ctx.GetQueryParams("tag") // ?tag=a&tag=b
+		// Use the tags for filtering
+	}
+*/
+func (c Context) GetQueryParams(key string) []string {
+	return c.QueryValues[key]
+}
+
+/*
+func BuildPath: Builds a request path from a route pattern by substituting its path parameters.
+Each :name segment of the pattern is replaced by the escaped value of the matching parameter.
+Segments without a matching parameter are left untouched.
+  - @param pattern: The route pattern (e.g., /api/world/:id).
+  - @param params: A map of path parameters, where the key is the parameter name and the value is the parameter value.
+  - @return: The built path.
+
+Example usage:
+
+	location := BuildPath("/api/world/:id", map[string]string{"id": world.ID})
+	w.Header().Set("Location", location)
+*/
+func BuildPath(pattern string, params map[string]string) string {
+	parts := strings.Split(pattern, "/")
+	for i, part := range parts {
+		if !strings.HasPrefix(part, ":") {
+			continue
+		}
+		if value, ok := params[part[1:]]; ok {
+			parts[i] = url.PathEscape(value)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
 /*
 func NewRouter: Creates a new Router instance with an empty middleware chain and a new Mux instance.
 This function initializes a Router struct with an empty slice of middleware and a new Mux instance.
@@ -192,14 +300,40 @@ func NewRouter() *Router {
 //	router.Handle("GET", "/api/v1/resource", myHandler)
 func (r *Router) Use(m Middleware) {
 	r.middleware = append(r.middleware, m)
-	r.mux.RouterMiddleware = r.middleware
+	r.mux.use(r.middleware)
+}
+
+/*
+func (r *Router) Wrap: Wraps the whole router with a standard http.Handler middleware.
+Unlike Middleware, a wrapper controls when the rest of the chain runs, and can act once it completes.
+Wrappers are applied in registration order, the first one being the outermost.
+  - @param wrapper: A function returning an http.Handler wrapping the provided one.
+
+Example usage:
+
+	router := NewRouter()
+	router.Wrap(middleware.MaxConcurrent(100))
+*/
+func (r *Router) Wrap(wrapper func(http.Handler) http.Handler) {
+	r.wrappers = append(r.wrappers, wrapper)
+}
+
+// handler returns the mux wrapped by the registered wrappers.
+func (r *Router) handler() http.Handler {
+	var handler http.Handler = r.mux
+	for i := len(r.wrappers) - 1; i >= 0; i-- {
+		handler = r.wrappers[i](handler)
+	}
+	return handler
 }
 
 /*
 func (r *Router) Handle: Registers a route with the specified method, path, handler, and middleware.
 This method adds a new route to the Router's internal mux and returns a Route instance.
+Route middleware runs after the router middleware, so it can override what they set, ie: a route-specific CORS policy.
   - @param method: The HTTP method for the route (e.g., GET, POST).
-  - @param path: The path for the route (e.g., /api/v1/resource).
+  - @param path: The path for the route (e.g., /api/v1/resource/:id). A trailing *name segment captures the rest of the path,
+    ie: /api/files/*path matches /api/files/a/b with path "a/b". Other routes take precedence over wildcard routes.
   - @param handler: The handler function for the route, which takes an http.ResponseWriter, an http.Request, and a Context.
   - @param middleware: A variadic list of middleware functions to be applied to the route.
   - @return: A Route instance representing the registered route.
@@ -208,6 +342,7 @@ Example usage:
 
 	router := NewRouter()
 	router.Handle("GET", "/api/v1/resource", myHandler, myMiddleware1, myMiddleware2)
+	router.Handle("GET", "/api/files/*path", filesHandler)
 */
 func (r *Router) Handle(method string, path string, handler HTTPHandlerWithContext, middleware ...Middleware) *Route {
 	route := Route{
@@ -215,15 +350,200 @@ func (r *Router) Handle(method string, path string, handler HTTPHandlerWithConte
 		Path:       path,
 		Handler:    handler,
 		Middleware: middleware,
+		mux:        r.mux,
 	}
 	r.mux.handle(method, path, handler, middleware...)
 
 	return &route
 }
 
+/*
+func (r *Router) Static: Serves the files of a directory under a URL prefix, for GET and HEAD requests.
+Content types are derived from the file extensions, and directories are never listed.
+Page requests (accepting text/html) for paths matching no file are answered with the index.html of the directory,
+so a single-page application can route them itself; other requests, ie: API calls, get the not found handler.
+Other routes take precedence over the files route, and it is left out of 405 and automatic OPTIONS replies,
+so serving under / keeps unknown API paths answering 404.
+  - @param urlPrefix: The path the directory is served under (e.g., / or /app).
+  - @param dir: The directory to serve.
+  - @param middleware: A variadic list of middleware functions to be applied to the route, after the router middleware.
+  - @return: The Route instance of the files route.
+
+Example usage:
+
+	router := NewRouter()
+	router.Handle("GET", "/api/world/:id", getWorld)
+	router.Static("/", "./web/dist")
+*/
+func (r *Router) Static(urlPrefix string, dir string, middleware ...Middleware) *Route {
+	prefix := strings.TrimSuffix(urlPrefix, "/")
+	handler := staticHandler(dir, r.mux)
+
+	if prefix != "" {
+		r.Handle(http.MethodGet, prefix, handler, middleware...)
+		r.mux.markStatic(http.MethodGet, prefix)
+	}
+	route := r.Handle(http.MethodGet, prefix+"/*filepath", handler, middleware...)
+	r.mux.markStatic(http.MethodGet, route.Path)
+	return route
+}
+
+// staticHandler serves the file of dir matching the filepath path parameter. Directories and missing files
+// are answered with dir/index.html for page requests, and with the not found handler of the mux otherwise.
+func staticHandler(dir string, mux *Mux) HTTPHandlerWithContext {
+	fileServer := http.FileServer(fileOnlyFileSystem{http.Dir(dir)})
+	index := filepath.Join(dir, "index.html")
+
+	return func(w http.ResponseWriter, r *http.Request, c Context) {
+		name := path.Clean("/" + c.GetPathParam("filepath"))
+		if info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name))); err != nil || info.IsDir() {
+			if !strings.Contains(r.Header.Get("Accept"), "text/html") {
+				mux.mu.RLock()
+				notFound := mux.notFoundHandler()
+				mux.mu.RUnlock()
+				notFound(w, r, c)
+				return
+			}
+			w.Header().Del("Content-Type")
+			http.ServeFile(w, r, index)
+			return
+		}
+
+		// Router middleware may have set a JSON content type, the file server derives it from the file instead.
+		w.Header().Del("Content-Type")
+
+		req := r.Clone(r.Context())
+		req.URL.Path = name
+		req.URL.RawPath = ""
+		fileServer.ServeHTTP(w, req)
+	}
+}
+
+// fileOnlyFileSystem refuses to open directories, so the file server never lists them.
+type fileOnlyFileSystem struct {
+	fs http.FileSystem
+}
+
+func (fs fileOnlyFileSystem) Open(name string) (http.File, error) {
+	file, err := fs.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, os.ErrNotExist
+	}
+	return file, nil
+}
+
+/*
+func (rt *Route) AllowedQueryParams: Restricts the query parameters accepted by the route.
+When configured, a request carrying a query parameter that is not in the allowlist is rejected with a 400 Bad Request.
+Routes without an allowlist accept any query parameter.
+  - @param params: The names of the query parameters accepted by the route.
+  - @return: The Route instance, to allow chaining.
+
+Example usage:
+
+	router := NewRouter()
+	router.Handle("GET", "/api/v1/resource", myHandler).AllowedQueryParams([]string{"sort", "page"})
+*/
+func (rt *Route) AllowedQueryParams(params []string) *Route {
+	rt.mux.allowQueryParams(rt.Method, rt.Path, params)
+	return rt
+}
+
+/*
+func (rt *Route) Wrap: Wraps the route's handler with a standard http.Handler middleware.
+Unlike Middleware, a wrapper can hand the handler a new request, ie: one whose context carries the verified claims.
+Route wrappers run after the router and route middleware, in registration order, the first one being the outermost.
+Wrap the route before serving: a route registered while the server runs is reachable before it is wrapped.
+  - @param wrapper: A function returning an http.Handler wrapping the provided one.
+  - @return: The Route instance, to allow chaining.
+
+Example usage:
+
+	router := NewRouter()
+	router.Handle("GET", "/api/auth/session", getSession).Wrap(middleware.RequireAuth)
+*/
+func (rt *Route) Wrap(wrapper func(http.Handler) http.Handler) *Route {
+	rt.mux.wrapRoute(rt.Method, rt.Path, wrapper)
+	return rt
+}
+
+/*
+func (r *Router) NotFoundHandler: Sets the handler invoked when no route matches the request.
+When unset, the router responds with the standard library's http.NotFound.
+  - @param handler: The handler function invoked for unknown paths.
+
+Example usage:
+
+	router := NewRouter()
+	router.NotFoundHandler(func(w http.ResponseWriter, r *http.Request, c Context) {
+		rest.RespondError(w, http.StatusNotFound, "not found")
+	})
+*/
+func (r *Router) NotFoundHandler(handler HTTPHandlerWithContext) {
+	r.mux.setNotFound(handler)
+}
+
+/*
+func (r *Router) MethodNotAllowedHandler: Sets the handler invoked when the path matches a route registered under a different method.
+The Allow header listing the registered methods is set before the handler runs.
+When unset, such requests get a plain 405 Method Not Allowed.
+  - @param handler: The handler function invoked for requests using a wrong method.
+
+Example usage:
+
+	router := NewRouter()
+	router.MethodNotAllowedHandler(func(w http.ResponseWriter, r *http.Request, c Context) {
+		rest.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+	})
+*/
+func (r *Router) MethodNotAllowedHandler(handler HTTPHandlerWithContext) {
+	r.mux.setMethodNotAllowed(handler)
+}
+
+/*
+func (r *Router) NewServer: Builds the HTTP server serving the router on the specified port, without starting it.
+Callers managing the server lifecycle themselves start it with ListenAndServe and stop it with Shutdown.
+  - @param port: The port on which the server will listen for incoming requests.
+  - @param options: A ServeOptions instance containing options for serving the router.
+  - @return: The configured *http.Server.
+
+Example usage:
+
+	server := router.NewServer("8080", ServeOptions{})
+	go server.ListenAndServe()
+	defer server.Shutdown(context.Background())
+*/
+func (r *Router) NewServer(port string, options ServeOptions) *http.Server {
+	r.mux.configure(options)
+
+	handler := r.handler()
+	if options.Logging {
+		var logger log.Logger
+
+		logger = log.NewLogfmtLogger(log.NewSyncWriter(os.Stderr))
+		stdlog.SetOutput(log.NewStdlibAdapter(logger))
+
+		logger = log.With(logger, "ts", log.DefaultTimestampUTC, "loc", log.DefaultCaller)
+
+		handler = admissioncontrol.LoggingMiddleware(logger)(handler)
+	}
+
+	return &http.Server{Addr: ":" + port, Handler: handler}
+}
+
 /*
 func (r *Router) Serve: Starts the HTTP server on the specified port with the provided options.
 This method initializes the server with the specified port and options, and starts listening for incoming HTTP requests.
+It blocks until the server stops, and returns nil once it was stopped by Shutdown.
   - @param port: The port on which the server will listen for incoming requests.
   - @param options: A ServeOptions instance containing options for serving the router.
   - @return: An error if the server fails to start.
@@ -234,25 +554,116 @@ Example usage:
 	router.Serve("8080", ServeOptions{Message: "Server started on port 8080"})
 */
 func (r *Router) Serve(port string, options ServeOptions) error {
-	if options.Logging {
-		var logger log.Logger
+	return r.serve(r.NewServer(port, options), port, options, (*http.Server).ListenAndServe)
+}
 
-		logger = log.NewLogfmtLogger(log.NewSyncWriter(os.Stderr))
-		stdlog.SetOutput(log.NewStdlibAdapter(logger))
+/*
+func (r *Router) ServeTLS: Starts the HTTPS server on the specified port, like Serve.
+  - @param port: The port on which the server will listen for incoming requests.
+  - @param certFile: The path of the certificate, followed by the intermediate certificates if any.
+  - @param keyFile: The path of the private key matching the certificate.
+  - @param options: A ServeOptions instance containing options for serving the router.
+  - @return: An error if the server fails to start.
 
-		logger = log.With(logger, "ts", log.DefaultTimestampUTC, "loc", log.DefaultCaller)
+Example usage:
 
-		loggingMiddleware := admissioncontrol.LoggingMiddleware(logger)
+	router.ServeTLS("8443", "/etc/tls/cert.pem", "/etc/tls/key.pem", ServeOptions{ShutdownOnSignal: true})
+*/
+func (r *Router) ServeTLS(port string, certFile string, keyFile string, options ServeOptions) error {
+	return r.serve(r.NewServer(port, options), port, options, func(server *http.Server) error {
+		return server.ListenAndServeTLS(certFile, keyFile)
+	})
+}
+
+/*
+func (r *Router) ServeAutocert: Starts the HTTPS server on port 443 with certificates obtained from Let's Encrypt.
+Certificates are requested for the given domains on first use, and renewed automatically.
+Port 80 answers the ACME HTTP-01 challenges and redirects other requests to HTTPS, so both ports must be reachable.
+  - @param domains: The domains certificates may be requested for.
+  - @param cacheDir: The directory certificates are stored in, so they survive restarts.
+  - @param options: A ServeOptions instance containing options for serving the router.
+  - @return: An error if the server fails to start.
+
+Example usage:
 
-		fmt.Println("Server started on port", port)
-		fmt.Println("Message:", options.Message)
-		if err := http.ListenAndServe(":"+port, loggingMiddleware(r.mux)); err != nil {
-			logger.Log("status", "fatal", "err", err)
-			os.Exit(1)
+	router.ServeAutocert([]string{"api.example.com"}, "/var/cache/autocert", ServeOptions{ShutdownOnSignal: true})
+*/
+func (r *Router) ServeAutocert(domains []string, cacheDir string, options ServeOptions) error {
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(domains...),
+		Cache:      autocert.DirCache(cacheDir),
+	}
+
+	challenges := &http.Server{Addr: ":80", Handler: manager.HTTPHandler(nil)}
+	go func() {
+		if err := challenges.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			stdlog.Printf("autocert challenge server error: %v", err)
 		}
+	}()
+	defer challenges.Close()
+
+	server := r.NewServer("443", options)
+	server.TLSConfig = manager.TLSConfig()
+	return r.serve(server, "443", options, func(server *http.Server) error {
+		return server.ListenAndServeTLS("", "")
+	})
+}
+
+// serve records the server for Shutdown, then runs listen until the server stops.
+func (r *Router) serve(server *http.Server, port string, options ServeOptions, listen func(*http.Server) error) error {
+	r.serverMu.Lock()
+	r.server = server
+	r.serverMu.Unlock()
+
+	if options.ShutdownOnSignal {
+		r.shutdownOnSignal(options.ShutdownTimeout)
 	}
+
 	fmt.Println("Server started on port", port)
 	fmt.Println("Message:", options.Message)
-	http.ListenAndServe(":"+port, r.mux)
+	if err := listen(server); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		stdlog.Printf("server error: %v", err)
+		return err
+	}
 	return nil
 }
+
+/*
+func (r *Router) Shutdown: Gracefully stops the server started by Serve.
+The server stops accepting connections and waits for in-flight requests to complete, or for ctx to be done.
+  - @param ctx: The context bounding the time spent waiting for in-flight requests.
+  - @return: The error of http.Server.Shutdown, or nil when the server is not running.
+*/
+func (r *Router) Shutdown(ctx context.Context) error {
+	r.serverMu.Lock()
+	server := r.server
+	r.serverMu.Unlock()
+
+	if server == nil {
+		return nil
+	}
+	return server.Shutdown(ctx)
+}
+
+// shutdownOnSignal calls Shutdown once the process receives SIGINT or SIGTERM.
+func (r *Router) shutdownOnSignal(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		sig := <-signals
+		signal.Stop(signals)
+		stdlog.Printf("received %s, shutting down the server", sig)
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if err := r.Shutdown(ctx); err != nil {
+			stdlog.Printf("shutdown: %v", err)
+		}
+	}()
+}
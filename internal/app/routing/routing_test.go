@@ -0,0 +1,285 @@
+package routing
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// serve sends a request through the router as a server built by NewServer would.
+func serve(router *Router, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.NewServer("0", ServeOptions{}).Handler.ServeHTTP(w, r)
+	return w
+}
+
+// ok is a handler answering 200 with the body "ok".
+func ok(w http.ResponseWriter, r *http.Request, rctx Context) {
+	w.Write([]byte("ok"))
+}
+
+func TestAllowedQueryParams(t *testing.T) {
+	router := NewRouter()
+	router.Handle("GET", "/api/strict", ok).AllowedQueryParams([]string{"sort", "page"})
+	router.Handle("GET", "/api/loose", ok)
+
+	tests := []struct {
+		name   string
+		target string
+		want   int
+	}{
+		{name: "no params", target: "/api/strict", want: http.StatusOK},
+		{name: "allowed params", target: "/api/strict?sort=name&page=2", want: http.StatusOK},
+		{name: "repeated allowed param", target: "/api/strict?sort=name&sort=type", want: http.StatusOK},
+		{name: "encoded allowed param", target: "/api/strict?so%72t=name", want: http.StatusOK},
+		{name: "unexpected param", target: "/api/strict?foo=bar", want: http.StatusBadRequest},
+		{name: "unexpected among allowed", target: "/api/strict?sort=name&foo=bar", want: http.StatusBadRequest},
+		{name: "route without allowlist", target: "/api/loose?foo=bar", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, httptest.NewRequest("GET", tt.target, nil))
+			if w.Code != tt.want {
+				t.Errorf("GET %s status = %d, want %d: %s", tt.target, w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestFallbackHandlers(t *testing.T) {
+	custom := func(status int, body string) HTTPHandlerWithContext {
+		return func(w http.ResponseWriter, r *http.Request, rctx Context) {
+			w.WriteHeader(status)
+			w.Write([]byte(body))
+		}
+	}
+
+	tests := []struct {
+		name       string
+		custom     bool
+		method     string
+		target     string
+		wantStatus int
+		wantBody   string
+		wantAllow  string
+	}{
+		{name: "default not found", method: "GET", target: "/api/missing", wantStatus: http.StatusNotFound, wantBody: "404 page not found\n"},
+		{name: "default method not allowed", method: "DELETE", target: "/api/world", wantStatus: http.StatusMethodNotAllowed, wantAllow: "GET, HEAD"},
+		{name: "custom not found", custom: true, method: "GET", target: "/api/missing", wantStatus: http.StatusNotFound, wantBody: `{"error":"not found"}`},
+		{name: "custom method not allowed", custom: true, method: "DELETE", target: "/api/world", wantStatus: http.StatusMethodNotAllowed, wantBody: `{"error":"method not allowed"}`, wantAllow: "GET, HEAD"},
+		{name: "matched route", custom: true, method: "GET", target: "/api/world", wantStatus: http.StatusOK, wantBody: "ok"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := NewRouter()
+			router.Handle("GET", "/api/world", ok)
+			if tt.custom {
+				router.NotFoundHandler(custom(http.StatusNotFound, `{"error":"not found"}`))
+				router.MethodNotAllowedHandler(custom(http.StatusMethodNotAllowed, `{"error":"method not allowed"}`))
+			}
+
+			w := serve(router, httptest.NewRequest(tt.method, tt.target, nil))
+			if w.Code != tt.wantStatus {
+				t.Errorf("%s %s status = %d, want %d", tt.method, tt.target, w.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("%s %s body = %q, want %q", tt.method, tt.target, w.Body, tt.wantBody)
+			}
+			if allow := w.Header().Get("Allow"); allow != tt.wantAllow {
+				t.Errorf("%s %s Allow = %q, want %q", tt.method, tt.target, allow, tt.wantAllow)
+			}
+		})
+	}
+}
+
+func TestHandleWhileServing(t *testing.T) {
+	router := NewRouter()
+	router.Handle("GET", "/api/world", ok)
+	server := httptest.NewServer(router.NewServer("0", ServeOptions{}).Handler)
+	defer server.Close()
+
+	const routes = 20
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < routes; i++ {
+			path := fmt.Sprintf("/api/dynamic/%d", i)
+			router.Handle("GET", path, ok).AllowedQueryParams([]string{"page"})
+		}
+	}()
+
+	// Requests run while the routes are registered, exercising the mux maps concurrently.
+	for i := 0; i < routes; i++ {
+		for _, target := range []string{"/api/world", fmt.Sprintf("/api/dynamic/%d?page=1", i)} {
+			res, err := http.Get(server.URL + target)
+			if err != nil {
+				t.Fatalf("GET %s: %v", target, err)
+			}
+			res.Body.Close()
+		}
+	}
+	<-done
+
+	tests := []struct {
+		target string
+		want   int
+	}{
+		{target: "/api/world", want: http.StatusOK},
+		{target: "/api/dynamic/0", want: http.StatusOK},
+		{target: fmt.Sprintf("/api/dynamic/%d?page=2", routes-1), want: http.StatusOK},
+		{target: "/api/dynamic/0?foo=bar", want: http.StatusBadRequest},
+		{target: fmt.Sprintf("/api/dynamic/%d", routes), want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			res, err := http.Get(server.URL + tt.target)
+			if err != nil {
+				t.Fatalf("GET %s: %v", tt.target, err)
+			}
+			res.Body.Close()
+			if res.StatusCode != tt.want {
+				t.Errorf("GET %s status = %d, want %d", tt.target, res.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestAutoHead(t *testing.T) {
+	getWorld := func(w http.ResponseWriter, r *http.Request, rctx Context) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Total-Count", "3")
+		w.Write([]byte(`{"name":"Atlantis"}`))
+	}
+	headWorlds := func(w http.ResponseWriter, r *http.Request, rctx Context) {
+		w.Header().Set("X-Total-Count", "7")
+	}
+
+	tests := []struct {
+		name       string
+		options    ServeOptions
+		target     string
+		wantStatus int
+		wantCount  string
+	}{
+		{name: "get route", target: "/api/world/1", wantStatus: http.StatusOK, wantCount: "3"},
+		{name: "explicit head route", target: "/api/worlds", wantStatus: http.StatusOK, wantCount: "7"},
+		{name: "disabled", options: ServeOptions{DisableAutoHead: true}, target: "/api/world/1", wantStatus: http.StatusMethodNotAllowed},
+		{name: "unknown path", target: "/api/missing", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := NewRouter()
+			router.Handle("GET", "/api/world/:id", getWorld)
+			router.Handle("GET", "/api/worlds", getWorld)
+			router.Handle("HEAD", "/api/worlds", headWorlds)
+
+			w := httptest.NewRecorder()
+			router.NewServer("0", tt.options).Handler.ServeHTTP(w, httptest.NewRequest("HEAD", tt.target, nil))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("HEAD %s status = %d, want %d", tt.target, w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if w.Body.Len() != 0 {
+				t.Errorf("HEAD %s body = %q, want none", tt.target, w.Body)
+			}
+			if count := w.Header().Get("X-Total-Count"); count != tt.wantCount {
+				t.Errorf("HEAD %s X-Total-Count = %q, want %q", tt.target, count, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestQueryParams(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  map[string]string
+	}{
+		{name: "no query", query: "", want: map[string]string{}},
+		{name: "value containing =", query: "token=a=b", want: map[string]string{"token": "a=b"}},
+		{name: "percent-encoded value", query: "q=hello%20world", want: map[string]string{"q": "hello world"}},
+		{name: "plus as space", query: "q=hello+world", want: map[string]string{"q": "hello world"}},
+		{name: "bare key", query: "flag&sort=name", want: map[string]string{"flag": "", "sort": "name"}},
+		{name: "empty pair", query: "a=1&&b=2", want: map[string]string{"a": "1", "b": "2"}},
+		{name: "repeated key keeps the first value", query: "tag=a&tag=b", want: map[string]string{"tag": "a"}},
+		{name: "malformed pair skipped", query: "bad=%zz&q=ok", want: map[string]string{"q": "ok"}},
+		{name: "everything together", query: "token=a=b&q=hello%20world&flag", want: map[string]string{"token": "a=b", "q": "hello world", "flag": ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]string
+			router := NewRouter()
+			router.Handle("GET", "/api/search", func(w http.ResponseWriter, r *http.Request, rctx Context) {
+				got = map[string]string{}
+				for key := range rctx.QueryParams {
+					got[key] = rctx.GetQueryParam(key)
+				}
+			})
+
+			w := serve(router, httptest.NewRequest("GET", "/api/search?"+tt.query, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("GET ?%s status = %d: %s", tt.query, w.Code, w.Body)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GET ?%s query params = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteMiddlewareKeying(t *testing.T) {
+	var ran []string
+	requireAuth := func(w http.ResponseWriter, r *http.Request) bool {
+		ran = append(ran, "auth")
+		if r.Header.Get("Authorization") == "" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return false
+		}
+		return true
+	}
+
+	router := NewRouter()
+	router.Handle("GET", "/api/world/:id", ok)
+	router.Handle("DELETE", "/api/world/:id", ok, requireAuth)
+	router.Handle("PUT", "/api/world/:id", ok)
+
+	tests := []struct {
+		name       string
+		method     string
+		token      string
+		wantStatus int
+		wantAuth   bool
+	}{
+		{name: "delete without a token", method: "DELETE", wantStatus: http.StatusUnauthorized, wantAuth: true},
+		{name: "delete with a token", method: "DELETE", token: "Bearer token", wantStatus: http.StatusOK, wantAuth: true},
+		{name: "get on the same path skips it", method: "GET", wantStatus: http.StatusOK},
+		{name: "head served by get skips it", method: "HEAD", wantStatus: http.StatusOK},
+		{name: "put on the same path skips it", method: "PUT", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ran = nil
+			r := httptest.NewRequest(tt.method, "/api/world/4:db:1", nil)
+			if tt.token != "" {
+				r.Header.Set("Authorization", tt.token)
+			}
+
+			w := serve(router, r)
+			if w.Code != tt.wantStatus {
+				t.Errorf("%s status = %d, want %d: %s", tt.method, w.Code, tt.wantStatus, w.Body)
+			}
+			if auth := len(ran) > 0; auth != tt.wantAuth {
+				t.Errorf("%s ran the DELETE middleware = %v, want %v", tt.method, auth, tt.wantAuth)
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package utils
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
 )
 
 func PrettyPrint(v interface{}) (err error) {
@@ -12,3 +14,20 @@ func PrettyPrint(v interface{}) (err error) {
 	}
 	return
 }
+
+/*
+Secret resolves a secret from the environment.
+When <name>_FILE is set, the secret is read from that file path, with trailing newlines trimmed,
+overriding <name>. Otherwise the value of <name> is returned.
+This allows secrets to be mounted as files, as Docker and Kubernetes secrets are.
+*/
+func Secret(name string) (string, error) {
+	if path := os.Getenv(name + "_FILE"); path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s_FILE: %w", name, err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	}
+	return os.Getenv(name), nil
+}
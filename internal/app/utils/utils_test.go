@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSecret(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		env     string
+		file    string
+		want    string
+		wantErr bool
+	}{
+		{name: "env", env: "from-env", want: "from-env"},
+		{name: "unset", want: ""},
+		{name: "file overrides env", env: "from-env", file: write("password", "from-file"), want: "from-file"},
+		{name: "trailing newline trimmed", file: write("newline", "from-file\n"), want: "from-file"},
+		{name: "trailing crlf trimmed", file: write("crlf", "from-file\r\n"), want: "from-file"},
+		{name: "inner spaces kept", file: write("spaces", " from file \n"), want: " from file "},
+		{name: "missing file", env: "from-env", file: filepath.Join(dir, "missing"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_PASSWORD", tt.env)
+			t.Setenv("TEST_PASSWORD_FILE", tt.file)
+
+			got, err := Secret("TEST_PASSWORD")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Secret() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Secret() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}